@@ -0,0 +1,44 @@
+// Command canonicalize clusters the duplicate songs of the samples DB into the
+// SongCanonical table. It creates the table when needed, so it must run as a
+// samples DB user with DDL rights. The server only reads the table and falls
+// back to the raw Sample table until this job has run once.
+package main
+
+import (
+	"log"
+
+	"github.com/Emeruem-Kennedy1/ghopper/config"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/database"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/logging"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger, err := logging.NewLogger()
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Sync()
+
+	zap.ReplaceGlobals(logger)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	samplesDB, err := database.InitSamplesDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize samples database: %v", err)
+	}
+	defer samplesDB.Close()
+
+	canonicalizer := repository.NewSongCanonicalizer(samplesDB)
+	if err := canonicalizer.EnsureTable(); err != nil {
+		log.Fatalf("Failed to create song canonical table: %v", err)
+	}
+	if _, err := canonicalizer.Run(); err != nil {
+		log.Fatalf("Failed to canonicalize duplicate songs: %v", err)
+	}
+}
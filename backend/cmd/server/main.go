@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/Emeruem-Kennedy1/ghopper/config"
@@ -8,6 +9,7 @@ import (
 	"github.com/Emeruem-Kennedy1/ghopper/internal/logging"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/server"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
 	"go.uber.org/zap"
 )
 
@@ -33,13 +35,54 @@ func main() {
 		log.Fatalf("Failed to initialize databases: %v", err)
 	}
 
+	// duplicate songs are merged once the canonicalize job has built the
+	// SongCanonical table; until then searches read the raw Sample table
+	canonical, err := repository.HasSongCanonicalTable(dbs.SamplesDB)
+	if err != nil {
+		logger.Error("Failed to check for the song canonical table", zap.Error(err))
+	}
+	if !canonical {
+		logger.Warn("No song canonical table, duplicate songs will not be merged")
+	}
+
 	userRepo := repository.NewUserRepository(dbs.AppDB)
-	songRepo := repository.NewSongRepository(dbs.SamplesDB)
+	var songRepo repository.SongRepositoryInterface = repository.NewSongRepository(dbs.SamplesDB, canonical)
+	var graphIndex *repository.SongGraphIndex
+	if cfg.SamplesGraphIndex {
+		graphIndex = repository.NewSongGraphIndex(dbs.SamplesDB, canonical)
+		if err := graphIndex.Load(); err != nil {
+			log.Fatalf("Failed to load sample graph index: %v", err)
+		}
+		go graphIndex.Run(context.Background(), cfg.SamplesGraphRefresh)
+		songRepo = graphIndex
+	}
+	// searchCache stays a nil interface when caching is disabled
+	var searchCache repository.SearchCacheInterface
+	if cfg.SearchCacheSize > 0 {
+		cachedSongRepo := repository.NewCachedSongRepository(songRepo, dbs.SamplesDB, canonical, cfg.SearchCacheSize, cfg.SearchCacheTTL)
+		// the index only sees DB changes once it reloads, so results cached
+		// in between come from its old snapshot
+		if graphIndex != nil {
+			graphIndex.OnReload(cachedSongRepo.Invalidate)
+		}
+		go cachedSongRepo.Run(context.Background(), cfg.SearchCacheCheck)
+		songRepo = cachedSongRepo
+		searchCache = cachedSongRepo
+	}
 	spotifySongRepo := repository.NewSpotifySongRepository(dbs.AppDB)
 	nonSpotifyUserRepo := repository.NewNonSpotifyUserRepository(dbs.AppDB)
+	genreTaxonomyRepo := repository.NewGenreTaxonomyRepository(dbs.AppDB)
+	exclusionRepo := repository.NewExclusionRepository(dbs.AppDB)
+	if err := genreTaxonomyRepo.SeedDefaults(); err != nil {
+		log.Fatalf("Failed to seed genre taxonomy: %v", err)
+	}
+	genreTaxonomy := services.NewGenreTaxonomyService(genreTaxonomyRepo)
+	if err := genreTaxonomy.Load(); err != nil {
+		log.Fatalf("Failed to load genre taxonomy: %v", err)
+	}
 
 	// init and start server
-	s, err := server.NewServer(cfg, userRepo, songRepo, spotifySongRepo, nonSpotifyUserRepo, logger)
+	s, err := server.NewServer(cfg, userRepo, songRepo, spotifySongRepo, nonSpotifyUserRepo, genreTaxonomy, exclusionRepo, searchCache, logger)
 
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
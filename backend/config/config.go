@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -25,6 +28,28 @@ type Config struct {
 	SamplesDBName       string
 	JWTSecret           string
 	FrontendURL         string
+	SamplesGraphIndex   bool
+	SamplesGraphRefresh time.Duration
+	AdminUserIDs        []string
+	// Playlist diversity stage, see models.DiversityOptions
+	PlaylistMaxPerArtist    int
+	PlaylistMaxPerSeed      int
+	PlaylistDiversityLambda float64
+	// Sample-graph search budget, see models.SearchBudget
+	SearchMaxDepth int
+	SearchMaxSeeds int
+	SearchMaxRows  int
+	SearchMaxCost  float64
+	SearchTimeout  time.Duration
+	// Genre search cache, disabled when SearchCacheSize is 0
+	SearchCacheSize  int
+	SearchCacheTTL   time.Duration
+	SearchCacheCheck time.Duration
+	// Sample radio random walk, see models.RadioOptions
+	RadioRestartProbability float64
+	RadioGenreBias          float64
+	RadioRecentWindow       int
+	RadioSessionTTL         time.Duration
 }
 
 func getEnv(key, fallack string) string {
@@ -34,6 +59,56 @@ func getEnv(key, fallack string) string {
 	return fallack
 }
 
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid boolean for %s, using default %t", key, fallback)
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid integer for %s, using default %d", key, fallback)
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid number for %s, using default %g", key, fallback)
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid duration for %s, using default %s", key, fallback)
+	}
+	return fallback
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func Load() (*Config, error) {
 	envFile := ".env.development"
 	if os.Getenv("GO_ENV") == "production" {
@@ -62,5 +137,27 @@ func Load() (*Config, error) {
 		SamplesDBHost:       getEnv("SAMPLES_DB_HOST", ""),
 		SamplesDBName:       getEnv("SAMPLES_DB_NAME", ""),
 		FrontendURL:         getEnv("FRONTEND_URL", ""),
+		SamplesGraphIndex:   getEnvBool("SAMPLES_GRAPH_INDEX", false),
+		SamplesGraphRefresh: getEnvDuration("SAMPLES_GRAPH_REFRESH", 15*time.Minute),
+		AdminUserIDs:        getEnvList("ADMIN_USER_IDS"),
+
+		PlaylistMaxPerArtist:    getEnvInt("PLAYLIST_MAX_PER_ARTIST", 2),
+		PlaylistMaxPerSeed:      getEnvInt("PLAYLIST_MAX_PER_SEED", 10),
+		PlaylistDiversityLambda: getEnvFloat("PLAYLIST_DIVERSITY_LAMBDA", 0.7),
+
+		SearchMaxDepth: getEnvInt("SEARCH_MAX_DEPTH", 6),
+		SearchMaxSeeds: getEnvInt("SEARCH_MAX_SEEDS", 50),
+		SearchMaxRows:  getEnvInt("SEARCH_MAX_ROWS", 5000),
+		SearchMaxCost:  getEnvFloat("SEARCH_MAX_COST", 500000),
+		SearchTimeout:  getEnvDuration("SEARCH_TIMEOUT", 15*time.Second),
+
+		SearchCacheSize:  getEnvInt("SEARCH_CACHE_SIZE", 500),
+		SearchCacheTTL:   getEnvDuration("SEARCH_CACHE_TTL", 30*time.Minute),
+		SearchCacheCheck: getEnvDuration("SEARCH_CACHE_CHECK", time.Minute),
+
+		RadioRestartProbability: getEnvFloat("RADIO_RESTART_PROBABILITY", 0.15),
+		RadioGenreBias:          getEnvFloat("RADIO_GENRE_BIAS", 4),
+		RadioRecentWindow:       getEnvInt("RADIO_RECENT_WINDOW", 50),
+		RadioSessionTTL:         getEnvDuration("RADIO_SESSION_TTL", time.Hour),
 	}, nil
 }
@@ -22,7 +22,7 @@ func InitDBConnections(cfg *config.Config) (*DBConnections, error) {
 		return nil, fmt.Errorf("failed to initialize app database: %v", err)
 	}
 
-	samplesDB, err := initSamplesDB(cfg)
+	samplesDB, err := InitSamplesDB(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize samples database: %v", err)
 	}
@@ -54,7 +54,12 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 		&models.NonSpotifyUser{},
 		&models.NonSpotifyPlaylist{},
 		&models.NonSpotifyPlaylistTrack{},
-		&models.NonSpotifyPlaylistSeedTrack{})
+		&models.NonSpotifyPlaylistSeedTrack{},
+		&models.GenreGroup{},
+		&models.GenreGroupGenre{},
+		&models.GenreGroupPlaylist{},
+		&models.GenreGroupCoverImage{},
+		&models.UserExclusion{})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
@@ -63,7 +68,7 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	return db, nil
 }
 
-func initSamplesDB(cfg *config.Config) (*sql.DB, error) {
+func InitSamplesDB(cfg *config.Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
 		"%s:%s@tcp(%s:%s)/%s?tls=false&charset=utf8mb4&parseTime=True&loc=Local",
 		cfg.SamplesDBUser,
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultArtistNetworkDepth = 1
+	maxArtistNetworkDepth     = 3
+	// maxArtistNetworkNodes caps the artists in an artist network, the artist
+	// included
+	maxArtistNetworkNodes = 200
+)
+
+// ArtistNetworkResponse mirrors GraphResponse with artists as nodes and
+// weighted "who samples whom" edges
+type ArtistNetworkResponse struct {
+	ArtistID string `json:"artistId"`
+	// Map of artist ID to the artists it shares samples with
+	AdjacencyList map[string]map[string]interface{} `json:"adjacencyList"`
+	// Map of artist ID to artist details
+	Nodes map[string]ArtistInfo `json:"nodes"`
+	Edges []ArtistEdgeInfo      `json:"edges"`
+	// Truncated says the node cap left reachable artists out
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type ArtistEdgeInfo struct {
+	From     string           `json:"from"` // Sampling artist ID
+	To       string           `json:"to"`   // Sampled artist ID
+	Count    int              `json:"count"`
+	Examples []SamplePairInfo `json:"examples"`
+}
+
+type SamplePairInfo struct {
+	SampledInID    string `json:"sampledInId"`
+	SampledInTitle string `json:"sampledInTitle"`
+	OriginalID     string `json:"originalId"`
+	OriginalTitle  string `json:"originalTitle"`
+}
+
+// GetArtistNetwork returns the artists an artist samples or is sampled by:
+// GET /artists/:id/network?depth=&mainOnly=&maxNodes=
+func GetArtistNetwork(songRepo repository.SongRepositoryInterface, budget models.SearchBudget) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		artistID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || artistID <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid artist id"})
+			return
+		}
+
+		opts := models.ArtistNetworkOptions{Depth: defaultArtistNetworkDepth, MaxNodes: maxArtistNetworkNodes}
+		if depthParam := ctx.Query("depth"); depthParam != "" {
+			depth, err := strconv.Atoi(depthParam)
+			if err != nil || depth <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "depth must be a positive integer"})
+				return
+			}
+			opts.Depth = min(depth, maxArtistNetworkDepth)
+		}
+		if mainOnlyParam := ctx.Query("mainOnly"); mainOnlyParam != "" {
+			mainOnly, err := strconv.ParseBool(mainOnlyParam)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "mainOnly must be true or false"})
+				return
+			}
+			opts.MainArtistsOnly = mainOnly
+		}
+		if maxNodesParam := ctx.Query("maxNodes"); maxNodesParam != "" {
+			maxNodes, err := strconv.Atoi(maxNodesParam)
+			if err != nil || maxNodes <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxNodes must be a positive integer"})
+				return
+			}
+			opts.MaxNodes = min(maxNodes, maxArtistNetworkNodes)
+		}
+
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		network, err := songRepo.GetArtistNetwork(searchCtx, artistID, opts)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to get artist network",
+				zap.Int("artistID", artistID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get artist network"})
+			return
+		}
+		if network == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "artist not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newArtistNetworkResponse(network))
+	}
+}
+
+func newArtistNetworkResponse(network *models.ArtistNetwork) ArtistNetworkResponse {
+	response := ArtistNetworkResponse{
+		ArtistID:      songKey(network.ArtistID),
+		AdjacencyList: make(map[string]map[string]interface{}),
+		Nodes:         make(map[string]ArtistInfo, len(network.Artists)),
+		Edges:         make([]ArtistEdgeInfo, 0, len(network.Edges)),
+		Truncated:     network.Truncated,
+	}
+
+	for id, artist := range network.Artists {
+		response.Nodes[songKey(id)] = ArtistInfo{ID: artist.ID, Name: artist.Name}
+	}
+
+	for _, edge := range network.Edges {
+		from, to := songKey(edge.FromArtistID), songKey(edge.ToArtistID)
+		if response.AdjacencyList[from] == nil {
+			response.AdjacencyList[from] = make(map[string]interface{})
+		}
+		if response.AdjacencyList[to] == nil {
+			response.AdjacencyList[to] = make(map[string]interface{})
+		}
+		response.AdjacencyList[from][to] = struct{}{}
+		response.AdjacencyList[to][from] = struct{}{}
+
+		examples := make([]SamplePairInfo, len(edge.Examples))
+		for i, pair := range edge.Examples {
+			examples[i] = SamplePairInfo{
+				SampledInID:    songKey(pair.SampledInSongID),
+				SampledInTitle: pair.SampledInTitle,
+				OriginalID:     songKey(pair.OriginalSongID),
+				OriginalTitle:  pair.OriginalTitle,
+			}
+		}
+
+		response.Edges = append(response.Edges, ArtistEdgeInfo{
+			From:     from,
+			To:       to,
+			Count:    edge.Count,
+			Examples: examples,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupArtistHandlerTest(songRepo repository.SongRepositoryInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	// Add a mock context middleware to simulate authenticated user
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "test-user-id")
+		c.Next()
+	})
+
+	r.GET("/artists/:id/network", GetArtistNetwork(songRepo, testSearchBudget))
+	return r
+}
+
+func TestGetArtistNetwork(t *testing.T) {
+	t.Run("Found_Network", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupArtistHandlerTest(mockRepo)
+
+		mockRepo.On("GetArtistNetwork", 1, models.ArtistNetworkOptions{Depth: 2, MainArtistsOnly: true, MaxNodes: maxArtistNetworkNodes}).
+			Return(&models.ArtistNetwork{
+				ArtistID: 1,
+				Artists: map[int]models.Artist{
+					1: {ID: 1, Name: "Producer"},
+					2: {ID: 2, Name: "Band"},
+				},
+				Edges: []models.ArtistEdge{
+					{
+						FromArtistID: 1,
+						ToArtistID:   2,
+						Count:        4,
+						Examples: []models.SamplePair{
+							{SampledInSongID: 10, SampledInTitle: "Beat", OriginalSongID: 20, OriginalTitle: "Break"},
+						},
+					},
+				},
+			}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/artists/1/network?depth=2&mainOnly=true", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response ArtistNetworkResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, "1", response.ArtistID)
+		assert.Equal(t, "Band", response.Nodes["2"].Name)
+		assert.Contains(t, response.AdjacencyList["2"], "1", "Adjacency list should be bidirectional")
+		require.Len(t, response.Edges, 1)
+		assert.Equal(t, ArtistEdgeInfo{
+			From:  "1",
+			To:    "2",
+			Count: 4,
+			Examples: []SamplePairInfo{
+				{SampledInID: "10", SampledInTitle: "Beat", OriginalID: "20", OriginalTitle: "Break"},
+			},
+		}, response.Edges[0])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Caps_Depth", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupArtistHandlerTest(mockRepo)
+
+		mockRepo.On("GetArtistNetwork", 1, models.ArtistNetworkOptions{Depth: maxArtistNetworkDepth, MaxNodes: maxArtistNetworkNodes}).
+			Return(&models.ArtistNetwork{ArtistID: 1, Artists: map[int]models.Artist{1: {ID: 1}}, Truncated: true}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/artists/1/network?depth=50&maxNodes=5000", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response ArtistNetworkResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.True(t, response.Truncated, "Should say the node cap left artists out")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Artist_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupArtistHandlerTest(mockRepo)
+
+		mockRepo.On("GetArtistNetwork", 999, mock.Anything).Return(nil, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/artists/999/network", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Parameters", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupArtistHandlerTest(mockRepo)
+
+		for _, url := range []string{"/artists/abc/network", "/artists/1/network?depth=0", "/artists/1/network?mainOnly=maybe", "/artists/1/network?maxNodes=0"} {
+			// Act
+			req := httptest.NewRequest("GET", url, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code, "Should reject %s", url)
+		}
+		mockRepo.AssertNotCalled(t, "GetArtistNetwork", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.GET("/artists/:id/network", GetArtistNetwork(mockRepo, models.SearchBudget{Timeout: time.Millisecond}))
+
+		mockRepo.On("GetArtistNetwork", 1, mock.Anything).
+			Run(func(mock.Arguments) { time.Sleep(10 * time.Millisecond) }).
+			Return(nil, context.DeadlineExceeded)
+
+		// Act
+		req := httptest.NewRequest("GET", "/artists/1/network", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Code, "Should time out with the search budget")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository_Error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupArtistHandlerTest(mockRepo)
+
+		mockRepo.On("GetArtistNetwork", 1, mock.Anything).Return(nil, assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/artists/1/network", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRepo.AssertExpectations(t)
+	})
+}
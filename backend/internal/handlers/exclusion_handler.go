@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExclusionRequest saves an artist or song the user never wants in playlists
+type ExclusionRequest struct {
+	// Kind is "artist" or "song"
+	Kind   string `json:"kind" binding:"required"`
+	Artist string `json:"artist" binding:"required"`
+	// Title is required for song exclusions
+	Title string `json:"title"`
+}
+
+// ExclusionInfo is a saved exclusion as returned to clients
+type ExclusionInfo struct {
+	ID     uint                 `json:"id"`
+	Kind   models.ExclusionKind `json:"kind"`
+	Artist string               `json:"artist"`
+	Title  string               `json:"title,omitempty"`
+}
+
+func newExclusionInfo(exclusion models.UserExclusion) ExclusionInfo {
+	return ExclusionInfo{
+		ID:     exclusion.ID,
+		Kind:   exclusion.Kind,
+		Artist: exclusion.Artist,
+		Title:  exclusion.Title,
+	}
+}
+
+// ListExclusions lists the user's saved exclusions
+func ListExclusions(exclusionRepo repository.ExclusionRepositoryInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetString("userID")
+		if userID == "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		exclusions, err := exclusionRepo.ListExclusions(userID)
+		if err != nil {
+			zap.L().Error("Failed to list exclusions",
+				zap.String("userID", userID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list exclusions"})
+			return
+		}
+
+		response := make([]ExclusionInfo, 0, len(exclusions))
+		for _, exclusion := range exclusions {
+			response = append(response, newExclusionInfo(exclusion))
+		}
+		ctx.JSON(http.StatusOK, gin.H{"exclusions": response})
+	}
+}
+
+// AddExclusion saves an artist or song exclusion for the user
+func AddExclusion(exclusionRepo repository.ExclusionRepositoryInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetString("userID")
+		if userID == "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		var req ExclusionRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("Invalid request format",
+				zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		kind, err := models.ParseExclusionKind(req.Kind)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if kind == models.ExclusionSong && strings.TrimSpace(req.Title) == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "title is required to exclude a song"})
+			return
+		}
+
+		exclusion := models.UserExclusion{
+			UserID: userID,
+			Kind:   kind,
+			Artist: req.Artist,
+			Title:  req.Title,
+		}
+		if err := exclusionRepo.AddExclusion(&exclusion); err != nil {
+			zap.L().Error("Failed to add exclusion",
+				zap.String("userID", userID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add exclusion"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newExclusionInfo(exclusion))
+	}
+}
+
+// DeleteExclusion removes one of the user's exclusions
+func DeleteExclusion(exclusionRepo repository.ExclusionRepositoryInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetString("userID")
+		if userID == "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid exclusion ID"})
+			return
+		}
+
+		deleted, err := exclusionRepo.DeleteExclusion(userID, uint(id))
+		if err != nil {
+			zap.L().Error("Failed to delete exclusion",
+				zap.String("userID", userID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete exclusion"})
+			return
+		}
+		if !deleted {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "exclusion not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "Exclusion deleted successfully"})
+	}
+}
+
+// loadSearchExclusions merges a user's saved exclusions with the artists and
+// songs a request excludes
+func loadSearchExclusions(exclusionRepo repository.ExclusionRepositoryInterface, userID string, artists []string, songs []models.SongQuery) (models.SearchExclusions, error) {
+	exclusions := models.SearchExclusions{
+		Artists: append([]string(nil), artists...),
+		Songs:   append([]models.SongQuery(nil), songs...),
+	}
+
+	saved, err := exclusionRepo.ListExclusions(userID)
+	if err != nil {
+		return models.SearchExclusions{}, err
+	}
+	for _, exclusion := range saved {
+		exclusions.Add(exclusion)
+	}
+	return exclusions, nil
+}
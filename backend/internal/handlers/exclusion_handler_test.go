@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExclusionHandlerTest(exclusionRepo *MockExclusionRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	// Add a mock context middleware to simulate authenticated user
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "test-user-id")
+		c.Next()
+	})
+
+	r.GET("/exclusions", ListExclusions(exclusionRepo))
+	r.POST("/exclusions", AddExclusion(exclusionRepo))
+	r.DELETE("/exclusions/:id", DeleteExclusion(exclusionRepo))
+	return r
+}
+
+func TestListExclusions(t *testing.T) {
+	// Arrange
+	mockExclusionRepo := new(MockExclusionRepository)
+	r := setupExclusionHandlerTest(mockExclusionRepo)
+
+	mockExclusionRepo.On("ListExclusions", "test-user-id").Return([]models.UserExclusion{
+		{ID: 1, UserID: "test-user-id", Kind: models.ExclusionArtist, Artist: "Some Band"},
+		{ID: 2, UserID: "test-user-id", Kind: models.ExclusionSong, Artist: "Some Singer", Title: "Some Song"},
+	}, nil)
+
+	// Act
+	req := httptest.NewRequest("GET", "/exclusions", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+	var response struct {
+		Exclusions []ExclusionInfo `json:"exclusions"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+	assert.Equal(t, []ExclusionInfo{
+		{ID: 1, Kind: models.ExclusionArtist, Artist: "Some Band"},
+		{ID: 2, Kind: models.ExclusionSong, Artist: "Some Singer", Title: "Some Song"},
+	}, response.Exclusions)
+	mockExclusionRepo.AssertExpectations(t)
+}
+
+func TestAddExclusion(t *testing.T) {
+	t.Run("Adds_Song", func(t *testing.T) {
+		// Arrange
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupExclusionHandlerTest(mockExclusionRepo)
+
+		mockExclusionRepo.On("AddExclusion", &models.UserExclusion{
+			UserID: "test-user-id",
+			Kind:   models.ExclusionSong,
+			Artist: "Some Singer",
+			Title:  "Some Song",
+		}).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.UserExclusion).ID = 3
+		}).Return(nil)
+
+		body, _ := json.Marshal(ExclusionRequest{Kind: "Song", Artist: "Some Singer", Title: "Some Song"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/exclusions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response ExclusionInfo
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+		assert.Equal(t, ExclusionInfo{ID: 3, Kind: models.ExclusionSong, Artist: "Some Singer", Title: "Some Song"}, response)
+		mockExclusionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Kind", func(t *testing.T) {
+		// Arrange
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupExclusionHandlerTest(mockExclusionRepo)
+
+		body, _ := json.Marshal(ExclusionRequest{Kind: "album", Artist: "Some Band"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/exclusions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		mockExclusionRepo.AssertNotCalled(t, "AddExclusion", mock.Anything)
+	})
+
+	t.Run("Song_Without_Title", func(t *testing.T) {
+		// Arrange
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupExclusionHandlerTest(mockExclusionRepo)
+
+		body, _ := json.Marshal(ExclusionRequest{Kind: "song", Artist: "Some Singer"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/exclusions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		mockExclusionRepo.AssertNotCalled(t, "AddExclusion", mock.Anything)
+	})
+}
+
+func TestDeleteExclusion(t *testing.T) {
+	t.Run("Deletes_Exclusion", func(t *testing.T) {
+		// Arrange
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupExclusionHandlerTest(mockExclusionRepo)
+
+		mockExclusionRepo.On("DeleteExclusion", "test-user-id", uint(1)).Return(true, nil)
+
+		// Act
+		req := httptest.NewRequest("DELETE", "/exclusions/1", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		mockExclusionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not_Found", func(t *testing.T) {
+		// Arrange
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupExclusionHandlerTest(mockExclusionRepo)
+
+		mockExclusionRepo.On("DeleteExclusion", "test-user-id", uint(9)).Return(false, nil)
+
+		// Act
+		req := httptest.NewRequest("DELETE", "/exclusions/9", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+	})
+
+	t.Run("Invalid_ID", func(t *testing.T) {
+		// Arrange
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupExclusionHandlerTest(mockExclusionRepo)
+
+		// Act
+		req := httptest.NewRequest("DELETE", "/exclusions/abc", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		mockExclusionRepo.AssertNotCalled(t, "DeleteExclusion", mock.Anything, mock.Anything)
+	})
+}
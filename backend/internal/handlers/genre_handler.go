@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GenreGroupInfo is a genre group as offered to clients picking a genre
+type GenreGroupInfo struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Genres []string `json:"genres"`
+	// SongCount is how many samples DB songs are tagged with any of Genres
+	SongCount int `json:"songCount"`
+}
+
+// GenreGroupRequest creates or replaces a genre group
+type GenreGroupRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Position int      `json:"position"`
+	Genres   []string `json:"genres" binding:"required,min=1"`
+	// Synonyms resolve to the group but are not searched for
+	Synonyms    []string `json:"synonyms"`
+	Playlists   []string `json:"playlists"`
+	CoverImages []string `json:"coverImages"`
+}
+
+// GenreGroupDetails is a genre group with everything an admin can edit
+type GenreGroupDetails struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Position    int      `json:"position"`
+	Genres      []string `json:"genres"`
+	Synonyms    []string `json:"synonyms"`
+	Playlists   []string `json:"playlists"`
+	CoverImages []string `json:"coverImages"`
+}
+
+// ListGenres lists the genre groups clients can search for, with the songs of
+// every group counted in one samples DB query
+func ListGenres(genreTaxonomy services.GenreTaxonomyServiceInterface, songRepo repository.SongRepositoryInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		groups := genreTaxonomy.Groups()
+		genreGroups := make(map[string][]string, len(groups))
+		for _, group := range groups {
+			genreGroups[group.ID] = group.SearchGenres()
+		}
+
+		counts, err := songRepo.CountSongsByGenreGroup(ctx.Request.Context(), genreGroups)
+		if err != nil {
+			zap.L().Error("Failed to count songs for genre groups",
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list genres"})
+			return
+		}
+
+		response := make([]GenreGroupInfo, 0, len(groups))
+		for _, group := range groups {
+			response = append(response, GenreGroupInfo{
+				ID:        group.ID,
+				Name:      group.Name,
+				Genres:    genreGroups[group.ID],
+				SongCount: counts[group.ID],
+			})
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"genres": response})
+	}
+}
+
+// ListGenreGroups lists the full genre taxonomy for admins
+func ListGenreGroups(genreTaxonomy services.GenreTaxonomyServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		groups := genreTaxonomy.Groups()
+		response := make([]GenreGroupDetails, 0, len(groups))
+		for _, group := range groups {
+			response = append(response, newGenreGroupDetails(group))
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"genres": response})
+	}
+}
+
+// SaveGenreGroup creates or replaces the genre group with the ID in the path
+func SaveGenreGroup(genreTaxonomy services.GenreTaxonomyServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		groupID := genreGroupID(ctx)
+
+		var req GenreGroupRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("Invalid request format",
+				zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		group := models.GenreGroup{
+			ID:       groupID,
+			Name:     strings.TrimSpace(req.Name),
+			Position: req.Position,
+		}
+		seen := make(map[string]struct{})
+		addGenre := func(name string, synonym bool) {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, exists := seen[name]; exists || name == "" {
+				return
+			}
+			seen[name] = struct{}{}
+			group.Genres = append(group.Genres, models.GenreGroupGenre{Name: name, Synonym: synonym})
+		}
+		for _, genre := range req.Genres {
+			addGenre(genre, false)
+		}
+		for _, synonym := range req.Synonyms {
+			addGenre(synonym, true)
+		}
+		for _, url := range req.Playlists {
+			group.Playlists = append(group.Playlists, models.GenreGroupPlaylist{URL: url})
+		}
+		for _, image := range req.CoverImages {
+			group.CoverImages = append(group.CoverImages, models.GenreGroupCoverImage{Image: image})
+		}
+
+		if len(group.SearchGenres()) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "at least one genre is required"})
+			return
+		}
+
+		if err := genreTaxonomy.SaveGroup(&group); err != nil {
+			if errors.Is(err, services.ErrGenreTaken) {
+				ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			zap.L().Error("Failed to save genre group",
+				zap.String("group", groupID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save genre group"})
+			return
+		}
+
+		zap.L().Info("Saved genre group",
+			zap.String("group", groupID),
+			zap.String("userID", ctx.GetString("userID")))
+		ctx.JSON(http.StatusOK, newGenreGroupDetails(group))
+	}
+}
+
+// DeleteGenreGroup removes the genre group with the ID in the path
+func DeleteGenreGroup(genreTaxonomy services.GenreTaxonomyServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		groupID := genreGroupID(ctx)
+
+		deleted, err := genreTaxonomy.DeleteGroup(groupID)
+		if err != nil {
+			zap.L().Error("Failed to delete genre group",
+				zap.String("group", groupID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete genre group"})
+			return
+		}
+		if !deleted {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "genre group not found"})
+			return
+		}
+
+		zap.L().Info("Deleted genre group",
+			zap.String("group", groupID),
+			zap.String("userID", ctx.GetString("userID")))
+		ctx.JSON(http.StatusOK, gin.H{"message": "Genre group deleted successfully"})
+	}
+}
+
+// genreGroupID returns the genre group ID in the path the way groups are
+// saved: trimmed and lowercased
+func genreGroupID(ctx *gin.Context) string {
+	return strings.ToLower(strings.TrimSpace(ctx.Param("id")))
+}
+
+func newGenreGroupDetails(group models.GenreGroup) GenreGroupDetails {
+	details := GenreGroupDetails{
+		ID:          group.ID,
+		Name:        group.Name,
+		Position:    group.Position,
+		Genres:      group.SearchGenres(),
+		Synonyms:    []string{},
+		Playlists:   make([]string, 0, len(group.Playlists)),
+		CoverImages: make([]string, 0, len(group.CoverImages)),
+	}
+	for _, genre := range group.Genres {
+		if genre.Synonym {
+			details.Synonyms = append(details.Synonyms, genre.Name)
+		}
+	}
+	for _, playlist := range group.Playlists {
+		details.Playlists = append(details.Playlists, playlist.URL)
+	}
+	for _, image := range group.CoverImages {
+		details.CoverImages = append(details.CoverImages, image.Image)
+	}
+	return details
+}
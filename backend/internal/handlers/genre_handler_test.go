@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGenreHandlerTest(genreTaxonomy services.GenreTaxonomyServiceInterface, songRepo repository.SongRepositoryInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	// Add a mock context middleware to simulate authenticated user
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "test-user-id")
+		c.Next()
+	})
+
+	r.GET("/genres", ListGenres(genreTaxonomy, songRepo))
+	r.GET("/admin/genres", ListGenreGroups(genreTaxonomy))
+	r.PUT("/admin/genres/:id", SaveGenreGroup(genreTaxonomy))
+	r.DELETE("/admin/genres/:id", DeleteGenreGroup(genreTaxonomy))
+	return r
+}
+
+func TestListGenres(t *testing.T) {
+	t.Run("Lists_Groups_With_Counts", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		mockSongRepo := new(MockSongRepository)
+		r := setupGenreHandlerTest(mockTaxonomy, mockSongRepo)
+
+		mockTaxonomy.On("Groups").Return([]models.GenreGroup{rockGenreGroup})
+		mockSongRepo.On("CountSongsByGenreGroup", map[string][]string{"rock": {"pop", "rock"}}).Return(map[string]int{"rock": 42}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/genres", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response struct {
+			Genres []GenreGroupInfo `json:"genres"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, []GenreGroupInfo{
+			{ID: "rock", Name: "Rock / Pop", Genres: []string{"pop", "rock"}, SongCount: 42},
+		}, response.Genres)
+		mockSongRepo.AssertExpectations(t)
+	})
+
+	t.Run("Count_Error", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		mockSongRepo := new(MockSongRepository)
+		r := setupGenreHandlerTest(mockTaxonomy, mockSongRepo)
+
+		mockTaxonomy.On("Groups").Return([]models.GenreGroup{rockGenreGroup})
+		mockSongRepo.On("CountSongsByGenreGroup", mock.Anything).Return(nil, assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/genres", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+	})
+}
+
+func TestListGenreGroups(t *testing.T) {
+	// Arrange
+	mockTaxonomy := new(MockGenreTaxonomyService)
+	r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+	mockTaxonomy.On("Groups").Return([]models.GenreGroup{rockGenreGroup})
+
+	// Act
+	req := httptest.NewRequest("GET", "/admin/genres", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+	var response struct {
+		Genres []GenreGroupDetails `json:"genres"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+	require.Len(t, response.Genres, 1)
+	assert.Equal(t, []string{"rock and roll"}, response.Genres[0].Synonyms)
+	assert.Equal(t, []string{"https://open.spotify.com/playlist/rock"}, response.Genres[0].Playlists)
+}
+
+func TestSaveGenreGroup(t *testing.T) {
+	t.Run("Saves_Group", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+		expected := &models.GenreGroup{
+			ID:          "jazz",
+			Name:        "Jazz / Blues",
+			Genres:      []models.GenreGroupGenre{{Name: "jazz"}, {Name: "blues"}, {Name: "bop", Synonym: true}},
+			CoverImages: []models.GenreGroupCoverImage{{Image: "jazz_1.jpg"}},
+		}
+		mockTaxonomy.On("SaveGroup", expected).Return(nil)
+
+		body, _ := json.Marshal(GenreGroupRequest{
+			Name:        "Jazz / Blues",
+			Genres:      []string{"Jazz", "blues", "jazz"},
+			Synonyms:    []string{"Bop"},
+			CoverImages: []string{"jazz_1.jpg"},
+		})
+
+		// Act
+		req := httptest.NewRequest("PUT", "/admin/genres/jazz", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response GenreGroupDetails
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, []string{"blues", "jazz"}, response.Genres)
+		assert.Equal(t, []string{"bop"}, response.Synonyms)
+		mockTaxonomy.AssertExpectations(t)
+	})
+
+	t.Run("Genre_Taken", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+		mockTaxonomy.On("SaveGroup", mock.Anything).Return(fmt.Errorf("%w: %q is in %q", services.ErrGenreTaken, "rock", "rock"))
+
+		body, _ := json.Marshal(GenreGroupRequest{Name: "Guitar", Genres: []string{"rock"}})
+
+		// Act
+		req := httptest.NewRequest("PUT", "/admin/genres/guitar", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, resp.Code, "Should return Conflict status")
+	})
+
+	t.Run("Invalid_Request", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+		for _, request := range []GenreGroupRequest{
+			{Genres: []string{"jazz"}},
+			{Name: "Jazz"},
+			{Name: "Jazz", Genres: []string{" "}},
+		} {
+			body, _ := json.Marshal(request)
+
+			// Act
+			req := httptest.NewRequest("PUT", "/admin/genres/jazz", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code, "Should reject %+v", request)
+		}
+		mockTaxonomy.AssertNotCalled(t, "SaveGroup", mock.Anything)
+	})
+}
+
+func TestDeleteGenreGroup(t *testing.T) {
+	t.Run("Deletes_Group", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+		mockTaxonomy.On("DeleteGroup", "jazz").Return(true, nil)
+
+		// Act
+		req := httptest.NewRequest("DELETE", "/admin/genres/jazz", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		mockTaxonomy.AssertExpectations(t)
+	})
+
+	t.Run("Normalizes_ID", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+		mockTaxonomy.On("DeleteGroup", "jazz").Return(true, nil)
+
+		// Act
+		req := httptest.NewRequest("DELETE", "/admin/genres/%20Jazz%20", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should delete the group saved under the lowercased ID")
+		mockTaxonomy.AssertExpectations(t)
+	})
+
+	t.Run("Group_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreHandlerTest(mockTaxonomy, new(MockSongRepository))
+
+		mockTaxonomy.On("DeleteGroup", "polka").Return(false, nil)
+
+		// Act
+		req := httptest.NewRequest("DELETE", "/admin/genres/polka", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+	})
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultGenreProfileDepth matches the default depth of a genre search
+const defaultGenreProfileDepth = defaultGenreSearchDepth
+
+// GenreProfileRequest asks which genres the songs reachable from a set of seed
+// songs are tagged with. The fields work like those of SongSearchRequest.
+type GenreProfileRequest struct {
+	Songs    []models.SongQuery `json:"songs"`
+	MaxDepth int                `json:"maxDepth"`
+	// Direction is one of "ancestors", "descendants" or "both" (default)
+	Direction string `json:"direction"`
+	// YearFrom, YearTo and Era only count songs released in the range
+	YearFrom      int    `json:"yearFrom"`
+	YearTo        int    `json:"yearTo"`
+	Era           string `json:"era"`
+	Chronological bool   `json:"chronological"`
+}
+
+// GenreProfileResponse counts the songs reachable from the seeds, depth by depth
+type GenreProfileResponse struct {
+	Seeds  []SeedReport            `json:"seeds"`
+	Levels []GenreProfileLevelInfo `json:"levels"`
+	// Truncated says the row cap left reachable songs out of the counts
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// GenreProfileLevelInfo counts the songs first reached Depth sample hops away
+// from the seeds
+type GenreProfileLevelInfo struct {
+	Depth int `json:"depth"`
+	Songs int `json:"songs"`
+	// Genres counts songs per genre, a song with several genres counts in each
+	Genres []GenreCount `json:"genres"`
+	// Groups counts songs per genre group, once however many of the group's
+	// genres a song has
+	Groups []GenreCount `json:"groups"`
+}
+
+type GenreCount struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Songs int    `json:"songs"`
+}
+
+// GetGenreProfile counts, for every depth up to maxDepth, how many songs
+// reachable from the seeds fall in each genre and genre group
+func GetGenreProfile(songRepo repository.SongRepositoryInterface, genreTaxonomy services.GenreTaxonomyServiceInterface, budget models.SearchBudget) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req GenreProfileRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("Invalid request format",
+				zap.Error(err))
+
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if len(req.Songs) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "songs are required"})
+			return
+		}
+
+		if req.MaxDepth <= 0 {
+			req.MaxDepth = defaultGenreProfileDepth
+		}
+
+		direction, err := models.ParseTraversalDirection(req.Direction)
+		if err != nil {
+			zap.L().Error("Invalid search direction",
+				zap.String("direction", req.Direction))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		years, err := models.ParseYearRange(req.YearFrom, req.YearTo, req.Era)
+		if err != nil {
+			zap.L().Error("Invalid year range",
+				zap.Int("yearFrom", req.YearFrom),
+				zap.Int("yearTo", req.YearTo),
+				zap.String("era", req.Era))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		searchOpts := models.SearchOptions{
+			MaxDepth:      req.MaxDepth,
+			Direction:     direction,
+			Years:         years,
+			Chronological: req.Chronological,
+		}
+		if !checkSearchBudget(ctx, budget, len(req.Songs), searchOpts) {
+			return
+		}
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		seeds, seedReports, err := resolveSeeds(searchCtx, songRepo, req.Songs)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to match songs",
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to profile genres"})
+			return
+		}
+		// A seed can resolve to several songs, each of them searched from
+		if !checkSearchBudget(ctx, budget, len(seedSongIDs(seeds)), searchOpts) {
+			return
+		}
+
+		profile := &models.GenreProfile{}
+		if len(seeds) > 0 {
+			profile, err = songRepo.GetGenreProfile(searchCtx, seeds, budget.Apply(searchOpts))
+			if err != nil {
+				if searchAborted(ctx, searchCtx) {
+					return
+				}
+				zap.L().Error("Failed to profile genres",
+					zap.Any("songs", req.Songs),
+					zap.Error(err))
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to profile genres"})
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusOK, GenreProfileResponse{
+			Seeds:     seedReports,
+			Levels:    newGenreProfileLevels(profile.Levels, req.MaxDepth, genreTaxonomy),
+			Truncated: profile.Truncated,
+		})
+	}
+}
+
+// newGenreProfileLevels returns one level per depth from 1 to maxDepth, so
+// depths reaching no new song show up as zeros
+func newGenreProfileLevels(levels []models.GenreProfileLevel, maxDepth int, genreTaxonomy services.GenreTaxonomyServiceInterface) []GenreProfileLevelInfo {
+	byDepth := make(map[int]models.GenreProfileLevel, len(levels))
+	for _, level := range levels {
+		byDepth[level.Depth] = level
+	}
+
+	infos := make([]GenreProfileLevelInfo, 0, maxDepth)
+	for depth := 1; depth <= maxDepth; depth++ {
+		level := byDepth[depth]
+		genres := make(map[string]int)
+		groups := make(map[string]int)
+		groupNames := make(map[string]string)
+		for _, set := range level.GenreSets {
+			setGroups := make(map[string]struct{})
+			for _, genre := range set.Genres {
+				genres[genre] += set.Songs
+				group := genreTaxonomy.ResolveGenre(genre)
+				setGroups[group.ID] = struct{}{}
+				groupNames[group.ID] = group.Name
+			}
+			for id := range setGroups {
+				groups[id] += set.Songs
+			}
+		}
+
+		info := GenreProfileLevelInfo{
+			Depth:  depth,
+			Songs:  level.Songs(),
+			Genres: make([]GenreCount, 0, len(genres)),
+			Groups: make([]GenreCount, 0, len(groups)),
+		}
+		for genre, songs := range genres {
+			info.Genres = append(info.Genres, GenreCount{Name: genre, Songs: songs})
+		}
+		for id, songs := range groups {
+			info.Groups = append(info.Groups, GenreCount{ID: id, Name: groupNames[id], Songs: songs})
+		}
+		sortGenreCounts(info.Genres)
+		sortGenreCounts(info.Groups)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// sortGenreCounts puts the most common genres first, then sorts by name
+func sortGenreCounts(counts []GenreCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Songs != counts[j].Songs {
+			return counts[i].Songs > counts[j].Songs
+		}
+		return counts[i].Name < counts[j].Name
+	})
+}
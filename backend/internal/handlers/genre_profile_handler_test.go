@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGenreProfileTest(songRepo *MockSongRepository, genreTaxonomy *MockGenreTaxonomyService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/search/profile", GetGenreProfile(songRepo, genreTaxonomy, testSearchBudget))
+	return r
+}
+
+func TestGetGenreProfile(t *testing.T) {
+	seeds := []models.SongQuery{{SongIDs: []int{1}}}
+
+	t.Run("Counts_Genres_And_Groups", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		r := setupGenreProfileTest(mockRepo, mockGenreTaxonomy)
+
+		mockRepo.On("GetSongsWithDetails", []int{1}).Return(map[int]*models.SongNode{1: {ID: 1}}, nil)
+		mockRepo.On("GetGenreProfile", seeds, models.SearchOptions{MaxDepth: 3, Direction: models.DirectionDescendants}).
+			Return(&models.GenreProfile{Levels: []models.GenreProfileLevel{
+				{Depth: 1, GenreSets: []models.GenreSetCount{
+					{Genres: []string{"hip-hop", "rap"}, Songs: 2},
+					{Genres: []string{"rap"}, Songs: 1},
+					{Genres: nil, Songs: 1},
+				}},
+				{Depth: 3, GenreSets: []models.GenreSetCount{
+					{Genres: []string{"jazz"}, Songs: 4},
+				}},
+			}, Truncated: true}, nil)
+		hipHop := models.GenreGroup{ID: "hip-hop", Name: "Hip-Hop / Rap / R&B"}
+		mockGenreTaxonomy.On("ResolveGenre", "hip-hop").Return(hipHop)
+		mockGenreTaxonomy.On("ResolveGenre", "rap").Return(hipHop)
+		mockGenreTaxonomy.On("ResolveGenre", "jazz").Return(models.GenreGroup{ID: "jazz", Name: "Jazz / Blues"})
+
+		// Act
+		body, _ := json.Marshal(GenreProfileRequest{Songs: seeds, MaxDepth: 3, Direction: "descendants"})
+		req := httptest.NewRequest("POST", "/search/profile", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response GenreProfileResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		require.Len(t, response.Seeds, 1)
+		assert.Equal(t, SeedMatched, response.Seeds[0].Status)
+		require.Len(t, response.Levels, 3, "Every depth up to maxDepth should be listed")
+		assert.True(t, response.Truncated, "Should say the row cap left songs out")
+
+		assert.Equal(t, 4, response.Levels[0].Songs)
+		assert.Equal(t, []GenreCount{
+			{Name: "rap", Songs: 3},
+			{Name: "hip-hop", Songs: 2},
+		}, response.Levels[0].Genres)
+		assert.Equal(t, []GenreCount{
+			{ID: "hip-hop", Name: "Hip-Hop / Rap / R&B", Songs: 3},
+		}, response.Levels[0].Groups, "Songs with two genres of a group should count once")
+
+		assert.Equal(t, 2, response.Levels[1].Depth)
+		assert.Zero(t, response.Levels[1].Songs)
+		assert.Empty(t, response.Levels[1].Genres)
+		assert.Equal(t, []GenreCount{{ID: "jazz", Name: "Jazz / Blues", Songs: 4}}, response.Levels[2].Groups)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Direction", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupGenreProfileTest(mockRepo, new(MockGenreTaxonomyService))
+
+		// Act
+		body, _ := json.Marshal(GenreProfileRequest{Songs: seeds, Direction: "sideways"})
+		req := httptest.NewRequest("POST", "/search/profile", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		mockRepo.AssertNotCalled(t, "GetGenreProfile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Over_Budget", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupGenreProfileTest(mockRepo, new(MockGenreTaxonomyService))
+
+		// Act
+		body, _ := json.Marshal(GenreProfileRequest{Songs: seeds, MaxDepth: testSearchBudget.MaxDepth + 1})
+		req := httptest.NewRequest("POST", "/search/profile", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should reject searches over the budget")
+		mockRepo.AssertNotCalled(t, "GetGenreProfile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Repository_Error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupGenreProfileTest(mockRepo, new(MockGenreTaxonomyService))
+
+		mockRepo.On("GetSongsWithDetails", []int{1}).Return(map[int]*models.SongNode{1: {ID: 1}}, nil)
+		mockRepo.On("GetGenreProfile", seeds, mock.Anything).Return(nil, assert.AnError)
+
+		// Act
+		body, _ := json.Marshal(GenreProfileRequest{Songs: seeds})
+		req := httptest.NewRequest("POST", "/search/profile", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRepo.AssertExpectations(t)
+	})
+}
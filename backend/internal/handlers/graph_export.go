@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// graphFormat is a serialization of a GraphResponse picked with the format
+// query parameter or the Accept header
+type graphFormat string
+
+const (
+	graphFormatJSON      graphFormat = "json"
+	graphFormatGraphML   graphFormat = "graphml"
+	graphFormatGEXF      graphFormat = "gexf"
+	graphFormatDOT       graphFormat = "dot"
+	graphFormatCytoscape graphFormat = "cytoscape"
+)
+
+// graphFormatContentTypes are the media types each format is served as and
+// matched against in the Accept header
+var graphFormatContentTypes = map[graphFormat]string{
+	graphFormatJSON:      "application/json",
+	graphFormatGraphML:   "application/graphml+xml",
+	graphFormatGEXF:      "application/gexf+xml",
+	graphFormatDOT:       "text/vnd.graphviz",
+	graphFormatCytoscape: "application/vnd.cytoscape+json",
+}
+
+// negotiateGraphFormat reads the format query parameter, falling back to the
+// first Accept header media type naming a known format, and to JSON
+func negotiateGraphFormat(ctx *gin.Context) (graphFormat, error) {
+	if value := ctx.Query("format"); value != "" {
+		format := graphFormat(strings.ToLower(strings.TrimSpace(value)))
+		if _, ok := graphFormatContentTypes[format]; !ok {
+			return "", fmt.Errorf("format must be one of %s, %s, %s, %s or %s",
+				graphFormatJSON, graphFormatGraphML, graphFormatGEXF, graphFormatDOT, graphFormatCytoscape)
+		}
+		return format, nil
+	}
+
+	for _, accepted := range strings.Split(ctx.GetHeader("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		for format, contentType := range graphFormatContentTypes {
+			if mediaType == contentType {
+				return format, nil
+			}
+		}
+	}
+	return graphFormatJSON, nil
+}
+
+// writeGraph answers with graph serialized as format
+func writeGraph(ctx *gin.Context, format graphFormat, graph GraphResponse) {
+	var (
+		body []byte
+		err  error
+	)
+	switch format {
+	case graphFormatGraphML:
+		body, err = encodeGraphML(graph)
+	case graphFormatGEXF:
+		body, err = encodeGEXF(graph)
+	case graphFormatDOT:
+		body = encodeDOT(graph)
+	case graphFormatCytoscape:
+		body, err = encodeCytoscape(graph)
+	default:
+		ctx.JSON(http.StatusOK, graph)
+		return
+	}
+	if err != nil {
+		zap.L().Error("Failed to encode graph",
+			zap.String("format", string(format)),
+			zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode graph"})
+		return
+	}
+	ctx.Data(http.StatusOK, graphFormatContentTypes[format]+"; charset=utf-8", body)
+}
+
+// exportNode is a song with the indexes of the paths it is on
+type exportNode struct {
+	SongNode
+	Key   string
+	Paths []int
+}
+
+// exportEdge says Source sampled Target, on the paths with the given indexes
+type exportEdge struct {
+	Source string
+	Target string
+	Paths  []int
+}
+
+// exportGraph flattens a GraphResponse into ID-ordered nodes and sample edges
+// so every format lists them the same, deterministic way
+type exportGraph struct {
+	Nodes []exportNode
+	Edges []exportEdge
+}
+
+func newExportGraph(graph GraphResponse) exportGraph {
+	nodePaths := make(map[string][]int)
+	edgePaths := make(map[[2]string][]int)
+	var edgeOrder [][2]string
+
+	for i, path := range graph.Paths {
+		for j, key := range path.PathNodes {
+			if paths := nodePaths[key]; len(paths) == 0 || paths[len(paths)-1] != i {
+				nodePaths[key] = append(paths, i)
+			}
+			if j == 0 {
+				continue
+			}
+
+			// ancestors hops go to a song the previous one sampled, descendants
+			// hops to a song that sampled the previous one
+			edge := [2]string{path.PathNodes[j-1], key}
+			if j-1 < len(path.HopDirections) && path.HopDirections[j-1] == models.DirectionDescendants {
+				edge = [2]string{key, path.PathNodes[j-1]}
+			}
+			paths, seen := edgePaths[edge]
+			if !seen {
+				edgeOrder = append(edgeOrder, edge)
+			}
+			if len(paths) == 0 || paths[len(paths)-1] != i {
+				edgePaths[edge] = append(paths, i)
+			}
+		}
+	}
+
+	export := exportGraph{
+		Nodes: make([]exportNode, 0, len(graph.Nodes)),
+		Edges: make([]exportEdge, 0, len(edgeOrder)),
+	}
+	for key, node := range graph.Nodes {
+		export.Nodes = append(export.Nodes, exportNode{SongNode: node, Key: key, Paths: nodePaths[key]})
+	}
+	sort.Slice(export.Nodes, func(i, j int) bool {
+		return export.Nodes[i].ID < export.Nodes[j].ID
+	})
+	for _, edge := range edgeOrder {
+		export.Edges = append(export.Edges, exportEdge{Source: edge[0], Target: edge[1], Paths: edgePaths[edge]})
+	}
+	return export
+}
+
+func artistNames(artists []ArtistInfo) string {
+	names := make([]string, len(artists))
+	for i, artist := range artists {
+		names[i] = artist.Name
+	}
+	return strings.Join(names, "; ")
+}
+
+func joinPaths(paths []int) string {
+	parts := make([]string, len(paths))
+	for i, path := range paths {
+		parts[i] = strconv.Itoa(path)
+	}
+	return strings.Join(parts, ",")
+}
+
+// nodeAttributes are the string attributes GraphML, GEXF and DOT carry for a
+// node, in output order
+func nodeAttributes(node exportNode) [][2]string {
+	attributes := [][2]string{
+		{"title", node.Title},
+		{"artists", artistNames(node.Artists)},
+		{"genres", strings.Join(node.Genres, "; ")},
+	}
+	if node.ReleaseYear > 0 {
+		attributes = append(attributes, [2]string{"releaseYear", strconv.Itoa(node.ReleaseYear)})
+	}
+	return append(attributes, [2]string{"paths", joinPaths(node.Paths)})
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// encodeGraphML serializes graph as GraphML, the format yEd and Cytoscape
+// desktop import
+func encodeGraphML(graph GraphResponse) ([]byte, error) {
+	export := newExportGraph(graph)
+	document := graphMLDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "title", For: "node", AttrName: "title", AttrType: "string"},
+			{ID: "artists", For: "node", AttrName: "artists", AttrType: "string"},
+			{ID: "genres", For: "node", AttrName: "genres", AttrType: "string"},
+			{ID: "releaseYear", For: "node", AttrName: "releaseYear", AttrType: "int"},
+			{ID: "paths", For: "node", AttrName: "paths", AttrType: "string"},
+			{ID: "edgePaths", For: "edge", AttrName: "paths", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "samples", EdgeDefault: "directed"},
+	}
+
+	for _, node := range export.Nodes {
+		element := graphMLNode{ID: node.Key}
+		for _, attribute := range nodeAttributes(node) {
+			element.Data = append(element.Data, graphMLData{Key: attribute[0], Value: attribute[1]})
+		}
+		document.Graph.Nodes = append(document.Graph.Nodes, element)
+	}
+	for i, edge := range export.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: edge.Source,
+			Target: edge.Target,
+			Data:   []graphMLData{{Key: "edgePaths", Value: joinPaths(edge.Paths)}},
+		})
+	}
+	return marshalXML(document)
+}
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	Mode            string           `xml:"mode,attr"`
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// encodeGEXF serializes graph as GEXF 1.2, Gephi's native format
+func encodeGEXF(graph GraphResponse) ([]byte, error) {
+	export := newExportGraph(graph)
+	document := gexfDocument{
+		Xmlns:   "http://www.gexf.net/1.2draft",
+		Version: "1.2",
+		Graph: gexfGraph{
+			Mode:            "static",
+			DefaultEdgeType: "directed",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{
+					{ID: "title", Title: "title", Type: "string"},
+					{ID: "artists", Title: "artists", Type: "string"},
+					{ID: "genres", Title: "genres", Type: "string"},
+					{ID: "releaseYear", Title: "releaseYear", Type: "integer"},
+					{ID: "paths", Title: "paths", Type: "string"},
+				}},
+				{Class: "edge", Attributes: []gexfAttribute{
+					{ID: "paths", Title: "paths", Type: "string"},
+				}},
+			},
+		},
+	}
+
+	for _, node := range export.Nodes {
+		element := gexfNode{ID: node.Key, Label: node.Title}
+		for _, attribute := range nodeAttributes(node) {
+			element.AttValues = append(element.AttValues, gexfAttValue{For: attribute[0], Value: attribute[1]})
+		}
+		document.Graph.Nodes = append(document.Graph.Nodes, element)
+	}
+	for i, edge := range export.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, gexfEdge{
+			ID:        strconv.Itoa(i),
+			Source:    edge.Source,
+			Target:    edge.Target,
+			AttValues: []gexfAttValue{{For: "paths", Value: joinPaths(edge.Paths)}},
+		})
+	}
+	return marshalXML(document)
+}
+
+func marshalXML(document interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding graph: %v", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// dotQuote quotes a Graphviz DOT string
+func dotQuote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + value + `"`
+}
+
+// encodeDOT serializes graph as a Graphviz digraph, edges pointing from the
+// sampling song to the sampled one
+func encodeDOT(graph GraphResponse) []byte {
+	export := newExportGraph(graph)
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph samples {\n")
+	for _, node := range export.Nodes {
+		attributes := []string{"label=" + dotQuote(node.Title)}
+		for _, attribute := range nodeAttributes(node) {
+			attributes = append(attributes, attribute[0]+"="+dotQuote(attribute[1]))
+		}
+		fmt.Fprintf(&buf, "  %s [%s];\n", dotQuote(node.Key), strings.Join(attributes, ", "))
+	}
+	for _, edge := range export.Edges {
+		fmt.Fprintf(&buf, "  %s -> %s [paths=%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), dotQuote(joinPaths(edge.Paths)))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// cytoscapeDocument is the Cytoscape.js elements JSON, with the search paths
+// and seed reports kept as graph data
+type cytoscapeDocument struct {
+	Data     cytoscapeGraphData `json:"data"`
+	Elements cytoscapeElements  `json:"elements"`
+}
+
+type cytoscapeGraphData struct {
+	Paths []PathInfo   `json:"paths"`
+	Seeds []SeedReport `json:"seeds,omitempty"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeElement `json:"nodes"`
+	Edges []cytoscapeElement `json:"edges"`
+}
+
+type cytoscapeElement struct {
+	Data cytoscapeData `json:"data"`
+}
+
+type cytoscapeData struct {
+	ID          string       `json:"id"`
+	Source      string       `json:"source,omitempty"`
+	Target      string       `json:"target,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Artists     []ArtistInfo `json:"artists,omitempty"`
+	Genres      []string     `json:"genres,omitempty"`
+	ReleaseYear int          `json:"releaseYear,omitempty"`
+	Paths       []int        `json:"paths"`
+}
+
+// encodeCytoscape serializes graph as Cytoscape.js elements JSON
+func encodeCytoscape(graph GraphResponse) ([]byte, error) {
+	export := newExportGraph(graph)
+	document := cytoscapeDocument{
+		Data: cytoscapeGraphData{Paths: graph.Paths, Seeds: graph.Seeds},
+		Elements: cytoscapeElements{
+			Nodes: make([]cytoscapeElement, 0, len(export.Nodes)),
+			Edges: make([]cytoscapeElement, 0, len(export.Edges)),
+		},
+	}
+
+	for _, node := range export.Nodes {
+		document.Elements.Nodes = append(document.Elements.Nodes, cytoscapeElement{Data: cytoscapeData{
+			ID:          node.Key,
+			Title:       node.Title,
+			Artists:     node.Artists,
+			Genres:      node.Genres,
+			ReleaseYear: node.ReleaseYear,
+			Paths:       append([]int{}, node.Paths...),
+		}})
+	}
+	for i, edge := range export.Edges {
+		document.Elements.Edges = append(document.Elements.Edges, cytoscapeElement{Data: cytoscapeData{
+			ID:     "e" + strconv.Itoa(i),
+			Source: edge.Source,
+			Target: edge.Target,
+			Paths:  edge.Paths,
+		}})
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding graph: %v", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportTestGraph has a seed (1) that sampled a song (2) that was also
+// sampled by a third song (3), found over two paths
+func exportTestGraph() GraphResponse {
+	seed := models.SongNode{ID: 1, Title: "Seed & Song", Artists: []models.Artist{{ID: 1, Name: "Seed Artist", IsMain: true}}, Genres: []string{"hip hop"}, ReleaseYear: 1994}
+	soul := models.SongNode{ID: 2, Title: "Soul Song", Artists: []models.Artist{{ID: 2, Name: "Soul \"Singer\""}}, Genres: []string{"soul", "funk"}}
+	later := models.SongNode{ID: 3, Title: "Later Song"}
+	return newGraphResponse([]models.SearchResult{
+		{
+			SourceSong:    seed,
+			MatchedSong:   soul,
+			Distance:      1,
+			Path:          []models.SongNode{seed, soul},
+			HopDirections: []models.TraversalDirection{models.DirectionAncestors},
+		},
+		{
+			SourceSong:    seed,
+			MatchedSong:   later,
+			Distance:      2,
+			Path:          []models.SongNode{seed, soul, later},
+			HopDirections: []models.TraversalDirection{models.DirectionAncestors, models.DirectionDescendants},
+		},
+	})
+}
+
+func TestNewExportGraph(t *testing.T) {
+	// Act
+	export := newExportGraph(exportTestGraph())
+
+	// Assert
+	require.Len(t, export.Nodes, 3)
+	assert.Equal(t, []int{0, 1}, export.Nodes[0].Paths)
+	assert.Equal(t, []int{0, 1}, export.Nodes[1].Paths)
+	assert.Equal(t, []int{1}, export.Nodes[2].Paths)
+	assert.Equal(t, []exportEdge{
+		{Source: "1", Target: "2", Paths: []int{0, 1}},
+		{Source: "3", Target: "2", Paths: []int{1}},
+	}, export.Edges, "Edges should point from the sampling song to the sampled one")
+}
+
+func TestEncodeGraphML(t *testing.T) {
+	// Act
+	body, err := encodeGraphML(exportTestGraph())
+	require.NoError(t, err)
+
+	// Assert
+	var document graphMLDocument
+	require.NoError(t, xml.Unmarshal(body, &document), "Should be valid XML")
+	assert.Equal(t, "directed", document.Graph.EdgeDefault)
+	require.Len(t, document.Graph.Nodes, 3)
+	assert.Equal(t, []graphMLData{
+		{Key: "title", Value: "Seed & Song"},
+		{Key: "artists", Value: "Seed Artist"},
+		{Key: "genres", Value: "hip hop"},
+		{Key: "releaseYear", Value: "1994"},
+		{Key: "paths", Value: "0,1"},
+	}, document.Graph.Nodes[0].Data)
+	require.Len(t, document.Graph.Edges, 2)
+	assert.Equal(t, "3", document.Graph.Edges[1].Source)
+	assert.Equal(t, []graphMLData{{Key: "edgePaths", Value: "1"}}, document.Graph.Edges[1].Data)
+}
+
+func TestEncodeGEXF(t *testing.T) {
+	// Act
+	body, err := encodeGEXF(exportTestGraph())
+	require.NoError(t, err)
+
+	// Assert
+	var document gexfDocument
+	require.NoError(t, xml.Unmarshal(body, &document), "Should be valid XML")
+	require.Len(t, document.Graph.Nodes, 3)
+	assert.Equal(t, "Soul Song", document.Graph.Nodes[1].Label)
+	assert.Contains(t, document.Graph.Nodes[1].AttValues, gexfAttValue{For: "artists", Value: `Soul "Singer"`})
+	assert.Contains(t, document.Graph.Nodes[1].AttValues, gexfAttValue{For: "genres", Value: "soul; funk"})
+	require.Len(t, document.Graph.Edges, 2)
+	assert.Equal(t, []gexfAttValue{{For: "paths", Value: "0,1"}}, document.Graph.Edges[0].AttValues)
+}
+
+func TestEncodeDOT(t *testing.T) {
+	// Act
+	body := string(encodeDOT(exportTestGraph()))
+
+	// Assert
+	assert.Contains(t, body, "digraph samples {")
+	assert.Contains(t, body, `"2" [label="Soul Song", title="Soul Song", artists="Soul \"Singer\"", genres="soul; funk", paths="0,1"];`,
+		"Should escape quotes in attributes")
+	assert.Contains(t, body, `"1" -> "2" [paths="0,1"];`)
+	assert.Contains(t, body, `"3" -> "2" [paths="1"];`)
+}
+
+func TestEncodeCytoscape(t *testing.T) {
+	// Act
+	body, err := encodeCytoscape(exportTestGraph())
+	require.NoError(t, err)
+
+	// Assert
+	var document cytoscapeDocument
+	require.NoError(t, json.Unmarshal(body, &document), "Should be valid JSON")
+	assert.Len(t, document.Data.Paths, 2)
+	require.Len(t, document.Elements.Nodes, 3)
+	assert.Equal(t, cytoscapeData{
+		ID:          "1",
+		Title:       "Seed & Song",
+		Artists:     []ArtistInfo{{ID: 1, Name: "Seed Artist", IsMain: true}},
+		Genres:      []string{"hip hop"},
+		ReleaseYear: 1994,
+		Paths:       []int{0, 1},
+	}, document.Elements.Nodes[0].Data)
+	require.Len(t, document.Elements.Edges, 2)
+	assert.Equal(t, cytoscapeData{ID: "e1", Source: "3", Target: "2", Paths: []int{1}}, document.Elements.Edges[1].Data)
+}
+
+func TestNegotiateGraphFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		accept   string
+		expected graphFormat
+		wantErr  bool
+	}{
+		{name: "Default", expected: graphFormatJSON},
+		{name: "Query", query: "GraphML", expected: graphFormatGraphML},
+		{name: "Query_Over_Accept", query: "dot", accept: "application/gexf+xml", expected: graphFormatDOT},
+		{name: "Accept", accept: "text/html, application/gexf+xml;q=0.9", expected: graphFormatGEXF},
+		{name: "Accept_Cytoscape", accept: "application/vnd.cytoscape+json", expected: graphFormatCytoscape},
+		{name: "Unknown_Accept", accept: "text/csv", expected: graphFormatJSON},
+		{name: "Unknown_Query", query: "csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/search?format="+tt.query, nil)
+			if tt.accept != "" {
+				ctx.Request.Header.Set("Accept", tt.accept)
+			}
+
+			// Act
+			format, err := negotiateGraphFormat(ctx)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
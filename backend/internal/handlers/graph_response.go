@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// graphBuilder accumulates songs, sample edges and paths into a GraphResponse
+type graphBuilder struct {
+	response GraphResponse
+}
+
+func newGraphBuilder() *graphBuilder {
+	return &graphBuilder{
+		response: GraphResponse{
+			AdjacencyList: make(map[string]map[string]interface{}),
+			Nodes:         make(map[string]SongNode),
+			Paths:         make([]PathInfo, 0),
+		},
+	}
+}
+
+// newGraphResponse builds the graph for a set of already hydrated search results
+func newGraphResponse(results []models.SearchResult) GraphResponse {
+	builder := newGraphBuilder()
+	for _, result := range results {
+		builder.addResult(result)
+	}
+	return builder.build()
+}
+
+func songKey(id int) string {
+	return strconv.Itoa(id)
+}
+
+func toSongNode(song models.SongNode) SongNode {
+	return SongNode{
+		ID:          song.ID,
+		Title:       song.Title,
+		Artists:     transformToArtistInfo(song.Artists),
+		Genres:      song.Genres,
+		ReleaseYear: song.ReleaseYear,
+	}
+}
+
+func (b *graphBuilder) addNode(song models.SongNode) {
+	key := songKey(song.ID)
+	if _, exists := b.response.Nodes[key]; !exists {
+		b.response.Nodes[key] = toSongNode(song)
+	}
+}
+
+// addEdge links two songs in the adjacency list (bidirectional)
+func (b *graphBuilder) addEdge(fromID, toID int) {
+	from, to := songKey(fromID), songKey(toID)
+	if b.response.AdjacencyList[from] == nil {
+		b.response.AdjacencyList[from] = make(map[string]interface{})
+	}
+	if b.response.AdjacencyList[to] == nil {
+		b.response.AdjacencyList[to] = make(map[string]interface{})
+	}
+	b.response.AdjacencyList[from][to] = struct{}{}
+	b.response.AdjacencyList[to][from] = struct{}{}
+}
+
+func (b *graphBuilder) addResult(result models.SearchResult) {
+	b.addNode(result.SourceSong)
+	b.addNode(result.MatchedSong)
+
+	pathNodes := make([]string, len(result.Path))
+	for i, node := range result.Path {
+		b.addNode(node)
+		if i > 0 {
+			b.addEdge(result.Path[i-1].ID, node.ID)
+		}
+		pathNodes[i] = songKey(node.ID)
+	}
+
+	b.response.Paths = append(b.response.Paths, PathInfo{
+		Start:         songKey(result.SourceSong.ID),
+		End:           songKey(result.MatchedSong.ID),
+		PathNodes:     pathNodes,
+		HopDirections: result.HopDirections,
+		Distance:      result.Distance,
+		Score:         result.Score,
+	})
+}
+
+func (b *graphBuilder) build() GraphResponse {
+	return b.response
+}
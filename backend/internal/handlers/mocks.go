@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Emeruem-Kennedy1/ghopper/internal/auth"
@@ -119,6 +120,16 @@ func (m *MockSpotifyClient) GetPlaylistTracks(playlistID spotify.ID) (*spotify.P
 	return args.Get(0).(*spotify.PlaylistTrackPage), args.Error(1)
 }
 
+func (m *MockSpotifyClient) GetPlaylistTracksOpt(playlistID spotify.ID, opt *spotify.Options, fields string) (*spotify.PlaylistTrackPage, error) {
+	args := m.Called(playlistID, opt, fields)
+	return args.Get(0).(*spotify.PlaylistTrackPage), args.Error(1)
+}
+
+func (m *MockSpotifyClient) CurrentUsersPlaylistsOpt(opt *spotify.Options) (*spotify.SimplePlaylistPage, error) {
+	args := m.Called(opt)
+	return args.Get(0).(*spotify.SimplePlaylistPage), args.Error(1)
+}
+
 func (m *MockSpotifyClient) AddTracksToPlaylist(playlistID spotify.ID, trackIDs ...spotify.ID) (string, error) {
 	args := m.Called(playlistID, trackIDs)
 	return args.String(0), args.Error(1)
@@ -147,26 +158,106 @@ func (m *MockSpotifyClient) CurrentUsersTopArtistsOpt(opts *spotify.Options) (*s
 	return args.Get(0).(*spotify.FullArtistPage), args.Error(1)
 }
 
-// ! MockSongRepository is a mock implementation of the SongRepository
+// ! MockSongRepository is a mock implementation of the SongRepository, it ignores contexts
 type MockSongRepository struct {
 	mock.Mock
 }
 
-func (m *MockSongRepository) FindSongsByGenreBFS(songQueries []models.SongQuery, targetGenre string, maxDepth int) ([]models.SearchResult, error) {
-	args := m.Called(songQueries, targetGenre, maxDepth)
+func (m *MockSongRepository) CountSongsByGenreGroup(_ context.Context, genreGroups map[string][]string) (map[string]int, error) {
+	args := m.Called(genreGroups)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockSongRepository) FindSongsByGenreBFS(_ context.Context, songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) ([]models.SearchResult, error) {
+	args := m.Called(songQueries, filter, opts)
+	return args.Get(0).([]models.SearchResult), args.Error(1)
+}
+
+func (m *MockSongRepository) MatchSongs(_ context.Context, query models.SongQuery) ([]models.SongMatch, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SongMatch), args.Error(1)
+}
+
+func (m *MockSongRepository) SearchSongs(_ context.Context, query models.SongSearchQuery) ([]models.SongNode, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SongNode), args.Error(1)
+}
+
+func (m *MockSongRepository) FindSamplePaths(_ context.Context, fromIDs, toIDs []int, opts models.SearchOptions) ([]models.SearchResult, error) {
+	args := m.Called(fromIDs, toIDs, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]models.SearchResult), args.Error(1)
 }
 
-func (m *MockSongRepository) GetAllSampledSongs(limit int) ([]int, error) {
+func (m *MockSongRepository) GetArtistNetwork(_ context.Context, artistID int, opts models.ArtistNetworkOptions) (*models.ArtistNetwork, error) {
+	args := m.Called(artistID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ArtistNetwork), args.Error(1)
+}
+
+func (m *MockSongRepository) GetSongNeighborhood(_ context.Context, songID int, opts models.NeighborhoodOptions) (*models.SongNeighborhood, error) {
+	args := m.Called(songID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SongNeighborhood), args.Error(1)
+}
+
+func (m *MockSongRepository) GetGenreProfile(_ context.Context, songQueries []models.SongQuery, opts models.SearchOptions) (*models.GenreProfile, error) {
+	args := m.Called(songQueries, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GenreProfile), args.Error(1)
+}
+
+func (m *MockSongRepository) GetSongIDsByArtist(_ context.Context, artist string, limit int) ([]int, error) {
+	args := m.Called(artist, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockSongRepository) GetAllSampledSongs(_ context.Context, limit int) ([]int, error) {
 	args := m.Called(limit)
 	return args.Get(0).([]int), args.Error(1)
 }
 
-func (m *MockSongRepository) GetSongWithDetails(SongID int) (*models.SongNode, error) {
+func (m *MockSongRepository) GetSampleLineage(_ context.Context, songID int) (*models.SampleLineage, error) {
+	args := m.Called(songID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleLineage), args.Error(1)
+}
+
+func (m *MockSongRepository) GetSongWithDetails(_ context.Context, SongID int) (*models.SongNode, error) {
 	args := m.Called(SongID)
 	return args.Get(0).(*models.SongNode), args.Error(1)
 }
-func (m *MockSongRepository) GetSongIDsByTitleAndArtist(title, artist string) ([]int, error) {
+func (m *MockSongRepository) GetSongsWithDetails(_ context.Context, ids []int) (map[int]*models.SongNode, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]*models.SongNode), args.Error(1)
+}
+
+func (m *MockSongRepository) GetSongIDsByTitleAndArtist(_ context.Context, title, artist string) ([]int, error) {
 	args := m.Called(title, artist)
 	return args.Get(0).([]int), args.Error(1)
 }
@@ -196,6 +287,14 @@ func (m *MockSpotifyService) GetPlaylistImageURL(userID, playlistID string) (str
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockSpotifyService) GetUserPlaylistSongs(userID string) ([]models.SongQuery, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SongQuery), args.Error(1)
+}
+
 // ! MockClientManager for testing
 type MockClientManager struct {
 	mock.Mock
@@ -277,3 +376,111 @@ func (m *MockSpotifySongRepository) DeleteUserPlaylists(userID string) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
+
+// ! MockSampleDNAService for testing
+type MockSampleDNAService struct {
+	mock.Mock
+}
+
+// Ensure the mock implements the interface
+var _ services.SampleDNAServiceInterface = (*MockSampleDNAService)(nil)
+
+func (m *MockSampleDNAService) Report(_ context.Context, tracks []models.TopTrack, opts models.SampleDNAOptions) (*models.SampleDNA, error) {
+	args := m.Called(tracks, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleDNA), args.Error(1)
+}
+
+// ! MockGenreTaxonomyService for testing
+type MockGenreTaxonomyService struct {
+	mock.Mock
+}
+
+// Ensure the mock implements the interface
+var _ services.GenreTaxonomyServiceInterface = (*MockGenreTaxonomyService)(nil)
+
+func (m *MockGenreTaxonomyService) Groups() []models.GenreGroup {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]models.GenreGroup)
+}
+
+func (m *MockGenreTaxonomyService) ResolveGenre(genre string) models.GenreGroup {
+	args := m.Called(genre)
+	return args.Get(0).(models.GenreGroup)
+}
+
+func (m *MockGenreTaxonomyService) SaveGroup(group *models.GenreGroup) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGenreTaxonomyService) DeleteGroup(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+// ! MockExclusionRepository is a mock implementation of the ExclusionRepository
+type MockExclusionRepository struct {
+	mock.Mock
+}
+
+var _ repository.ExclusionRepositoryInterface = (*MockExclusionRepository)(nil)
+
+func (m *MockExclusionRepository) ListExclusions(userID string) ([]models.UserExclusion, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserExclusion), args.Error(1)
+}
+
+func (m *MockExclusionRepository) AddExclusion(exclusion *models.UserExclusion) error {
+	args := m.Called(exclusion)
+	return args.Error(0)
+}
+
+func (m *MockExclusionRepository) DeleteExclusion(userID string, id uint) (bool, error) {
+	args := m.Called(userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// ! MockSearchCache is a mock implementation of the SearchCacheInterface
+type MockSearchCache struct {
+	mock.Mock
+}
+
+var _ repository.SearchCacheInterface = (*MockSearchCache)(nil)
+
+func (m *MockSearchCache) Stats() models.SearchCacheStats {
+	args := m.Called()
+	return args.Get(0).(models.SearchCacheStats)
+}
+
+func (m *MockSearchCache) Invalidate() {
+	m.Called()
+}
+
+// ! MockRadioService is a mock implementation of the RadioServiceInterface
+type MockRadioService struct {
+	mock.Mock
+}
+
+var _ services.RadioServiceInterface = (*MockRadioService)(nil)
+
+func (m *MockRadioService) Start(_ context.Context, userID string, seedIDs []int, genre string) (string, error) {
+	args := m.Called(userID, seedIDs, genre)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRadioService) Next(_ context.Context, userID, sessionID string, count int) ([]models.RadioTrack, error) {
+	args := m.Called(userID, sessionID, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RadioTrack), args.Error(1)
+}
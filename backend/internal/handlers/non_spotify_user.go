@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
 	"github.com/Emeruem-Kennedy1/ghopper/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -41,6 +41,20 @@ type NonSpotifyPlaylistRequest struct {
 		Artist string `json:"artist" binding:"required"`
 	} `json:"seed_tracks" binding:"required,min=1,max=20"`
 	Genre string `json:"genre" binding:"required"`
+	// ExcludeGenres drops songs tagged with any of these genres
+	ExcludeGenres []string `json:"exclude_genres"`
+	// ExcludeArtists and ExcludeSongs add to the user's saved exclusions
+	ExcludeArtists []string           `json:"exclude_artists"`
+	ExcludeSongs   []models.SongQuery `json:"exclude_songs"`
+	// ExcludeKnownTracks drops the tracks of the user's earlier playlists
+	ExcludeKnownTracks bool `json:"exclude_known_tracks"`
+	// YearFrom, YearTo and Era bound the release years of playlist tracks
+	YearFrom int    `json:"year_from"`
+	YearTo   int    `json:"year_to"`
+	Era      string `json:"era"`
+	// Chronological only follows samples whose original is not newer than the
+	// song sampling it
+	Chronological bool `json:"chronological"`
 }
 
 // UpdateTrackStatusRequest contains data to update a track's status
@@ -132,6 +146,10 @@ func VerifyNonSpotifyUser(userRepo *repository.NonSpotifyUserRepository) gin.Han
 func GenerateNonSpotifyPlaylist(
 	userRepo *repository.NonSpotifyUserRepository,
 	songRepo repository.SongRepositoryInterface,
+	genreTaxonomy services.GenreTaxonomyServiceInterface,
+	exclusionRepo repository.ExclusionRepositoryInterface,
+	diversity models.DiversityOptions,
+	budget models.SearchBudget,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("userID")
@@ -146,6 +164,14 @@ func GenerateNonSpotifyPlaylist(
 			return
 		}
 
+		years, err := models.ParseYearRange(req.YearFrom, req.YearTo, req.Era)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		genreGroup := genreTaxonomy.ResolveGenre(req.Genre)
+
 		// Convert seed tracks to song queries
 		songQueries := make([]models.SongQuery, len(req.SeedTracks))
 		for i, track := range req.SeedTracks {
@@ -156,24 +182,89 @@ func GenerateNonSpotifyPlaylist(
 		}
 
 		// Search for songs by genre using the sample song repository
-		maxDepth := 2 // Adjust as needed
-		searchResults, err := songRepo.FindSongsByGenreBFS(songQueries, req.Genre, maxDepth)
+		searchOpts := models.SearchOptions{
+			MaxDepth:      2,
+			Direction:     models.DirectionBoth,
+			Limit:         playlistCandidateLimit,
+			Years:         years,
+			Chronological: req.Chronological,
+		}
+		if !checkSearchBudget(c, budget, len(songQueries), searchOpts) {
+			return
+		}
+		searchCtx, cancel := budget.Context(c.Request.Context())
+		defer cancel()
+
+		seeds, seedReports, err := resolveSeeds(searchCtx, songRepo, songQueries)
+		if err != nil {
+			if searchAborted(c, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to match seed tracks", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate playlist"})
+			return
+		}
+		// A seed can resolve to several songs, each of them searched from
+		if !checkSearchBudget(c, budget, len(seedSongIDs(seeds)), searchOpts) {
+			return
+		}
+
+		exclusions, err := loadSearchExclusions(exclusionRepo, userID.(string), req.ExcludeArtists, req.ExcludeSongs)
 		if err != nil {
-			zap.L().Error("Failed to search for songs", zap.Error(err))
+			zap.L().Error("Failed to load exclusions", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate playlist"})
 			return
 		}
+		if req.ExcludeKnownTracks {
+			knownTracks, err := userRepo.GetUserTracks(userID.(string))
+			if err != nil {
+				zap.L().Error("Failed to get known tracks", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate playlist"})
+				return
+			}
+			for _, track := range knownTracks {
+				exclusions.Songs = append(exclusions.Songs, models.SongQuery{Title: track.Title, Artist: track.Artist})
+			}
+		}
+
+		var searchResults []models.SearchResult
+		if len(seeds) > 0 {
+			searchOpts.Exclude = exclusions
+			searchResults, err = songRepo.FindSongsByGenreBFS(searchCtx, seeds, models.GenreFilter{
+				Genres:  genreGroup.SearchGenres(),
+				Match:   models.GenreMatchAny,
+				Exclude: req.ExcludeGenres,
+			}, budget.Apply(searchOpts))
+			if err != nil {
+				if searchAborted(c, searchCtx) {
+					return
+				}
+				zap.L().Error("Failed to search for songs", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate playlist"})
+				return
+			}
+		}
+
+		countSeedResults(seedReports, searchResults)
 
 		if len(searchResults) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No matching songs found for the given genre and seed tracks"})
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":       "No matching songs found for the given genre and seed tracks",
+				"seed_report": seedReports,
+			})
 			return
 		}
 
-		// Create a map to avoid duplicates
-		uniqueTracks := make(map[string]models.NonSpotifyPlaylistTrack)
+		// Results come best first, so keep the first of any duplicates
+		var tracks []models.NonSpotifyPlaylistTrack
+		seenTracks := make(map[string]struct{})
 
 		// Helper function to check if a track matches any seed track
-		isASeedTrack := func(title, artist string) bool {
+		matchedSeeds := seedSongIDs(seeds)
+		isASeedTrack := func(id int, title, artist string) bool {
+			if _, isSeed := matchedSeeds[id]; isSeed {
+				return true
+			}
 			for _, seed := range req.SeedTracks {
 				if strings.EqualFold(seed.Title, title) && strings.EqualFold(seed.Artist, artist) {
 					return true
@@ -182,32 +273,29 @@ func GenerateNonSpotifyPlaylist(
 			return false
 		}
 
+		candidates := make([]models.SearchResult, 0, len(searchResults))
 		for _, result := range searchResults {
-
-			if isASeedTrack(result.MatchedSong.Title, result.MatchedSong.Artists[0].Name) {
-				continue
+			if !isASeedTrack(result.MatchedSong.ID, result.MatchedSong.Title, result.MatchedSong.Artists[0].Name) {
+				candidates = append(candidates, result)
 			}
+		}
 
+		for _, result := range diversifyResults(candidates, diversity, playlistSongLimit) {
 			// Use artist and title as the key
 			key := fmt.Sprintf("%s-%s", result.MatchedSong.Title, result.MatchedSong.Artists[0].Name)
-			if _, exists := uniqueTracks[key]; !exists {
-				uniqueTracks[key] = models.NonSpotifyPlaylistTrack{
+			if _, exists := seenTracks[key]; !exists {
+				seenTracks[key] = struct{}{}
+				tracks = append(tracks, models.NonSpotifyPlaylistTrack{
 					ID:              uuid.New().String(),
 					Title:           result.MatchedSong.Title,
 					Artist:          result.MatchedSong.Artists[0].Name,
 					AddedToPlaylist: false,
 					CreatedAt:       time.Now(),
 					UpdatedAt:       time.Now(),
-				}
+				})
 			}
 		}
 
-		// Convert map to slice
-		tracks := make([]models.NonSpotifyPlaylistTrack, 0, len(uniqueTracks))
-		for _, track := range uniqueTracks {
-			tracks = append(tracks, track)
-		}
-
 		// Create seed tracks for the playlist
 		seedTracks := make([]models.NonSpotifyPlaylistSeedTrack, len(req.SeedTracks))
 		for i, seed := range req.SeedTracks {
@@ -229,7 +317,7 @@ func GenerateNonSpotifyPlaylist(
 			Name:        fmt.Sprintf("%s-%s-playlist", req.Genre, dateStr),
 			Genre:       req.Genre,
 			Description: fmt.Sprintf("Playlist generated for the %s genre", req.Genre),
-			ImageURL:    generateImageURL(req.Genre), // Implement this function to generate image URLs
+			ImageURL:    generateImageURL(genreGroup),
 			CreatedAt:   now,
 			UpdatedAt:   now,
 		}
@@ -248,6 +336,7 @@ func GenerateNonSpotifyPlaylist(
 				Tracks:             tracks,
 				SeedTracks:         seedTracks,
 			},
+			"seed_report": seedReports,
 		})
 	}
 }
@@ -374,37 +463,19 @@ func DeleteNonSpotifyPlaylist(userRepo *repository.NonSpotifyUserRepository) gin
 	}
 }
 
-// Helper function to generate image URLs based on genre
-func generateImageURL(genre string) string {
-	normalizedGenre := strings.ToLower(strings.ReplaceAll(genre, " ", "-"))
-
-	counts := map[string]int{
-		"pop":        5,
-		"jazz":       6,
-		"hip-hop":    6,
-		"country":    6,
-		"electronic": 5,
-		"reggae":     5,
-		"soundtrack": 5,
-		"world":      6,
-		"classical":  5,
-		"soul":       6,
-	}
-
-	genre_count, exists := counts[normalizedGenre]
-	if !exists || genre_count <= 0 {
-		genre_count = 1
+// Helper function to pick one of the genre group's cover images
+func generateImageURL(group models.GenreGroup) string {
+	if len(group.CoverImages) == 0 {
+		return fmt.Sprintf("%s_1.jpg", group.ID)
 	}
 
-	max := big.NewInt(int64(genre_count))
+	max := big.NewInt(int64(len(group.CoverImages)))
 	n, err := rand.Int(rand.Reader, max)
 
 	if err != nil {
 		zap.L().Error("Failed to generate random number", zap.Error(err))
 		return "default.jpg" // TODO: add a default if random generation fails
 	}
-	number := n.Int64() + 1
 
-	// Return a placeholder URL that includes the genre
-	return fmt.Sprintf("%s_%s.jpg", normalizedGenre, strconv.FormatInt(number, 10))
+	return group.CoverImages[n.Int64()].Image
 }
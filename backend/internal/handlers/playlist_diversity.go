@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// diversifyResults picks up to limit ranked genre search results for a
+// playlist. Each matched song is kept once, opts caps how many tracks may share
+// a main artist or a seed, and the rest are re-ranked with maximal marginal
+// relevance: every pick maximises
+//
+//	Lambda*score - (1-Lambda)*similarity to the tracks already picked
+//
+// where two tracks sharing a main artist are fully similar and two tracks from
+// the same seed are half similar. A limit of 0 keeps every eligible result.
+func diversifyResults(results []models.SearchResult, opts models.DiversityOptions, limit int) []models.SearchResult {
+	lambda := min(max(opts.Lambda, 0), 1)
+
+	type candidate struct {
+		result  models.SearchResult
+		artists []int
+		// similarity is the highest similarity to any picked track
+		similarity float64
+	}
+
+	var candidates []*candidate
+	seen := make(map[int]struct{}, len(results))
+	for _, result := range results {
+		if _, exists := seen[result.MatchedSong.ID]; exists {
+			continue
+		}
+		seen[result.MatchedSong.ID] = struct{}{}
+		candidates = append(candidates, &candidate{result: result, artists: mainArtistIDs(result.MatchedSong)})
+	}
+
+	perArtist := make(map[int]int)
+	perSeed := make(map[int]int)
+	eligible := func(c *candidate) bool {
+		if opts.MaxPerSeed > 0 && perSeed[c.result.SourceSong.ID] >= opts.MaxPerSeed {
+			return false
+		}
+		if opts.MaxPerArtist > 0 {
+			for _, artistID := range c.artists {
+				if perArtist[artistID] >= opts.MaxPerArtist {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	picked := make([]models.SearchResult, 0, len(candidates))
+	for len(candidates) > 0 && (limit <= 0 || len(picked) < limit) {
+		// Caps only tighten, so a candidate that fails them once is gone for good
+		remaining := candidates[:0]
+		for _, c := range candidates {
+			if eligible(c) {
+				remaining = append(remaining, c)
+			}
+		}
+		candidates = remaining
+		if len(candidates) == 0 {
+			break
+		}
+
+		best, bestValue := 0, 0.0
+		for i, c := range candidates {
+			value := lambda*c.result.Score - (1-lambda)*c.similarity
+			if i == 0 || value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+
+		chosen := candidates[best]
+		candidates = append(candidates[:best], candidates[best+1:]...)
+		picked = append(picked, chosen.result)
+		perSeed[chosen.result.SourceSong.ID]++
+		for _, artistID := range chosen.artists {
+			perArtist[artistID]++
+		}
+
+		for _, c := range candidates {
+			c.similarity = max(c.similarity, resultSimilarity(c.result, c.artists, chosen.result, chosen.artists))
+		}
+	}
+
+	return picked
+}
+
+// resultSimilarity is 1 for tracks sharing a main artist, 0.5 for tracks
+// reached from the same seed and 0 otherwise
+func resultSimilarity(a models.SearchResult, aArtists []int, b models.SearchResult, bArtists []int) float64 {
+	for _, artistID := range aArtists {
+		for _, otherID := range bArtists {
+			if artistID == otherID {
+				return 1
+			}
+		}
+	}
+	if a.SourceSong.ID == b.SourceSong.ID {
+		return 0.5
+	}
+	return 0
+}
+
+// mainArtistIDs returns a song's main artists, or its first artist when none is
+// marked as main
+func mainArtistIDs(song models.SongNode) []int {
+	var ids []int
+	for _, artist := range song.Artists {
+		if artist.IsMain {
+			ids = append(ids, artist.ID)
+		}
+	}
+	if len(ids) == 0 && len(song.Artists) > 0 {
+		ids = append(ids, song.Artists[0].ID)
+	}
+	return ids
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func diversityResult(sourceID, matchedID, artistID int, score float64) models.SearchResult {
+	return models.SearchResult{
+		SourceSong: models.SongNode{ID: sourceID},
+		MatchedSong: models.SongNode{
+			ID:      matchedID,
+			Artists: []models.Artist{{ID: artistID, IsMain: true}},
+		},
+		Score: score,
+	}
+}
+
+func matchedIDs(results []models.SearchResult) []int {
+	ids := make([]int, len(results))
+	for i, result := range results {
+		ids[i] = result.MatchedSong.ID
+	}
+	return ids
+}
+
+func TestDiversifyResults(t *testing.T) {
+	t.Run("Max_Per_Artist", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			diversityResult(1, 10, 100, 0.9),
+			diversityResult(1, 11, 100, 0.8),
+			diversityResult(2, 12, 100, 0.7),
+			diversityResult(2, 13, 200, 0.6),
+		}
+
+		// Act
+		picked := diversifyResults(results, models.DiversityOptions{MaxPerArtist: 2, Lambda: 1}, 0)
+
+		// Assert
+		assert.Equal(t, []int{10, 11, 13}, matchedIDs(picked), "Should drop the third track by the same artist")
+	})
+
+	t.Run("Max_Per_Seed", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			diversityResult(1, 10, 100, 0.9),
+			diversityResult(1, 11, 101, 0.8),
+			diversityResult(2, 12, 102, 0.7),
+		}
+
+		// Act
+		picked := diversifyResults(results, models.DiversityOptions{MaxPerSeed: 1, Lambda: 1}, 0)
+
+		// Assert
+		assert.Equal(t, []int{10, 12}, matchedIDs(picked), "Should keep one track per seed")
+	})
+
+	t.Run("Re_Ranks_For_Variety", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			diversityResult(1, 10, 100, 0.9),
+			diversityResult(1, 11, 100, 0.85),
+			diversityResult(2, 12, 200, 0.6),
+		}
+
+		// Act
+		picked := diversifyResults(results, models.DiversityOptions{Lambda: 0.7}, 0)
+
+		// Assert
+		assert.Equal(t, []int{10, 12, 11}, matchedIDs(picked), "Another artist should come before a second track by the first")
+	})
+
+	t.Run("Lambda_One_Keeps_Score_Order", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			diversityResult(1, 10, 100, 0.9),
+			diversityResult(1, 11, 100, 0.85),
+			diversityResult(2, 12, 200, 0.6),
+		}
+
+		// Act
+		picked := diversifyResults(results, models.DiversityOptions{Lambda: 1}, 0)
+
+		// Assert
+		assert.Equal(t, []int{10, 11, 12}, matchedIDs(picked))
+	})
+
+	t.Run("Duplicates_And_Limit", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			diversityResult(1, 10, 100, 0.9),
+			diversityResult(2, 10, 100, 0.9),
+			diversityResult(1, 11, 101, 0.8),
+			diversityResult(1, 12, 102, 0.7),
+		}
+
+		// Act
+		picked := diversifyResults(results, models.DiversityOptions{Lambda: 1}, 2)
+
+		// Assert
+		assert.Equal(t, []int{10, 11}, matchedIDs(picked), "Should keep each song once and stop at the limit")
+		assert.Equal(t, 1, picked[0].SourceSong.ID, "Should keep the first, best ranked copy")
+	})
+
+	t.Run("No_Results", func(t *testing.T) {
+		// Act
+		picked := diversifyResults(nil, models.DiversityOptions{MaxPerArtist: 2, Lambda: 0.7}, 10)
+
+		// Assert
+		assert.Empty(t, picked)
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRadioCount = 10
+	maxRadioCount     = 50
+)
+
+// RadioResponse is the next batch of a sample radio session
+type RadioResponse struct {
+	SessionID string           `json:"sessionId"`
+	Tracks    []RadioTrackInfo `json:"tracks"`
+}
+
+type RadioTrackInfo struct {
+	Song SongNode `json:"song"`
+	From string   `json:"from"` // Song ID the walk stepped from
+	// Direction is "ancestors" when From samples Song, "descendants" when Song
+	// samples From
+	Direction models.TraversalDirection `json:"direction"`
+	// Restarted says the walk jumped back to a seed song before this hop
+	Restarted bool `json:"restarted,omitempty"`
+}
+
+// GetRadio plays the next tracks of a sample radio session. Without a session a
+// new one starts from the seed song IDs:
+// GET /radio?seed=&seed=&genre=&count= or GET /radio?session=&count=
+func GetRadio(radioService services.RadioServiceInterface, budget models.SearchBudget) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetString("userID")
+
+		count := defaultRadioCount
+		if countParam := ctx.Query("count"); countParam != "" {
+			parsed, err := strconv.Atoi(countParam)
+			if err != nil || parsed <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "count must be a positive integer"})
+				return
+			}
+			count = min(parsed, maxRadioCount)
+		}
+
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		sessionID := ctx.Query("session")
+		if sessionID == "" {
+			var seedIDs []int
+			for _, seedParam := range ctx.QueryArray("seed") {
+				for _, value := range strings.Split(seedParam, ",") {
+					seedID, err := strconv.Atoi(strings.TrimSpace(value))
+					if err != nil || seedID <= 0 {
+						ctx.JSON(http.StatusBadRequest, gin.H{"error": "seed must be a song id"})
+						return
+					}
+					seedIDs = append(seedIDs, seedID)
+				}
+			}
+			if len(seedIDs) == 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "seed or session is required"})
+				return
+			}
+			if budget.MaxSeeds > 0 && len(seedIDs) > budget.MaxSeeds {
+				ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "too many seed songs"})
+				return
+			}
+
+			var err error
+			sessionID, err = radioService.Start(searchCtx, userID, seedIDs, ctx.Query("genre"))
+			if errors.Is(err, services.ErrNoRadioSeeds) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				if searchAborted(ctx, searchCtx) {
+					return
+				}
+				zap.L().Error("Failed to start radio session",
+					zap.String("userID", userID),
+					zap.Ints("seeds", seedIDs),
+					zap.Error(err))
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start radio"})
+				return
+			}
+		}
+
+		tracks, err := radioService.Next(searchCtx, userID, sessionID, count)
+		if errors.Is(err, services.ErrRadioSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to play radio",
+				zap.String("userID", userID),
+				zap.String("sessionID", sessionID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to play radio"})
+			return
+		}
+
+		response := RadioResponse{
+			SessionID: sessionID,
+			Tracks:    make([]RadioTrackInfo, len(tracks)),
+		}
+		for i, track := range tracks {
+			response.Tracks[i] = RadioTrackInfo{
+				Song:      toSongNode(track.Song),
+				From:      songKey(track.FromSongID),
+				Direction: track.Direction,
+				Restarted: track.Restarted,
+			}
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
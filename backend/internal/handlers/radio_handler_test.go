@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRadioHandlerTest(radioService services.RadioServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	// Add a mock context middleware to simulate authenticated user
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "test-user-id")
+		c.Next()
+	})
+
+	r.GET("/radio", GetRadio(radioService, testSearchBudget))
+	return r
+}
+
+func TestGetRadio(t *testing.T) {
+	tracks := []models.RadioTrack{
+		{Song: models.SongNode{ID: 2, Title: "Soul Song"}, FromSongID: 1, Direction: models.DirectionAncestors, Restarted: true},
+		{Song: models.SongNode{ID: 5, Title: "Later Song"}, FromSongID: 2, Direction: models.DirectionDescendants},
+	}
+
+	t.Run("Starts_Session", func(t *testing.T) {
+		// Arrange
+		mockRadioService := new(MockRadioService)
+		r := setupRadioHandlerTest(mockRadioService)
+
+		mockRadioService.On("Start", "test-user-id", []int{1, 7, 9}, "soul").Return("session-1", nil)
+		mockRadioService.On("Next", "test-user-id", "session-1", 2).Return(tracks, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/radio?seed=1&seed=7,9&genre=soul&count=2", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response RadioResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, "session-1", response.SessionID)
+		require.Len(t, response.Tracks, 2)
+		assert.Equal(t, "Soul Song", response.Tracks[0].Song.Title)
+		assert.Equal(t, "1", response.Tracks[0].From)
+		assert.True(t, response.Tracks[0].Restarted)
+		assert.Equal(t, models.DirectionDescendants, response.Tracks[1].Direction)
+		mockRadioService.AssertExpectations(t)
+	})
+
+	t.Run("Continues_Session", func(t *testing.T) {
+		// Arrange
+		mockRadioService := new(MockRadioService)
+		r := setupRadioHandlerTest(mockRadioService)
+
+		mockRadioService.On("Next", "test-user-id", "session-1", maxRadioCount).Return(tracks, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/radio?session=session-1&count=500", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		mockRadioService.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+		mockRadioService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Parameters", func(t *testing.T) {
+		for _, target := range []string{
+			"/radio",
+			"/radio?seed=abc",
+			"/radio?seed=1&count=0",
+		} {
+			// Arrange
+			mockRadioService := new(MockRadioService)
+			r := setupRadioHandlerTest(mockRadioService)
+
+			// Act
+			req := httptest.NewRequest("GET", target, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code, "Should reject %s", target)
+			mockRadioService.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Too_Many_Seeds", func(t *testing.T) {
+		// Arrange
+		mockRadioService := new(MockRadioService)
+		r := setupRadioHandlerTest(mockRadioService)
+
+		// Act
+		req := httptest.NewRequest("GET", "/radio?seed=1,2,3,4,5,6", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should reject more seeds than the budget allows")
+		mockRadioService.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Seeds_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockRadioService := new(MockRadioService)
+		r := setupRadioHandlerTest(mockRadioService)
+
+		mockRadioService.On("Start", "test-user-id", []int{999}, "").Return("", services.ErrNoRadioSeeds)
+
+		// Act
+		req := httptest.NewRequest("GET", "/radio?seed=999", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+		mockRadioService.AssertExpectations(t)
+	})
+
+	t.Run("Session_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockRadioService := new(MockRadioService)
+		r := setupRadioHandlerTest(mockRadioService)
+
+		mockRadioService.On("Next", "test-user-id", "expired", defaultRadioCount).Return(nil, services.ErrRadioSessionNotFound)
+
+		// Act
+		req := httptest.NewRequest("GET", "/radio?session=expired", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+		mockRadioService.AssertExpectations(t)
+	})
+
+	t.Run("Service_Error", func(t *testing.T) {
+		// Arrange
+		mockRadioService := new(MockRadioService)
+		r := setupRadioHandlerTest(mockRadioService)
+
+		mockRadioService.On("Next", "test-user-id", "session-1", defaultRadioCount).Return(nil, assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/radio?session=session-1", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRadioService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/zmb3/spotify"
+	"go.uber.org/zap"
+)
+
+// sampleDNATimeRanges are the Spotify top track time ranges a sample DNA
+// report is built from
+var sampleDNATimeRanges = []string{"short", "medium", "long"}
+
+const (
+	// sampleDNATracksPerRange is how many top tracks are read per time range
+	sampleDNATracksPerRange = 20
+	// sampleDNADepth is how many sample hops back ancestors are looked for
+	sampleDNADepth = 2
+)
+
+// sampleDNAOptions sizes the sample DNA report
+var sampleDNAOptions = models.SampleDNAOptions{
+	Depth:    sampleDNADepth,
+	Entries:  10,
+	Examples: 3,
+	MaxNodes: 200,
+}
+
+// SampleDNAResponse is what a user's top tracks sample, directly or through
+// other samples
+type SampleDNAResponse struct {
+	Tracks    int              `json:"tracks"`
+	Matched   int              `json:"matched"`
+	Ancestors []SampleDNASong  `json:"ancestors"`
+	Artists   []SampleDNAEntry `json:"artists"`
+	Genres    []SampleDNAEntry `json:"genres"`
+	Eras      []SampleDNAEntry `json:"eras"`
+}
+
+type SampleDNASong struct {
+	Song     SongNode        `json:"song"`
+	Tracks   int             `json:"tracks"` // Top tracks sampling it
+	Examples []SampleDNAPath `json:"examples"`
+}
+
+type SampleDNAEntry struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Tracks   int             `json:"tracks"` // Top tracks sampling it
+	Examples []SampleDNAPath `json:"examples"`
+}
+
+// SampleDNAPath is a sample chain from a top track (first) back to an ancestor
+type SampleDNAPath struct {
+	Songs      []SongNode `json:"songs"`
+	TimeRanges []string   `json:"timeRanges"`
+}
+
+// GetUserSampleDNA maps the user's short, medium and long term top tracks into
+// the samples DB and reports the songs, artists, genres and eras they sample
+func GetUserSampleDNA(songRepo repository.SongRepositoryInterface, clientManager services.ClientManagerInterface, sampleDNAService services.SampleDNAServiceInterface, budget models.SearchBudget) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, exists := ctx.Get("userID")
+		if !exists {
+			zap.L().Warn("Unauthorized attempt to get user's sample DNA")
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		client, exists := clientManager.GetClient(userID.(string))
+		if !exists {
+			zap.L().Warn("No Spotify client found for user",
+				zap.String("userID", userID.(string)))
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		var ranked [][]models.TopTrack
+		for _, timeRange := range sampleDNATimeRanges {
+			limit := sampleDNATracksPerRange
+			page, err := client.CurrentUsersTopTracksOpt(&spotify.Options{Limit: &limit, Timerange: &timeRange})
+			if err != nil {
+				zap.L().Error("Failed to fetch top tracks from Spotify",
+					zap.String("userID", userID.(string)),
+					zap.String("timeRange", timeRange),
+					zap.Error(err))
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user's top tracks"})
+				return
+			}
+
+			tracks := make([]models.TopTrack, 0, len(page.Tracks))
+			for _, track := range page.Tracks {
+				if len(track.Artists) == 0 {
+					continue
+				}
+				tracks = append(tracks, models.TopTrack{
+					Title:      track.Name,
+					Artist:     track.Artists[0].Name,
+					TimeRanges: []string{timeRange},
+				})
+			}
+			ranked = append(ranked, tracks)
+		}
+
+		tracks := mergeTopTracks(ranked, budget.MaxSeeds)
+		if len(tracks) == 0 {
+			zap.L().Warn("No songs found for user",
+				zap.String("userID", userID.(string)))
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no songs found for user"})
+			return
+		}
+
+		searchOpts := models.SearchOptions{MaxDepth: sampleDNADepth, Direction: models.DirectionAncestors}
+		if !checkSearchBudget(ctx, budget, len(tracks), searchOpts) {
+			return
+		}
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		queries := make([]models.SongQuery, len(tracks))
+		for i, track := range tracks {
+			queries[i] = models.SongQuery{Title: track.Title, Artist: track.Artist}
+		}
+		// Spotify titles carry remaster/feat. suffixes the samples DB does not
+		_, seedReports, err := resolveSeeds(searchCtx, songRepo, queries)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to match top tracks",
+				zap.String("userID", userID.(string)),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sample DNA"})
+			return
+		}
+		for i := range tracks {
+			tracks[i].SongIDs = seedReports[i].SongIDs
+		}
+
+		dna, err := sampleDNAService.Report(searchCtx, tracks, sampleDNAOptions)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to build sample DNA",
+				zap.String("userID", userID.(string)),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sample DNA"})
+			return
+		}
+
+		zap.L().Info("Successfully built user's sample DNA",
+			zap.String("userID", userID.(string)),
+			zap.Int("tracks", dna.Tracks),
+			zap.Int("matched", dna.Matched))
+
+		ctx.JSON(http.StatusOK, newSampleDNAResponse(dna))
+	}
+}
+
+// mergeTopTracks interleaves the top tracks of every time range by rank, so
+// capping them at maxTracks keeps the best of each range. Tracks in several
+// ranges are merged. A maxTracks of 0 keeps every track.
+func mergeTopTracks(ranked [][]models.TopTrack, maxTracks int) []models.TopTrack {
+	var merged []models.TopTrack
+	index := make(map[string]int)
+	for rank := 0; ; rank++ {
+		more := false
+		for _, tracks := range ranked {
+			if rank >= len(tracks) {
+				continue
+			}
+			more = true
+
+			track := tracks[rank]
+			key := strings.ToLower(track.Title) + "\x00" + strings.ToLower(track.Artist)
+			if i, exists := index[key]; exists {
+				merged[i].TimeRanges = append(merged[i].TimeRanges, track.TimeRanges...)
+				continue
+			}
+			if maxTracks > 0 && len(merged) >= maxTracks {
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, track)
+		}
+		if !more {
+			return merged
+		}
+	}
+}
+
+func newSampleDNAResponse(dna *models.SampleDNA) SampleDNAResponse {
+	response := SampleDNAResponse{
+		Tracks:    dna.Tracks,
+		Matched:   dna.Matched,
+		Ancestors: make([]SampleDNASong, 0, len(dna.Ancestors)),
+		Artists:   make([]SampleDNAEntry, 0, len(dna.Artists)),
+		Genres:    make([]SampleDNAEntry, 0, len(dna.Genres)),
+		Eras:      make([]SampleDNAEntry, 0, len(dna.Eras)),
+	}
+	for _, ancestor := range dna.Ancestors {
+		response.Ancestors = append(response.Ancestors, SampleDNASong{
+			Song:     toSongNode(ancestor.Song),
+			Tracks:   ancestor.Tracks,
+			Examples: toSampleDNAPaths(ancestor.Examples),
+		})
+	}
+	for _, artist := range dna.Artists {
+		response.Artists = append(response.Artists, SampleDNAEntry{
+			ID:       strconv.Itoa(artist.Artist.ID),
+			Name:     artist.Artist.Name,
+			Tracks:   artist.Tracks,
+			Examples: toSampleDNAPaths(artist.Examples),
+		})
+	}
+	for _, genre := range dna.Genres {
+		response.Genres = append(response.Genres, SampleDNAEntry{
+			Name:     genre.Genre,
+			Tracks:   genre.Tracks,
+			Examples: toSampleDNAPaths(genre.Examples),
+		})
+	}
+	for _, era := range dna.Eras {
+		response.Eras = append(response.Eras, SampleDNAEntry{
+			Name:     strconv.Itoa(era.Decade) + "s",
+			Tracks:   era.Tracks,
+			Examples: toSampleDNAPaths(era.Examples),
+		})
+	}
+	return response
+}
+
+func toSampleDNAPaths(paths []models.SampleDNAPath) []SampleDNAPath {
+	converted := make([]SampleDNAPath, len(paths))
+	for i, path := range paths {
+		converted[i] = SampleDNAPath{Songs: make([]SongNode, len(path.Songs)), TimeRanges: path.TimeRanges}
+		for j, song := range path.Songs {
+			converted[i].Songs[j] = toSongNode(song)
+		}
+	}
+	return converted
+}
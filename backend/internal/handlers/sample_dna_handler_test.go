@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zmb3/spotify"
+)
+
+func setupSampleDNATest(songRepo *MockSongRepository, clientManager *MockClientManager, sampleDNAService *MockSampleDNAService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	// Add a mock context middleware to simulate authenticated user
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "test-user-id")
+		c.Next()
+	})
+
+	r.GET("/user/sample-dna", GetUserSampleDNA(songRepo, clientManager, sampleDNAService, testSearchBudget))
+	return r
+}
+
+// topTracksPage builds a Spotify top tracks page from title and artist pairs
+func topTracksPage(tracks ...[2]string) *spotify.FullTrackPage {
+	page := &spotify.FullTrackPage{}
+	for _, track := range tracks {
+		page.Tracks = append(page.Tracks, spotify.FullTrack{
+			SimpleTrack: spotify.SimpleTrack{
+				Name:    track[0],
+				Artists: []spotify.SimpleArtist{{Name: track[1]}},
+			},
+		})
+	}
+	return page
+}
+
+// inTimeRange matches the Spotify options asking for a time range
+func inTimeRange(timeRange string) interface{} {
+	return mock.MatchedBy(func(opts *spotify.Options) bool {
+		return opts.Timerange != nil && *opts.Timerange == timeRange
+	})
+}
+
+func TestGetUserSampleDNA(t *testing.T) {
+	first := [2]string{"First Song", "First Artist"}
+	second := [2]string{"Second Song", "Second Artist"}
+	third := [2]string{"Third Song", "Third Artist"}
+
+	t.Run("Builds_Report", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockClient := new(MockSpotifyClient)
+		mockSampleDNAService := new(MockSampleDNAService)
+		r := setupSampleDNATest(mockRepo, mockClientManager, mockSampleDNAService)
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", inTimeRange("short")).Return(topTracksPage(first, second), nil)
+		mockClient.On("CurrentUsersTopTracksOpt", inTimeRange("medium")).Return(topTracksPage(second, third), nil)
+		mockClient.On("CurrentUsersTopTracksOpt", inTimeRange("long")).Return(topTracksPage(first), nil)
+
+		mockRepo.On("MatchSongs", models.SongQuery{Title: "First Song", Artist: "First Artist"}).
+			Return([]models.SongMatch{{SongID: 1, Confidence: 1}}, nil)
+		mockRepo.On("MatchSongs", models.SongQuery{Title: "Second Song", Artist: "Second Artist"}).Return(nil, nil)
+		mockRepo.On("MatchSongs", models.SongQuery{Title: "Third Song", Artist: "Third Artist"}).
+			Return([]models.SongMatch{{SongID: 7, Confidence: 1}}, nil)
+
+		soulSong := models.SongNode{ID: 3, Title: "Soul Song", Genres: []string{"soul"}, ReleaseYear: 1972}
+		example := models.SampleDNAPath{
+			Songs:      []models.SongNode{{ID: 1, Title: "First Song"}, soulSong},
+			TimeRanges: []string{"short", "long"},
+		}
+		entry := models.SampleDNAEntry{Tracks: 2, Examples: []models.SampleDNAPath{example}}
+		mockSampleDNAService.On("Report", []models.TopTrack{
+			{Title: "First Song", Artist: "First Artist", TimeRanges: []string{"short", "long"}, SongIDs: []int{1}},
+			{Title: "Second Song", Artist: "Second Artist", TimeRanges: []string{"medium", "short"}, SongIDs: []int{}},
+			{Title: "Third Song", Artist: "Third Artist", TimeRanges: []string{"medium"}, SongIDs: []int{7}},
+		}, sampleDNAOptions).Return(&models.SampleDNA{
+			Tracks:    3,
+			Matched:   2,
+			Ancestors: []models.SampleDNASong{{Song: soulSong, SampleDNAEntry: entry}},
+			Artists:   []models.SampleDNAArtist{{Artist: models.Artist{ID: 201, Name: "Soul Artist"}, SampleDNAEntry: entry}},
+			Genres:    []models.SampleDNAGenre{{Genre: "soul", SampleDNAEntry: entry}},
+			Eras:      []models.SampleDNAEra{{Decade: 1970, SampleDNAEntry: entry}},
+		}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/user/sample-dna", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response SampleDNAResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, 3, response.Tracks)
+		assert.Equal(t, 2, response.Matched)
+		require.Len(t, response.Ancestors, 1)
+		assert.Equal(t, "Soul Song", response.Ancestors[0].Song.Title)
+		require.Len(t, response.Ancestors[0].Examples, 1)
+		assert.Len(t, response.Ancestors[0].Examples[0].Songs, 2)
+		assert.Equal(t, []string{"short", "long"}, response.Ancestors[0].Examples[0].TimeRanges)
+		require.Len(t, response.Artists, 1)
+		assert.Equal(t, "201", response.Artists[0].ID)
+		assert.Equal(t, "Soul Artist", response.Artists[0].Name)
+		assert.Equal(t, 2, response.Artists[0].Tracks)
+		assert.Equal(t, "soul", response.Genres[0].Name)
+		assert.Equal(t, "1970s", response.Eras[0].Name)
+		mockSampleDNAService.AssertExpectations(t)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("No_Spotify_Client", func(t *testing.T) {
+		// Arrange
+		mockClientManager := new(MockClientManager)
+		mockSampleDNAService := new(MockSampleDNAService)
+		r := setupSampleDNATest(new(MockSongRepository), mockClientManager, mockSampleDNAService)
+
+		mockClientManager.On("GetClient", "test-user-id").Return(nil, false)
+
+		// Act
+		req := httptest.NewRequest("GET", "/user/sample-dna", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "Should return Unauthorized status")
+		mockSampleDNAService.AssertNotCalled(t, "Report", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Spotify_Error", func(t *testing.T) {
+		// Arrange
+		mockClientManager := new(MockClientManager)
+		mockClient := new(MockSpotifyClient)
+		mockSampleDNAService := new(MockSampleDNAService)
+		r := setupSampleDNATest(new(MockSongRepository), mockClientManager, mockSampleDNAService)
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", mock.Anything).Return((*spotify.FullTrackPage)(nil), assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/user/sample-dna", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockSampleDNAService.AssertNotCalled(t, "Report", mock.Anything, mock.Anything)
+	})
+
+	t.Run("No_Top_Tracks", func(t *testing.T) {
+		// Arrange
+		mockClientManager := new(MockClientManager)
+		mockClient := new(MockSpotifyClient)
+		r := setupSampleDNATest(new(MockSongRepository), mockClientManager, new(MockSampleDNAService))
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", mock.Anything).Return(topTracksPage(), nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/user/sample-dna", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+	})
+}
+
+func TestMergeTopTracks(t *testing.T) {
+	// Arrange
+	ranked := [][]models.TopTrack{
+		{{Title: "A", Artist: "X", TimeRanges: []string{"short"}}, {Title: "B", Artist: "X", TimeRanges: []string{"short"}}},
+		{{Title: "C", Artist: "X", TimeRanges: []string{"medium"}}, {Title: "a", Artist: "x", TimeRanges: []string{"medium"}}},
+		{{Title: "D", Artist: "X", TimeRanges: []string{"long"}}},
+	}
+
+	// Act
+	merged := mergeTopTracks(ranked, 3)
+
+	// Assert
+	require.Len(t, merged, 3, "Should keep the best ranked track of every range")
+	assert.Equal(t, []string{"A", "C", "D"}, []string{merged[0].Title, merged[1].Title, merged[2].Title})
+	assert.Equal(t, []string{"short", "medium"}, merged[0].TimeRanges, "Tracks in several ranges should be merged")
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// checkSearchBudget answers 422 and returns false when a search from seeds
+// seed songs with opts is over budget
+func checkSearchBudget(ctx *gin.Context, budget models.SearchBudget, seeds int, opts models.SearchOptions) bool {
+	if err := budget.Check(seeds, opts); err != nil {
+		zap.L().Warn("Rejected search over budget",
+			zap.Int("seeds", seeds),
+			zap.Int("maxDepth", opts.MaxDepth),
+			zap.Float64("estimatedCost", models.EstimateSearchCost(seeds, opts)),
+			zap.Error(err))
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// searchAborted answers a search that failed because searchCtx ended and
+// returns true. Searches running past the budget's timeout get a 504; clients
+// that went away get nothing.
+func searchAborted(ctx *gin.Context, searchCtx context.Context) bool {
+	switch {
+	case errors.Is(searchCtx.Err(), context.DeadlineExceeded):
+		zap.L().Warn("Search timed out")
+		ctx.JSON(http.StatusGatewayTimeout, gin.H{"error": "search timed out, try a smaller search"})
+		return true
+	case errors.Is(searchCtx.Err(), context.Canceled):
+		zap.L().Info("Search cancelled by client")
+		ctx.Abort()
+		return true
+	}
+	return false
+}
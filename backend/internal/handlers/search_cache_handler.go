@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SearchCacheStatsResponse reports how the genre search cache is used
+type SearchCacheStatsResponse struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hitRate"`
+	Evictions     int64   `json:"evictions"`
+	Expirations   int64   `json:"expirations"`
+	Invalidations int64   `json:"invalidations"`
+	Entries       int     `json:"entries"`
+	MaxEntries    int     `json:"maxEntries"`
+}
+
+// GetSearchCacheStats returns the genre search cache counters. searchCache is
+// nil when the cache is disabled.
+func GetSearchCacheStats(searchCache repository.SearchCacheInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if searchCache == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "search cache is disabled"})
+			return
+		}
+
+		stats := searchCache.Stats()
+		response := SearchCacheStatsResponse{
+			Hits:          stats.Hits,
+			Misses:        stats.Misses,
+			Evictions:     stats.Evictions,
+			Expirations:   stats.Expirations,
+			Invalidations: stats.Invalidations,
+			Entries:       stats.Entries,
+			MaxEntries:    stats.MaxEntries,
+		}
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			response.HitRate = float64(stats.Hits) / float64(lookups)
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// ClearSearchCache drops every cached genre search
+func ClearSearchCache(searchCache repository.SearchCacheInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if searchCache == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "search cache is disabled"})
+			return
+		}
+
+		searchCache.Invalidate()
+		zap.L().Info("Cleared search cache",
+			zap.String("userID", ctx.GetString("userID")))
+		ctx.JSON(http.StatusOK, gin.H{"message": "Search cache cleared"})
+	}
+}
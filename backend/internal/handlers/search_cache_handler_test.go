@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSearchCacheHandlerTest(searchCache repository.SearchCacheInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/search-cache", GetSearchCacheStats(searchCache))
+	r.DELETE("/search-cache", ClearSearchCache(searchCache))
+	return r
+}
+
+func TestGetSearchCacheStats(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		mockSearchCache := new(MockSearchCache)
+		r := setupSearchCacheHandlerTest(mockSearchCache)
+
+		mockSearchCache.On("Stats").Return(models.SearchCacheStats{
+			Hits: 3, Misses: 1, Evictions: 2, Entries: 4, MaxEntries: 10,
+		})
+
+		// Act
+		req := httptest.NewRequest("GET", "/search-cache", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response SearchCacheStatsResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, SearchCacheStatsResponse{
+			Hits: 3, Misses: 1, HitRate: 0.75, Evictions: 2, Entries: 4, MaxEntries: 10,
+		}, response)
+		mockSearchCache.AssertExpectations(t)
+	})
+
+	t.Run("Cache_Disabled", func(t *testing.T) {
+		// Arrange
+		r := setupSearchCacheHandlerTest(nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/search-cache", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return not found when the cache is disabled")
+	})
+}
+
+func TestClearSearchCache(t *testing.T) {
+	// Arrange
+	mockSearchCache := new(MockSearchCache)
+	r := setupSearchCacheHandlerTest(mockSearchCache)
+
+	mockSearchCache.On("Invalidate").Return()
+
+	// Act
+	req := httptest.NewRequest("DELETE", "/search-cache", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+	mockSearchCache.AssertExpectations(t)
+}
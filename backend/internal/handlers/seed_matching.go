@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+)
+
+// minSeedMatchConfidence is the lowest match confidence a candidate needs to
+// seed a search. It admits normalized title/artist matches but not bare title
+// prefixes.
+const minSeedMatchConfidence = 0.75
+
+// maxSeedSongs caps how many songs one seed resolves to, whether the client
+// pinned them or they matched
+const maxSeedSongs = 5
+
+// SeedMatchStatus says how a seed track was found in the samples DB
+type SeedMatchStatus string
+
+const (
+	// SeedMatched means the title and artist matched exactly, or the client
+	// pinned the seed to song IDs that exist
+	SeedMatched SeedMatchStatus = "matched"
+	// SeedFuzzyMatched means the seed only matched after normalization
+	SeedFuzzyMatched SeedMatchStatus = "fuzzy"
+	// SeedNotFound means the seed matched nothing and was left out of the search
+	SeedNotFound SeedMatchStatus = "not_found"
+)
+
+// SeedReport tells the client what became of one seed track, or of one seed
+// artist, which has no title
+type SeedReport struct {
+	Title   string          `json:"title"`
+	Artist  string          `json:"artist"`
+	Status  SeedMatchStatus `json:"status"`
+	SongIDs []int           `json:"songIds"`
+	// Results is how many search results started from this seed
+	Results int `json:"results"`
+}
+
+// resolveSeeds matches every seed against the samples DB and pins it to the
+// matched song IDs, so searches start from songs whose titles carry
+// remaster/feat. suffixes or whose artists are spelled differently. Seeds that
+// already carry SongIDs keep those of their first maxSeedSongs IDs found in
+// the samples DB, and matched seeds keep their best maxSeedSongs matches.
+// Seeds matching nothing are dropped from the returned queries. The reports
+// line up with seeds.
+func resolveSeeds(ctx context.Context, songRepo repository.SongRepositoryInterface, seeds []models.SongQuery) ([]models.SongQuery, []SeedReport, error) {
+	resolved := make([]models.SongQuery, 0, len(seeds))
+	reports := make([]SeedReport, 0, len(seeds))
+	for _, seed := range seeds {
+		report := SeedReport{
+			Title:   seed.Title,
+			Artist:  seed.Artist,
+			Status:  SeedNotFound,
+			SongIDs: []int{},
+		}
+
+		if len(seed.SongIDs) > 0 {
+			songIDs, err := existingSongIDs(ctx, songRepo, seed.SongIDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(songIDs) > 0 {
+				report.Status = SeedMatched
+				report.SongIDs = songIDs
+
+				seed.SongIDs = songIDs
+				resolved = append(resolved, seed)
+			}
+			reports = append(reports, report)
+			continue
+		}
+		if seed.Title == "" || seed.Artist == "" {
+			reports = append(reports, report)
+			continue
+		}
+
+		matches, err := songRepo.MatchSongs(ctx, seed)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var songIDs []int
+		exact := false
+		for _, match := range matches {
+			if len(songIDs) == maxSeedSongs {
+				break
+			}
+			if match.Confidence >= minSeedMatchConfidence {
+				songIDs = append(songIDs, match.SongID)
+				exact = exact || match.Confidence >= 1
+			}
+		}
+
+		if len(songIDs) > 0 {
+			report.Status = SeedFuzzyMatched
+			if exact {
+				report.Status = SeedMatched
+			}
+			report.SongIDs = songIDs
+
+			seed.SongIDs = songIDs
+			resolved = append(resolved, seed)
+		}
+		reports = append(reports, report)
+	}
+	return resolved, reports, nil
+}
+
+// existingSongIDs returns those of the first maxSeedSongs distinct ids that
+// are songs in the samples DB, in order
+func existingSongIDs(ctx context.Context, songRepo repository.SongRepositoryInterface, ids []int) ([]int, error) {
+	var candidates []int
+	for _, id := range ids {
+		if len(candidates) == maxSeedSongs {
+			break
+		}
+		if id > 0 && !containsID(candidates, id) {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	songs, err := songRepo.GetSongsWithDetails(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	var existing []int
+	for _, id := range candidates {
+		if _, ok := songs[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	return existing, nil
+}
+
+// resolveArtistSeeds expands every artist into at most songsPerArtist of their
+// songs in the samples DB, the most sampled and sampling first. Artists with no
+// songs are dropped from the returned queries. The reports line up with artists.
+func resolveArtistSeeds(ctx context.Context, songRepo repository.SongRepositoryInterface, artists []string, songsPerArtist int) ([]models.SongQuery, []SeedReport, error) {
+	resolved := make([]models.SongQuery, 0, len(artists))
+	reports := make([]SeedReport, 0, len(artists))
+	for _, artist := range artists {
+		report := SeedReport{
+			Artist:  artist,
+			Status:  SeedNotFound,
+			SongIDs: []int{},
+		}
+		if artist == "" {
+			reports = append(reports, report)
+			continue
+		}
+
+		songIDs, err := songRepo.GetSongIDsByArtist(ctx, artist, songsPerArtist)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(songIDs) > 0 {
+			report.Status = SeedMatched
+			report.SongIDs = songIDs
+			resolved = append(resolved, models.SongQuery{Artist: artist, SongIDs: songIDs})
+		}
+		reports = append(reports, report)
+	}
+	return resolved, reports, nil
+}
+
+// countSeedResults credits every result to the seeds whose songs it started from
+func countSeedResults(reports []SeedReport, results []models.SearchResult) {
+	for i := range reports {
+		reports[i].Results = 0
+		for _, result := range results {
+			if containsID(reports[i].SongIDs, result.SourceSong.ID) {
+				reports[i].Results++
+			}
+		}
+	}
+}
+
+// seedSongIDs returns the set of song IDs the resolved seeds point at
+func seedSongIDs(seeds []models.SongQuery) map[int]struct{} {
+	ids := make(map[int]struct{})
+	for _, seed := range seeds {
+		for _, id := range seed.SongIDs {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+func containsID(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
@@ -14,95 +17,103 @@ import (
 	"go.uber.org/zap"
 )
 
-var genreGroups = map[string]string{
-	"hip-hop":    "Hip-Hop / Rap / R&B",
-	"rap":        "Hip-Hop / Rap / R&B",
-	"r&b":        "Hip-Hop / Rap / R&B",
-	"electronic": "Electronic / Dance",
-	"dance":      "Electronic / Dance",
-	"rock":       "Rock / Pop",
-	"pop":        "Rock / Pop",
-	"soul":       "Soul / Funk / Disco",
-	"funk":       "Soul / Funk / Disco",
-	"disco":      "Soul / Funk / Disco",
-	"jazz":       "Jazz / Blues",
-	"blues":      "Jazz / Blues",
-	"reggae":     "Reggae / Dub",
-	"dub":        "Reggae / Dub",
-	"country":    "Country / Folk",
-	"folk":       "Country / Folk",
-	"world":      "World / Latin",
-	"latin":      "World / Latin",
-	"soundtrack": "Soundtrack / Library",
-	"library":    "Soundtrack / Library",
-	"classical":  "Classical",
+type SongSearchRequest struct {
+	Songs []models.SongQuery `json:"songs"`
+	Genre string             `json:"genre"`
+	// Genres adds target genres on top of Genre
+	Genres []string `json:"genres"`
+	// GenreMatch is "any" (default) or "all" of the target genres
+	GenreMatch string `json:"genreMatch"`
+	// ExcludeGenres drops songs tagged with any of these genres
+	ExcludeGenres []string `json:"excludeGenres"`
+	MaxDepth      int      `json:"maxDepth"`
+	// Direction is one of "ancestors", "descendants" or "both" (default)
+	Direction string `json:"direction"`
+	// MaxPaths asks for alternative paths per matched song (default 1)
+	MaxPaths int `json:"maxPaths"`
+	// Limit keeps only the best scoring paths (default and cap maxGenreSearchLimit)
+	Limit int `json:"limit"`
+	// YearFrom and YearTo bound the release years of matches; Era, e.g.
+	// "1970s" or "70s", sets both
+	YearFrom int    `json:"yearFrom"`
+	YearTo   int    `json:"yearTo"`
+	Era      string `json:"era"`
+	// Chronological only follows samples whose original is not newer than the
+	// song sampling it
+	Chronological bool `json:"chronological"`
 }
 
-// Representative search genre for each group (for database queries)
-var searchGenres = map[string]string{
-	"Hip-Hop / Rap / R&B":  "hip-hop", // We can use any of hip-hop, rap, or r&b
-	"Electronic / Dance":   "electronic",
-	"Rock / Pop":           "rock",
-	"Soul / Funk / Disco":  "soul",
-	"Jazz / Blues":         "jazz",
-	"Reggae / Dub":         "reggae",
-	"Country / Folk":       "country",
-	"World / Latin":        "world",
-	"Soundtrack / Library": "soundtrack",
-	"Classical":            "classical",
-}
+// maxAlternativePaths caps SongSearchRequest.MaxPaths
+const maxAlternativePaths = 5
+
+const (
+	// maxGenreSearchLimit caps SongSearchRequest.Limit
+	maxGenreSearchLimit = 200
+	// playlistSongLimit is how many tracks go into a generated playlist
+	playlistSongLimit = 50
+	// playlistCandidateLimit is how many of the best scoring matches the
+	// diversity stage picks a playlist's tracks from
+	playlistCandidateLimit = 4 * playlistSongLimit
+)
 
-var defaultGenrePlaylists = map[string][]string{
-	"hip-hop": {
-		"https://open.spotify.com/playlist/37i9dQZF1DXbkfWVLd8wE3?si=zqZ10XC9S2a095CXo8vW6Q",
-		"https://open.spotify.com/playlist/0h9Gaqt2sNJ8M5aMV3h9BO?si=eB4jwKD0RFKXFz9WUoqXkA",
-		"https://open.spotify.com/playlist/37i9dQZF1DX04mASjTsvf0?si=r6S-aGh0Q7a2gb3nCFLemQ",
-	},
-	"electronic": {
-		"https://open.spotify.com/playlist/37i9dQZF1DWZBCPUIUs2iR?si=0REkYg79Sp6ghVwZnO0D6Q",
-		"https://open.spotify.com/playlist/44dFP8mNyCi3UcBlyaRICH?si=_bljU3wzSH6h7tfYIGQ0cw",
-	},
-	"rock": {
-		"https://open.spotify.com/playlist/37i9dQZF1DWXRqgorJj26U?si=nYX-RaKmTOqRcq73fyGEnQ",
-		"https://open.spotify.com/playlist/37i9dQZF1EIctsc1CJao2L?si=6MJxt2cpQeyYtAMYg0RKJw",
-	},
-	"soul": {
-		"https://open.spotify.com/playlist/73sIU7MIIIrSh664eygyjm?si=uKn9DEovQSqxb9P4l5u7RQ",
-		"https://open.spotify.com/playlist/37i9dQZF1DWWvhKV4FBciw?si=1lzYUcgJR-6DBpoluxX2OA",
-		"https://open.spotify.com/playlist/37i9dQZF1DX1MUPbVKMgJE?si=0nXq73dyRYGzdrrzEpd2Gw",
-	},
-	"jazz": {
-		"https://open.spotify.com/playlist/4pIwPQAiZk4JGiWRzAaxwK?si=gxXRMeGQS_SeqeTLhTIGbQ",
-		"https://open.spotify.com/playlist/0A1IHcqjyImN9uoHRsVtBn?si=f1z51sRCRx6gd7dPEfg5_g",
-	},
-	"reggae": {
-		"https://open.spotify.com/playlist/37i9dQZF1EQpjs4F0vUZ1x?si=03B58nZISEeLoLDvbZ7jbw",
-		"https://open.spotify.com/playlist/7AI62FuDUugLcg1IyVgMwU?si=GiAjkEvDT2mdloDn-zsKpQ",
-	},
-	"country": {
-		"https://open.spotify.com/playlist/0QFaFgDQQiKBob7VIZIilG?si=kjNuzW4iS86D9Udo0VSeUA",
-		"https://open.spotify.com/playlist/37i9dQZF1DWVmps5U8gHNv?si=ElAZI6eRS9OWr0EMMlh2Ow",
-	},
-	"world": {
-		"https://open.spotify.com/playlist/37i9dQZF1DXcIme26eJxid?si=F382j4bBTjSa8oS2NC3C_w",
-		"https://open.spotify.com/playlist/37i9dQZF1DX6ThddIjWuGT?si=B0r6U0sNQlS8DD1xC4g9ng",
-	},
-	"soundtrack": {
-		"https://open.spotify.com/playlist/3vDe8D64ytZRKXt0AsJT0B?si=4HFGEkdPRwSPajsaU23T2A",
-	},
-	"classical": {
-		"https://open.spotify.com/playlist/2AIyLES2xJfPa6EOxmKySl?si=qpeStpIiQO--nr__oaFQCg",
-	},
+// PathSearchRequest asks how two songs are connected through samples
+type PathSearchRequest struct {
+	From     models.SongQuery `json:"from"`
+	To       models.SongQuery `json:"to"`
+	MaxDepth int              `json:"maxDepth"`
+	// Direction is one of "ancestors", "descendants" or "both" (default)
+	Direction string `json:"direction"`
+	// MaxPaths asks for alternative chains of the same, shortest length (default 1)
+	MaxPaths int `json:"maxPaths"`
 }
 
-type SongSearchRequest struct {
-	Songs    []models.SongQuery `json:"songs"`
-	Genre    string             `json:"genre"`
-	MaxDepth int                `json:"maxDepth"`
-}
+const defaultPathSearchDepth = 6
+
+// defaultGenreSearchDepth is the depth of a genre search that sets no maxDepth
+const defaultGenreSearchDepth = 5
+
+const (
+	defaultSongSearchLimit = 10
+	maxSongSearchLimit     = 50
+)
+
+const (
+	defaultNeighborhoodRadius = 1
+	maxNeighborhoodRadius     = 3
+	// maxNeighborhoodNodes caps the songs in a song graph, the song included
+	maxNeighborhoodNodes = 500
+)
+
+const (
+	// analysisTopTracks is how many top tracks seed an analysis. When top
+	// artists seed it too, their songs take analysisTopArtists*artistSeedSongs
+	// of those seeds.
+	analysisTopTracks  = 50
+	analysisTopArtists = 5
+	// artistSeedSongs is how many of a top artist's songs seed an analysis
+	artistSeedSongs = 3
+)
 
 type TopTracksAnalysisRequest struct {
 	Genre string `json:"genre"`
+	// ExcludeGenres drops songs tagged with any of these genres
+	ExcludeGenres []string `json:"excludeGenres"`
+	// ExcludeArtists and ExcludeSongs add to the user's saved exclusions
+	ExcludeArtists []string           `json:"excludeArtists"`
+	ExcludeSongs   []models.SongQuery `json:"excludeSongs"`
+	// ExcludeKnownTracks drops the user's top tracks and the songs in up to 50
+	// of their saved playlists
+	ExcludeKnownTracks bool `json:"excludeKnownTracks"`
+	// YearFrom, YearTo and Era bound the release years of playlist tracks
+	YearFrom int    `json:"yearFrom"`
+	YearTo   int    `json:"yearTo"`
+	Era      string `json:"era"`
+	// Chronological only follows samples whose original is not newer than the
+	// song sampling it
+	Chronological bool `json:"chronological"`
+	// SeedSource is one of "tracks" (default), "artists" or "both". Top
+	// artists seed the search with their songs in the samples DB.
+	SeedSource string `json:"seedSource"`
 }
 
 type TopTrackResponseSong struct {
@@ -114,6 +125,9 @@ type TopTrackResponseSong struct {
 type TopTracksAnalysisResponse struct {
 	Songs    []TopTrackResponseSong `json:"songs"`
 	Playlist string                 `json:"playlist"`
+	// Seeds reports how each top track and top artist was matched in the
+	// samples DB
+	Seeds []SeedReport `json:"seeds"`
 }
 
 type GraphResponse struct {
@@ -123,6 +137,12 @@ type GraphResponse struct {
 	Nodes map[string]SongNode `json:"nodes"`
 	// Original path information
 	Paths []PathInfo `json:"paths"`
+	// Seeds reports how each requested song was matched, for searches
+	Seeds []SeedReport `json:"seeds,omitempty"`
+	// Center is the song a neighborhood graph was expanded from
+	Center string `json:"center,omitempty"`
+	// Truncated says the node cap left reachable songs out
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 type DeletePlaylistRequest struct {
@@ -136,17 +156,29 @@ type ArtistInfo struct {
 }
 
 type SongNode struct {
-	ID      int          `json:"id"`
-	Title   string       `json:"title"`
-	Artists []ArtistInfo `json:"artists"`
-	Genres  []string     `json:"genres"`
+	ID          int          `json:"id"`
+	Title       string       `json:"title"`
+	Artists     []ArtistInfo `json:"artists"`
+	Genres      []string     `json:"genres"`
+	ReleaseYear int          `json:"releaseYear,omitempty"`
+}
+
+// SongDetailsResponse is a song with its sample neighbourhood
+type SongDetailsResponse struct {
+	Song             SongNode   `json:"song"`
+	SamplesUsed      []SongNode `json:"samplesUsed"`
+	SampledIn        []SongNode `json:"sampledIn"`
+	SamplesUsedCount int        `json:"samplesUsedCount"`
+	SampledInCount   int        `json:"sampledInCount"`
 }
 
 type PathInfo struct {
-	Start     string   `json:"start"`     // Starting song ID
-	End       string   `json:"end"`       // Ending song ID
-	PathNodes []string `json:"pathNodes"` // List of song IDs in path
-	Distance  int      `json:"distance"`
+	Start         string                      `json:"start"`         // Starting song ID
+	End           string                      `json:"end"`           // Ending song ID
+	PathNodes     []string                    `json:"pathNodes"`     // List of song IDs in path
+	HopDirections []models.TraversalDirection `json:"hopDirections"` // Direction of each hop in the path
+	Distance      int                         `json:"distance"`
+	Score         float64                     `json:"score,omitempty"` // Ranking score between 0 and 1, for genre searches
 }
 
 func transformToArtistInfo(artists []models.Artist) []ArtistInfo {
@@ -161,31 +193,15 @@ func transformToArtistInfo(artists []models.Artist) []ArtistInfo {
 	return result
 }
 
-// Helper function to normalize genre for playlist creation and display
-func normalizeGenre(genre string) string {
-	normalizedGenre := strings.ToLower(strings.TrimSpace(genre))
-	if groupName, exists := genreGroups[normalizedGenre]; exists {
-		return groupName
-	}
-	return normalizedGenre
-}
-
-// Helper function to get the database search genre
-func getSearchGenre(groupedGenre string) string {
-	if searchGenre, exists := searchGenres[groupedGenre]; exists {
-		return searchGenre
-	}
-	return strings.ToLower(strings.TrimSpace(groupedGenre))
-}
-
-func getRandomPlaylist(genre string) string {
-	if playlists, exists := defaultGenrePlaylists[genre]; exists && len(playlists) > 0 {
-		return playlists[rand.Intn(len(playlists))]
+// getRandomPlaylist picks one of the genre group's fallback playlists
+func getRandomPlaylist(group models.GenreGroup) string {
+	if len(group.Playlists) > 0 {
+		return group.Playlists[rand.Intn(len(group.Playlists))].URL
 	}
 	return "" // Return empty string if no playlist found
 }
 
-func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientManager services.ClientManagerInterface, spotifyService services.SpotifyServiceInterface) gin.HandlerFunc {
+func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientManager services.ClientManagerInterface, spotifyService services.SpotifyServiceInterface, genreTaxonomy services.GenreTaxonomyServiceInterface, exclusionRepo repository.ExclusionRepositoryInterface, diversity models.DiversityOptions, budget models.SearchBudget) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		userID, exists := ctx.Get("userID")
 		if !exists {
@@ -219,29 +235,64 @@ func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientM
 			return
 		}
 
-		limit := 50
-		timeRange := "short"
-
-		tracks, err := client.CurrentUsersTopTracksOpt(&spotify.Options{Limit: &limit, Timerange: &timeRange})
+		years, err := models.ParseYearRange(req.YearFrom, req.YearTo, req.Era)
 		if err != nil {
-			zap.L().Error("Failed to fetch top tracks from Spotify",
-				zap.String("userID", userID.(string)),
-				zap.Error(err))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user's top tracks"})
+		seedSource, err := models.ParseSeedSource(req.SeedSource)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		timeRange := "short"
+
 		// store the tracks as SongQuery
-		songs := make([]models.SongQuery, len(tracks.Tracks))
-		for _, track := range tracks.Tracks {
-			songs = append(songs, models.SongQuery{
-				Title:  track.Name,
-				Artist: track.Artists[0].Name,
-			})
+		var songs []models.SongQuery
+		if seedSource != models.SeedSourceArtists {
+			limit := analysisTopTracks
+			if seedSource == models.SeedSourceBoth {
+				limit -= analysisTopArtists * artistSeedSongs
+			}
+			tracks, err := client.CurrentUsersTopTracksOpt(&spotify.Options{Limit: &limit, Timerange: &timeRange})
+			if err != nil {
+				zap.L().Error("Failed to fetch top tracks from Spotify",
+					zap.String("userID", userID.(string)),
+					zap.Error(err))
+
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user's top tracks"})
+				return
+			}
+
+			for _, track := range tracks.Tracks {
+				songs = append(songs, models.SongQuery{
+					Title:  track.Name,
+					Artist: track.Artists[0].Name,
+				})
+			}
+		}
+
+		var artists []string
+		if seedSource != models.SeedSourceTracks {
+			limit := analysisTopArtists
+			topArtists, err := client.CurrentUsersTopArtistsOpt(&spotify.Options{Limit: &limit, Timerange: &timeRange})
+			if err != nil {
+				zap.L().Error("Failed to fetch top artists from Spotify",
+					zap.String("userID", userID.(string)),
+					zap.Error(err))
+
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user's top artists"})
+				return
+			}
+
+			for _, artist := range topArtists.Artists {
+				artists = append(artists, artist.Name)
+			}
 		}
 
-		if len(songs) == 0 {
+		if len(songs)+len(artists) == 0 {
 			zap.L().Warn("No songs found for user",
 				zap.String("userID", userID.(string)))
 
@@ -249,20 +300,116 @@ func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientM
 			return
 		}
 
-		// Normalize genre for playlist creation and UI display
-		normalizedGenre := normalizeGenre(req.Genre)
-		// Get the single search genre for database lookup
-		searchGenre := getSearchGenre(normalizedGenre)
+		// Resolve the genre group for playlist creation and UI display
+		genreGroup := genreTaxonomy.ResolveGenre(req.Genre)
+		// Search for any genre in the group, minus the excluded ones
+		genreFilter := models.GenreFilter{
+			Genres:  genreGroup.SearchGenres(),
+			Match:   models.GenreMatchAny,
+			Exclude: req.ExcludeGenres,
+		}
+
+		searchOpts := models.SearchOptions{
+			MaxDepth:      2,
+			Direction:     models.DirectionBoth,
+			Limit:         playlistCandidateLimit,
+			Years:         years,
+			Chronological: req.Chronological,
+		}
+		// Top artists are counted once here and by their songs once resolved
+		if !checkSearchBudget(ctx, budget, len(songs)+len(artists), searchOpts) {
+			return
+		}
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		// Spotify titles carry remaster/feat. suffixes the samples DB does not
+		trackSeeds, seedReports, err := resolveSeeds(searchCtx, songRepo, songs)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to match top tracks",
+				zap.String("userID", userID.(string)),
+				zap.Error(err))
+
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to analyze songs"})
+			return
+		}
 
-		analysisResults, err := songRepo.FindSongsByGenreBFS(songs, searchGenre, 2)
+		artistSeeds, artistReports, err := resolveArtistSeeds(searchCtx, songRepo, artists, artistSeedSongs)
 		if err != nil {
-			zap.L().Error("Failed to analyze songs",
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to match top artists",
 				zap.String("userID", userID.(string)),
 				zap.Error(err))
 
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to analyze songs"})
 			return
 		}
+		seeds := append(trackSeeds, artistSeeds...)
+		seedReports = append(seedReports, artistReports...)
+
+		seedSongs := len(songs)
+		for _, seed := range artistSeeds {
+			seedSongs += len(seed.SongIDs)
+		}
+		if !checkSearchBudget(ctx, budget, seedSongs, searchOpts) {
+			return
+		}
+
+		zap.L().Info("Matched top tracks and artists",
+			zap.String("userID", userID.(string)),
+			zap.String("seedSource", string(seedSource)),
+			zap.Int("tracks", len(songs)),
+			zap.Int("artists", len(artists)),
+			zap.Int("matched", len(seeds)))
+
+		var analysisResults []models.SearchResult
+		if len(seeds) > 0 {
+			exclusions, err := loadSearchExclusions(exclusionRepo, userID.(string), req.ExcludeArtists, req.ExcludeSongs)
+			if err != nil {
+				zap.L().Error("Failed to load exclusions",
+					zap.String("userID", userID.(string)),
+					zap.Error(err))
+
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to analyze songs"})
+				return
+			}
+			if req.ExcludeKnownTracks {
+				playlistSongs, err := spotifyService.GetUserPlaylistSongs(userID.(string))
+				if err != nil {
+					zap.L().Error("Failed to get playlist songs",
+						zap.String("userID", userID.(string)),
+						zap.Error(err))
+
+					ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to analyze songs"})
+					return
+				}
+				// The matched top tracks carry their song IDs. Top artists'
+				// songs are not known tracks, so they stay in the results.
+				exclusions.Songs = append(exclusions.Songs, trackSeeds...)
+				exclusions.Songs = append(exclusions.Songs, playlistSongs...)
+			}
+
+			searchOpts.Exclude = exclusions
+			analysisResults, err = songRepo.FindSongsByGenreBFS(searchCtx, seeds, genreFilter, budget.Apply(searchOpts))
+			if err != nil {
+				if searchAborted(ctx, searchCtx) {
+					return
+				}
+				zap.L().Error("Failed to analyze songs",
+					zap.String("userID", userID.(string)),
+					zap.Error(err))
+
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to analyze songs"})
+				return
+			}
+		}
+		countSeedResults(seedReports, analysisResults)
+		analysisResults = diversifyResults(analysisResults, diversity, playlistSongLimit)
 
 		songResults := make([]models.SongQuery, 0, len(analysisResults))
 		for _, result := range analysisResults {
@@ -306,7 +453,8 @@ func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientM
 		if len(songIDs) == 0 {
 			response = TopTracksAnalysisResponse{
 				Songs:    topTrackSongs,
-				Playlist: getRandomPlaylist(searchGenre),
+				Playlist: getRandomPlaylist(genreGroup),
+				Seeds:    seedReports,
 			}
 			zap.L().Info("No songs found for genre",
 				zap.String("userID", userID.(string)),
@@ -317,8 +465,8 @@ func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientM
 		}
 
 		// add the songs to a playlist
-		playlistName := fmt.Sprintf("Explore %s songs", normalizedGenre)
-		playlistDescription := fmt.Sprintf("Playlist of songs in the genre %s", normalizedGenre)
+		playlistName := fmt.Sprintf("Explore %s songs", genreGroup.Name)
+		playlistDescription := fmt.Sprintf("Playlist of songs in the genre %s", genreGroup.Name)
 		playlistURL, err := spotifyService.CreatePlaylistFromSongs(userID.(string), songIDs, playlistName, playlistDescription)
 
 		if err != nil {
@@ -333,6 +481,7 @@ func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientM
 		response = TopTracksAnalysisResponse{
 			Songs:    topTrackSongs,
 			Playlist: playlistURL,
+			Seeds:    seedReports,
 		}
 
 		zap.L().Info("Successfully analyzed songs",
@@ -344,7 +493,9 @@ func AnalyzeSongsGivenGenre(songRepo repository.SongRepositoryInterface, clientM
 	}
 }
 
-func SearchSongByGenre(songRepo repository.SongRepositoryInterface) gin.HandlerFunc {
+// SearchSongByGenre finds songs of the requested genres around the seed songs.
+// Searches over budget are rejected with a 422.
+func SearchSongByGenre(songRepo repository.SongRepositoryInterface, budget models.SearchBudget) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var req SongSearchRequest
 		if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -355,103 +506,397 @@ func SearchSongByGenre(songRepo repository.SongRepositoryInterface) gin.HandlerF
 			return
 		}
 
-		if req.Genre == "" {
+		format, err := negotiateGraphFormat(ctx)
+		if err != nil {
+			zap.L().Error("Invalid graph format",
+				zap.String("format", ctx.Query("format")))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		genreFilter := models.GenreFilter{Genres: req.Genres, Exclude: req.ExcludeGenres}
+		if req.Genre != "" {
+			genreFilter.Genres = append([]string{req.Genre}, req.Genres...)
+		}
+		if len(genreFilter.TargetGenres()) == 0 {
 			zap.L().Error("Genre not provided")
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "genre is required"})
 			return
 		}
 
+		genreFilter.Match, err = models.ParseGenreMatch(req.GenreMatch)
+		if err != nil {
+			zap.L().Error("Invalid genre match",
+				zap.String("genreMatch", req.GenreMatch))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		if req.MaxDepth <= 0 {
-			req.MaxDepth = 5 // Default max depth
+			req.MaxDepth = defaultGenreSearchDepth
+		}
+
+		if req.MaxPaths > maxAlternativePaths {
+			req.MaxPaths = maxAlternativePaths
 		}
 
-		results, err := songRepo.FindSongsByGenreBFS(req.Songs, req.Genre, req.MaxDepth)
+		if req.Limit <= 0 || req.Limit > maxGenreSearchLimit {
+			req.Limit = maxGenreSearchLimit
+		}
+
+		direction, err := models.ParseTraversalDirection(req.Direction)
 		if err != nil {
-			zap.L().Error("Failed to search songs",
-				zap.Error(err),
-			)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search songs"})
+			zap.L().Error("Invalid search direction",
+				zap.String("direction", req.Direction))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		graphResponse := GraphResponse{
-			AdjacencyList: make(map[string]map[string]interface{}),
-			Nodes:         make(map[string]SongNode),
-			Paths:         make([]PathInfo, 0),
+		years, err := models.ParseYearRange(req.YearFrom, req.YearTo, req.Era)
+		if err != nil {
+			zap.L().Error("Invalid year range",
+				zap.Int("yearFrom", req.YearFrom),
+				zap.Int("yearTo", req.YearTo),
+				zap.String("era", req.Era))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		for _, result := range results {
-			// Add source and matched songs to nodes
-			sourceID := fmt.Sprintf("%d", result.SourceSong.ID)
-			matchedID := fmt.Sprintf("%d", result.MatchedSong.ID)
+		searchOpts := models.SearchOptions{
+			MaxDepth:      req.MaxDepth,
+			Direction:     direction,
+			MaxPaths:      req.MaxPaths,
+			Limit:         req.Limit,
+			Years:         years,
+			Chronological: req.Chronological,
+		}
+		if !checkSearchBudget(ctx, budget, len(req.Songs), searchOpts) {
+			return
+		}
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
 
-			// Add nodes
-			if _, exists := graphResponse.Nodes[sourceID]; !exists {
-				graphResponse.Nodes[sourceID] = SongNode{
-					ID:      result.SourceSong.ID,
-					Title:   result.SourceSong.Title,
-					Artists: transformToArtistInfo(result.SourceSong.Artists),
-					Genres:  result.SourceSong.Genres,
-				}
+		seeds, seedReports, err := resolveSeeds(searchCtx, songRepo, req.Songs)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
 			}
+			zap.L().Error("Failed to match songs",
+				zap.Error(err),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search songs"})
+			return
+		}
+		// A seed can resolve to several songs, each of them searched from
+		if !checkSearchBudget(ctx, budget, len(seedSongIDs(seeds)), searchOpts) {
+			return
+		}
 
-			if _, exists := graphResponse.Nodes[matchedID]; !exists {
-				graphResponse.Nodes[matchedID] = SongNode{
-					ID:      result.MatchedSong.ID,
-					Title:   result.MatchedSong.Title,
-					Artists: transformToArtistInfo(result.MatchedSong.Artists),
-					Genres:  result.MatchedSong.Genres,
+		var results []models.SearchResult
+		if len(seeds) > 0 {
+			results, err = songRepo.FindSongsByGenreBFS(searchCtx, seeds, genreFilter, budget.Apply(searchOpts))
+			if err != nil {
+				if searchAborted(ctx, searchCtx) {
+					return
 				}
+				zap.L().Error("Failed to search songs",
+					zap.Error(err),
+				)
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search songs"})
+				return
 			}
+		}
+		countSeedResults(seedReports, results)
 
-			// Build adjacency list from path
-			for i := 0; i < len(result.Path)-1; i++ {
-				currentID := fmt.Sprintf("%d", result.Path[i].ID)
-				nextID := fmt.Sprintf("%d", result.Path[i+1].ID)
-
-				// Add nodes from path
-				if _, exists := graphResponse.Nodes[currentID]; !exists {
-					graphResponse.Nodes[currentID] = SongNode{
-						ID:      result.Path[i].ID,
-						Title:   result.Path[i].Title,
-						Artists: transformToArtistInfo(result.Path[i].Artists),
-						Genres:  result.Path[i].Genres,
-					}
-				}
+		graphResponse := newGraphResponse(results)
+		graphResponse.Seeds = seedReports
 
-				// Add to adjacency list (bidirectional)
-				if graphResponse.AdjacencyList[currentID] == nil {
-					graphResponse.AdjacencyList[currentID] = make(map[string]interface{})
-				}
-				if graphResponse.AdjacencyList[nextID] == nil {
-					graphResponse.AdjacencyList[nextID] = make(map[string]interface{})
-				}
-				graphResponse.AdjacencyList[currentID][nextID] = struct{}{}
-				graphResponse.AdjacencyList[nextID][currentID] = struct{}{}
-			}
+		zap.L().Info("Successfully searched songs",
+			zap.Any("songs", req.Songs),
+			zap.Strings("genres", genreFilter.TargetGenres()),
+			zap.Strings("excludeGenres", genreFilter.ExcludedGenres()),
+			zap.String("direction", string(direction)),
+			zap.String("format", string(format)),
+		)
+		writeGraph(ctx, format, graphResponse)
+	}
+}
+
+// SearchSamplePath finds the shortest sample chains between two songs.
+// Searches over budget, or reaching more songs than its row cap, get a 422.
+func SearchSamplePath(songRepo repository.SongRepositoryInterface, budget models.SearchBudget) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req PathSearchRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("Invalid request format",
+				zap.Error(err))
+
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if req.MaxDepth <= 0 {
+			req.MaxDepth = defaultPathSearchDepth
+		}
+		if budget.MaxDepth > 0 {
+			req.MaxDepth = min(req.MaxDepth, budget.MaxDepth)
+		}
+		req.MaxPaths = min(req.MaxPaths, maxAlternativePaths)
 
-			// Add path info
-			pathNodes := make([]string, len(result.Path))
-			for i, node := range result.Path {
-				pathNodes[i] = fmt.Sprintf("%d", node.ID)
+		direction, err := models.ParseTraversalDirection(req.Direction)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		searchOpts := models.SearchOptions{
+			MaxDepth:  req.MaxDepth,
+			Direction: direction,
+			MaxPaths:  req.MaxPaths,
+		}
+		// Each side of the bidirectional search walks about half the chain
+		sideOpts := searchOpts
+		sideOpts.MaxDepth = (searchOpts.MaxDepth + 1) / 2
+		if !checkSearchBudget(ctx, budget, 2, sideOpts) {
+			return
+		}
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		seeds, seedReports, err := resolveSeeds(searchCtx, songRepo, []models.SongQuery{req.From, req.To})
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
 			}
+			zap.L().Error("Failed to match songs",
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search path"})
+			return
+		}
 
-			graphResponse.Paths = append(graphResponse.Paths, PathInfo{
-				Start:     sourceID,
-				End:       matchedID,
-				PathNodes: pathNodes,
-				Distance:  result.Distance,
+		if seedReports[0].Status == SeedNotFound || seedReports[1].Status == SeedNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "song not found",
+				"seeds": seedReports,
 			})
+			return
+		}
+		if !checkSearchBudget(ctx, budget, len(seedSongIDs(seeds)), sideOpts) {
+			return
 		}
 
-		zap.L().Info("Successfully searched songs",
-			zap.Any("songs", req.Songs),
-			zap.String("genre", req.Genre),
+		results, err := songRepo.FindSamplePaths(searchCtx, seeds[0].SongIDs, seeds[1].SongIDs, budget.Apply(searchOpts))
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			if errors.Is(err, models.ErrSearchOverBudget) {
+				ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			zap.L().Error("Failed to search sample path",
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search path"})
+			return
+		}
+		countSeedResults(seedReports[:1], results)
+		seedReports[1].Results = len(results)
+
+		graphResponse := newGraphResponse(results)
+		graphResponse.Seeds = seedReports
+
+		zap.L().Info("Successfully searched sample path",
+			zap.Any("from", req.From),
+			zap.Any("to", req.To),
+			zap.Int("paths", len(results)),
 		)
 		ctx.JSON(http.StatusOK, graphResponse)
 	}
 }
 
+// SearchSongs autocompletes seed tracks from the samples catalog:
+// GET /songs/search?q=&artist=&limit=
+func SearchSongs(songRepo repository.SongRepositoryInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		query := models.SongSearchQuery{
+			Query:  strings.TrimSpace(ctx.Query("q")),
+			Artist: strings.TrimSpace(ctx.Query("artist")),
+			Limit:  defaultSongSearchLimit,
+		}
+
+		if query.Query == "" && query.Artist == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "q or artist is required"})
+			return
+		}
+
+		if limitParam := ctx.Query("limit"); limitParam != "" {
+			limit, err := strconv.Atoi(limitParam)
+			if err != nil || limit <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+				return
+			}
+			query.Limit = min(limit, maxSongSearchLimit)
+		}
+
+		songs, err := songRepo.SearchSongs(ctx.Request.Context(), query)
+		if err != nil {
+			zap.L().Error("Failed to search song catalog",
+				zap.String("query", query.Query),
+				zap.String("artist", query.Artist),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search songs"})
+			return
+		}
+
+		response := make([]SongNode, len(songs))
+		for i, song := range songs {
+			response[i] = toSongNode(song)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"songs": response})
+	}
+}
+
+// GetSongDetails returns a song with the songs it samples and the songs that
+// sample it: GET /songs/:id. A duplicate song is answered with its canonical
+// song, the one its lineage is read for.
+func GetSongDetails(songRepo repository.SongRepositoryInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		songID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || songID <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid song id"})
+			return
+		}
+
+		song, err := songRepo.GetSongWithDetails(ctx.Request.Context(), songID)
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "song not found"})
+			return
+		}
+		if err != nil {
+			zap.L().Error("Failed to get song details",
+				zap.Int("songID", songID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get song"})
+			return
+		}
+
+		lineage, err := songRepo.GetSampleLineage(ctx.Request.Context(), song.ID)
+		if err != nil {
+			zap.L().Error("Failed to get sample lineage",
+				zap.Int("songID", songID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get song"})
+			return
+		}
+
+		ids := append(append([]int(nil), lineage.SamplesUsed...), lineage.SampledIn...)
+		songs, err := songRepo.GetSongsWithDetails(ctx.Request.Context(), ids)
+		if err != nil {
+			zap.L().Error("Failed to get song details",
+				zap.Int("songID", songID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get song"})
+			return
+		}
+
+		response := SongDetailsResponse{
+			Song:        toSongNode(*song),
+			SamplesUsed: hydratedSongNodes(songs, lineage.SamplesUsed),
+			SampledIn:   hydratedSongNodes(songs, lineage.SampledIn),
+		}
+		response.SamplesUsedCount = len(response.SamplesUsed)
+		response.SampledInCount = len(response.SampledIn)
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// GetSongGraph returns every song within radius sample hops of a song,
+// whatever its genres: GET /songs/:id/graph?radius=&direction=&maxNodes=
+func GetSongGraph(songRepo repository.SongRepositoryInterface, budget models.SearchBudget) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		songID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || songID <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid song id"})
+			return
+		}
+
+		opts := models.NeighborhoodOptions{Radius: defaultNeighborhoodRadius, MaxNodes: maxNeighborhoodNodes}
+		if radiusParam := ctx.Query("radius"); radiusParam != "" {
+			radius, err := strconv.Atoi(radiusParam)
+			if err != nil || radius <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "radius must be a positive integer"})
+				return
+			}
+			opts.Radius = min(radius, maxNeighborhoodRadius)
+		}
+		if maxNodesParam := ctx.Query("maxNodes"); maxNodesParam != "" {
+			maxNodes, err := strconv.Atoi(maxNodesParam)
+			if err != nil || maxNodes <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxNodes must be a positive integer"})
+				return
+			}
+			opts.MaxNodes = min(maxNodes, maxNeighborhoodNodes)
+		}
+		opts.Direction, err = models.ParseTraversalDirection(ctx.Query("direction"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		searchCtx, cancel := budget.Context(ctx.Request.Context())
+		defer cancel()
+
+		neighborhood, err := songRepo.GetSongNeighborhood(searchCtx, songID, opts)
+		if err != nil {
+			if searchAborted(ctx, searchCtx) {
+				return
+			}
+			zap.L().Error("Failed to get song neighborhood",
+				zap.Int("songID", songID),
+				zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get song graph"})
+			return
+		}
+		if neighborhood == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "song not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newNeighborhoodGraphResponse(neighborhood))
+	}
+}
+
+// newNeighborhoodGraphResponse builds the graph of a song neighborhood. It has
+// no paths, only the sample edges between the songs.
+func newNeighborhoodGraphResponse(neighborhood *models.SongNeighborhood) GraphResponse {
+	builder := newGraphBuilder()
+	for _, song := range neighborhood.Songs {
+		builder.addNode(song)
+	}
+	for _, edge := range neighborhood.Edges {
+		builder.addEdge(edge.SampledInID, edge.OriginalID)
+	}
+
+	response := builder.build()
+	response.Center = songKey(neighborhood.SongID)
+	response.Truncated = neighborhood.Truncated
+	return response
+}
+
+// hydratedSongNodes looks ids up in songs, keeping their order and skipping
+// songs that could not be hydrated
+func hydratedSongNodes(songs map[int]*models.SongNode, ids []int) []SongNode {
+	nodes := make([]SongNode, 0, len(ids))
+	for _, id := range ids {
+		if song, ok := songs[id]; ok {
+			nodes = append(nodes, toSongNode(*song))
+		}
+	}
+	return nodes
+}
+
 func DeletePlaylist(spotifyService services.SpotifyServiceInterface, spotifySongRepo repository.SpotifySongRepositoryInterface) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		userID, exists := ctx.Get("userID")
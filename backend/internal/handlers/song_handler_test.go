@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Emeruem-Kennedy1/ghopper/config"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -16,6 +21,10 @@ import (
 	"github.com/zmb3/spotify"
 )
 
+// testSearchBudget caps searches in the handler tests without capping rows, so
+// search options reach the repository unchanged
+var testSearchBudget = models.SearchBudget{MaxDepth: 10, MaxSeeds: 5, MaxCost: 1000000}
+
 func setupSongHandlerTest(songRepo repository.SongRepositoryInterface) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -26,11 +35,27 @@ func setupSongHandlerTest(songRepo repository.SongRepositoryInterface) *gin.Engi
 		c.Next()
 	})
 
-	r.POST("/search", SearchSongByGenre(songRepo))
+	r.POST("/search", SearchSongByGenre(songRepo, testSearchBudget))
+	r.POST("/search/path", SearchSamplePath(songRepo, testSearchBudget))
+	r.GET("/songs/search", SearchSongs(songRepo))
+	r.GET("/songs/:id", GetSongDetails(songRepo))
+	r.GET("/songs/:id/graph", GetSongGraph(songRepo, testSearchBudget))
 	return r
 }
 
-func setupAnalyzeSongsTest(songRepo *MockSongRepository, clientManager *MockClientManager, spotifyService *MockSpotifyService) *gin.Engine {
+// rockGenreGroup is the genre group the analysis tests resolve "rock" to
+var rockGenreGroup = models.GenreGroup{
+	ID:   "rock",
+	Name: "Rock / Pop",
+	Genres: []models.GenreGroupGenre{
+		{Name: "rock"},
+		{Name: "pop"},
+		{Name: "rock and roll", Synonym: true},
+	},
+	Playlists: []models.GenreGroupPlaylist{{URL: "https://open.spotify.com/playlist/rock"}},
+}
+
+func setupAnalyzeSongsTest(songRepo *MockSongRepository, clientManager *MockClientManager, spotifyService *MockSpotifyService, genreTaxonomy *MockGenreTaxonomyService, exclusionRepo *MockExclusionRepository) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 
@@ -40,7 +65,7 @@ func setupAnalyzeSongsTest(songRepo *MockSongRepository, clientManager *MockClie
 		c.Next()
 	})
 
-	r.POST("/toptracks-analysis", AnalyzeSongsGivenGenre(songRepo, clientManager, spotifyService))
+	r.POST("/toptracks-analysis", AnalyzeSongsGivenGenre(songRepo, clientManager, spotifyService, genreTaxonomy, exclusionRepo, models.DiversityOptions{Lambda: 1}, testSearchBudget))
 	return r
 }
 
@@ -59,6 +84,9 @@ func setupDeletePlaylistTest(spotifyService *MockSpotifyService, spotifySongRepo
 }
 
 func TestSearchSongByGenre(t *testing.T) {
+	exactMatch := []models.SongMatch{{SongID: 1, Title: "Test Song", Artist: "Test Artist", Confidence: 1}}
+	matchedSeeds := []models.SongQuery{{Title: "Test Song", Artist: "Test Artist", SongIDs: []int{1}}}
+
 	t.Run("Successful_Search", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockSongRepository)
@@ -107,7 +135,8 @@ func TestSearchSongByGenre(t *testing.T) {
 		}
 
 		// Setup mock expectations
-		mockRepo.On("FindSongsByGenreBFS", searchRequest.Songs, searchRequest.Genre, searchRequest.MaxDepth).
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter(searchRequest.Genre), models.SearchOptions{MaxDepth: searchRequest.MaxDepth, Direction: models.DirectionBoth, Limit: maxGenreSearchLimit}).
 			Return(mockResults, nil)
 
 		// Convert request to JSON
@@ -133,11 +162,58 @@ func TestSearchSongByGenre(t *testing.T) {
 		assert.Len(t, graphResponse.Paths, 1, "Should have one path")
 		assert.Len(t, graphResponse.Nodes, 2, "Should have two nodes")
 		assert.NotEmpty(t, graphResponse.AdjacencyList, "Should have adjacency list")
+		assert.Equal(t, []SeedReport{
+			{Title: "Test Song", Artist: "Test Artist", Status: SeedMatched, SongIDs: []int{1}, Results: 1},
+		}, graphResponse.Seeds, "Should report how the seed was matched")
 
 		// Verify mock expectations
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Seed_Diagnostics", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song - 2011 Remaster", Artist: "Test Artist"},
+				{Title: "Missing Song", Artist: "Missing Artist"},
+			},
+			Genre:    "rock",
+			MaxDepth: 2,
+		}
+
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).
+			Return([]models.SongMatch{{SongID: 1, Title: "Test Song", Artist: "Test Artist", Confidence: 0.95}}, nil)
+		mockRepo.On("MatchSongs", searchRequest.Songs[1]).Return(nil, nil)
+		mockRepo.On("FindSongsByGenreBFS",
+			[]models.SongQuery{{Title: "Test Song - 2011 Remaster", Artist: "Test Artist", SongIDs: []int{1}}},
+			models.NewGenreFilter("rock"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: maxGenreSearchLimit}).
+			Return([]models.SearchResult{}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var graphResponse GraphResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &graphResponse), "Should parse response JSON")
+		require.Len(t, graphResponse.Seeds, 2, "Should report every seed")
+		assert.Equal(t, SeedFuzzyMatched, graphResponse.Seeds[0].Status)
+		assert.Equal(t, []int{1}, graphResponse.Seeds[0].SongIDs)
+		assert.Equal(t, SeedNotFound, graphResponse.Seeds[1].Status)
+		assert.Empty(t, graphResponse.Seeds[1].SongIDs)
+		assert.Zero(t, graphResponse.Seeds[1].Results)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Missing_Genre", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockSongRepository)
@@ -187,7 +263,8 @@ func TestSearchSongByGenre(t *testing.T) {
 		}
 
 		// Setup mock to return an error
-		mockRepo.On("FindSongsByGenreBFS", searchRequest.Songs, searchRequest.Genre, searchRequest.MaxDepth).
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter(searchRequest.Genre), models.SearchOptions{MaxDepth: searchRequest.MaxDepth, Direction: models.DirectionBoth, Limit: maxGenreSearchLimit}).
 			Return([]models.SearchResult{}, assert.AnError)
 
 		// Convert request to JSON
@@ -214,89 +291,1351 @@ func TestSearchSongByGenre(t *testing.T) {
 		// Verify mock expectations
 		mockRepo.AssertExpectations(t)
 	})
-}
 
-func TestAnalyzeSongsGivenGenre(t *testing.T) {
-	t.Run("Successful_Analysis", func(t *testing.T) {
+	t.Run("Directional_Search", func(t *testing.T) {
 		// Arrange
-		mockSongRepo := new(MockSongRepository)
-		mockClientManager := new(MockClientManager)
-		mockSpotifyService := new(MockSpotifyService)
-		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService)
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
 
-		// Mock Spotify client
-		mockClient := new(MockSpotifyClient)
-		mockTracks := &spotify.FullTrackPage{
-			Tracks: []spotify.FullTrack{
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genre:     "soul",
+			MaxDepth:  2,
+			Direction: "ancestors",
+		}
+
+		mockResults := []models.SearchResult{
+			{
+				SourceSong:    models.SongNode{ID: 1, Title: "Test Song"},
+				MatchedSong:   models.SongNode{ID: 2, Title: "Soul Song"},
+				Distance:      1,
+				Path:          []models.SongNode{{ID: 1}, {ID: 2}},
+				HopDirections: []models.TraversalDirection{models.DirectionAncestors},
+			},
+		}
+
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter(searchRequest.Genre), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionAncestors, Limit: maxGenreSearchLimit}).
+			Return(mockResults, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var graphResponse GraphResponse
+		err := json.Unmarshal(resp.Body.Bytes(), &graphResponse)
+		require.NoError(t, err, "Should parse response JSON")
+
+		require.Len(t, graphResponse.Paths, 1, "Should have one path")
+		assert.Equal(t, []models.TraversalDirection{models.DirectionAncestors}, graphResponse.Paths[0].HopDirections, "Path should record hop directions")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Caps_Alternative_Paths", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genre:    "soul",
+			MaxDepth: 3,
+			MaxPaths: 50,
+		}
+
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter(searchRequest.Genre), models.SearchOptions{MaxDepth: 3, Direction: models.DirectionBoth, MaxPaths: maxAlternativePaths, Limit: maxGenreSearchLimit}).
+			Return([]models.SearchResult{}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Limit_And_Score", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genre:    "soul",
+			MaxDepth: 2,
+			Limit:    5,
+		}
+
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter(searchRequest.Genre), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: 5}).
+			Return([]models.SearchResult{
 				{
-					SimpleTrack: spotify.SimpleTrack{
-						Name: "Test Song",
-						Artists: []spotify.SimpleArtist{
-							{Name: "Test Artist"},
-						},
-					},
+					SourceSong:  models.SongNode{ID: 1, Title: "Test Song"},
+					MatchedSong: models.SongNode{ID: 2, Title: "Soul Song", Genres: []string{"soul"}},
+					Distance:    1,
+					Path:        []models.SongNode{{ID: 1, Title: "Test Song"}, {ID: 2, Title: "Soul Song"}},
+					Score:       0.8,
 				},
+			}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var graphResponse GraphResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &graphResponse))
+		require.Len(t, graphResponse.Paths, 1)
+		assert.Equal(t, 0.8, graphResponse.Paths[0].Score, "Paths should carry the result score")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Multiple_Genres_With_Exclusions", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
 			},
+			Genres:        []string{"jazz", "blues"},
+			GenreMatch:    "all",
+			ExcludeGenres: []string{"smooth jazz"},
+			MaxDepth:      2,
 		}
 
-		// Prepare mock search request
-		analysisRequest := TopTracksAnalysisRequest{
-			Genre: "rock",
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.GenreFilter{
+			Genres:  []string{"jazz", "blues"},
+			Match:   models.GenreMatchAll,
+			Exclude: []string{"smooth jazz"},
+		}, models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: maxGenreSearchLimit}).
+			Return([]models.SearchResult{}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Genre_Match", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genres:     []string{"jazz"},
+			GenreMatch: "most",
 		}
 
-		// Setup mock expectations
-		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
-		mockClient.On("CurrentUsersTopTracksOpt", mock.Anything).Return(mockTracks, nil)
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
 
-		mockSongRepo.On("FindSongsByGenreBFS", mock.Anything, "rock", 2).Return(
-			[]models.SearchResult{
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		assert.Contains(t, resp.Body.String(), "genreMatch must be one of", "Error message should list valid match modes")
+		mockRepo.AssertNotCalled(t, "FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Era_And_Chronological", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genre:         "soul",
+			MaxDepth:      2,
+			Era:           "1970s",
+			YearTo:        1975,
+			Chronological: true,
+		}
+
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter(searchRequest.Genre), models.SearchOptions{
+			MaxDepth:      2,
+			Direction:     models.DirectionBoth,
+			Limit:         maxGenreSearchLimit,
+			Years:         models.YearRange{From: 1970, To: 1975},
+			Chronological: true,
+		}).
+			Return([]models.SearchResult{
 				{
-					MatchedSong: models.SongNode{
-						Title: "Matched Song",
-						Artists: []models.Artist{
-							{Name: "Matched Artist"},
-						},
-					},
+					SourceSong:  models.SongNode{ID: 1, Title: "Test Song", ReleaseYear: 2012},
+					MatchedSong: models.SongNode{ID: 2, Title: "Soul Song", Genres: []string{"soul"}, ReleaseYear: 1972},
+					Distance:    1,
+					Path:        []models.SongNode{{ID: 1, Title: "Test Song", ReleaseYear: 2012}, {ID: 2, Title: "Soul Song", ReleaseYear: 1972}},
 				},
 			}, nil)
 
-		mockSpotifyService.On("GetSongURL", "test-user-id", "Matched Song", "Matched Artist").
-			Return("https://open.spotify.com/track/123", nil)
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
 
-		mockSpotifyService.On("CreatePlaylistFromSongs",
-			"test-user-id",
-			mock.Anything,
-			mock.Anything,
-			mock.Anything).
-			Return("https://open.spotify.com/playlist/test-playlist", nil)
+		// Act
+		r.ServeHTTP(resp, req)
 
-		// Convert request to JSON
-		jsonRequest, _ := json.Marshal(analysisRequest)
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
 
-		// Create test HTTP request
-		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		var graphResponse GraphResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &graphResponse))
+		assert.Equal(t, 1972, graphResponse.Nodes["2"].ReleaseYear, "Matched songs should carry their release year")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Year_Range", func(t *testing.T) {
+		for _, searchRequest := range []SongSearchRequest{
+			{Genre: "soul", Era: "seventies"},
+			{Genre: "soul", YearFrom: 1980, YearTo: 1970},
+		} {
+			// Arrange
+			mockRepo := new(MockSongRepository)
+			r := setupSongHandlerTest(mockRepo)
+			searchRequest.Songs = []models.SongQuery{{Title: "Test Song", Artist: "Test Artist"}}
+
+			jsonRequest, _ := json.Marshal(searchRequest)
+			req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+			mockRepo.AssertNotCalled(t, "FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Invalid_Direction", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genre:     "soul",
+			Direction: "sideways",
+		}
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
 		req.Header.Set("Content-Type", "application/json")
 		resp := httptest.NewRecorder()
 
-		// Serve the request
+		// Act
 		r.ServeHTTP(resp, req)
 
 		// Assert
-		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		assert.Contains(t, resp.Body.String(), "direction must be one of", "Error message should list valid directions")
+		mockRepo.AssertNotCalled(t, "FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything)
+	})
 
-		// Parse the response
-		var topTracksResponse TopTracksAnalysisResponse
-		err := json.Unmarshal(resp.Body.Bytes(), &topTracksResponse)
-		require.NoError(t, err, "Should parse response JSON")
+	t.Run("Over_Budget", func(t *testing.T) {
+		for name, searchRequest := range map[string]SongSearchRequest{
+			"maxDepth":       {Genre: "soul", MaxDepth: 20},
+			"seed songs":     {Genre: "soul", Songs: make([]models.SongQuery, 6)},
+			"estimated cost": {Genre: "soul", MaxDepth: 8, MaxPaths: 5},
+		} {
+			// Arrange
+			mockRepo := new(MockSongRepository)
+			r := setupSongHandlerTest(mockRepo)
+			if searchRequest.Songs == nil {
+				searchRequest.Songs = []models.SongQuery{{Title: "Test Song", Artist: "Test Artist"}}
+			}
+
+			jsonRequest, _ := json.Marshal(searchRequest)
+			req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should reject searches over the %s limit", name)
+			assert.Contains(t, resp.Body.String(), name, "Error message should name the exceeded limit")
+			mockRepo.AssertNotCalled(t, "MatchSongs", mock.Anything)
+		}
+	})
 
-		// Verify response contents
-		assert.NotEmpty(t, topTracksResponse.Playlist, "Should have a playlist URL")
-		assert.NotEmpty(t, topTracksResponse.Songs, "Should have songs")
+	t.Run("Budgets_Resolved_Songs", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
 
-		// Verify mock expectations
-		mockClientManager.AssertExpectations(t)
-		mockSongRepo.AssertExpectations(t)
-		mockSpotifyService.AssertExpectations(t)
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{
+				{SongIDs: []int{1, 2, 2, 3, 4, 5, 6, 7}},
+				{Title: "Test Song", Artist: "Test Artist"},
+			},
+			Genre:    "soul",
+			MaxDepth: 2,
+		}
+
+		// Pinned IDs are capped and checked, the unknown song 4 is dropped
+		mockRepo.On("GetSongsWithDetails", []int{1, 2, 3, 4, 5}).
+			Return(map[int]*models.SongNode{1: {ID: 1}, 2: {ID: 2}, 3: {ID: 3}, 5: {ID: 5}}, nil)
+		mockRepo.On("MatchSongs", searchRequest.Songs[1]).
+			Return([]models.SongMatch{{SongID: 10, Confidence: 1}, {SongID: 11, Confidence: 0.95}}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should count every song the seeds resolved to")
+		assert.Contains(t, resp.Body.String(), "6 seed songs")
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Default_Budget_Accepts_Default_Search", func(t *testing.T) {
+		// Arrange
+		cfg, err := config.Load()
+		require.NoError(t, err)
+		budget := models.SearchBudget{MaxDepth: cfg.SearchMaxDepth, MaxSeeds: cfg.SearchMaxSeeds, MaxCost: cfg.SearchMaxCost}
+
+		mockRepo := new(MockSongRepository)
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.POST("/search", SearchSongByGenre(mockRepo, budget))
+
+		// As many seeds as the budget allows, with the default depth and direction
+		searchRequest := SongSearchRequest{Genre: "soul"}
+		for i := 1; i <= cfg.SearchMaxSeeds; i++ {
+			seed := models.SongQuery{Title: fmt.Sprintf("Song %d", i), Artist: "Test Artist"}
+			searchRequest.Songs = append(searchRequest.Songs, seed)
+			mockRepo.On("MatchSongs", seed).Return([]models.SongMatch{{SongID: i, Confidence: 1}}, nil)
+		}
+		mockRepo.On("FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything).Return([]models.SearchResult{}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "The default budget should accept a default search from the most seeds it allows: %s", resp.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Row_Cap_And_Timeout", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.POST("/search", SearchSongByGenre(mockRepo, models.SearchBudget{MaxRows: 100, Timeout: time.Millisecond}))
+
+		searchRequest := SongSearchRequest{
+			Songs:    []models.SongQuery{{Title: "Test Song", Artist: "Test Artist"}},
+			Genre:    "soul",
+			MaxDepth: 2,
+		}
+
+		// The search runs past the timeout and fails with its context
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter("soul"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: maxGenreSearchLimit, MaxRows: 100}).
+			Run(func(mock.Arguments) { time.Sleep(10 * time.Millisecond) }).
+			Return([]models.SearchResult(nil), context.DeadlineExceeded)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Code, "Should return Gateway Timeout status")
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Export_Format", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs:    []models.SongQuery{{Title: "Test Song", Artist: "Test Artist"}},
+			Genre:    "soul",
+			MaxDepth: 2,
+		}
+
+		mockRepo.On("MatchSongs", searchRequest.Songs[0]).Return(exactMatch, nil)
+		mockRepo.On("FindSongsByGenreBFS", matchedSeeds, models.NewGenreFilter("soul"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: maxGenreSearchLimit}).
+			Return([]models.SearchResult{{
+				SourceSong:    models.SongNode{ID: 1, Title: "Test Song"},
+				MatchedSong:   models.SongNode{ID: 2, Title: "Soul Song", Genres: []string{"soul"}},
+				Distance:      1,
+				Path:          []models.SongNode{{ID: 1, Title: "Test Song"}, {ID: 2, Title: "Soul Song", Genres: []string{"soul"}}},
+				HopDirections: []models.TraversalDirection{models.DirectionAncestors},
+			}}, nil)
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search?format=dot", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		assert.Equal(t, "text/vnd.graphviz; charset=utf-8", resp.Header().Get("Content-Type"))
+		assert.Contains(t, resp.Body.String(), `"1" -> "2" [paths="0"];`, "Should export the sample edge")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Format", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		searchRequest := SongSearchRequest{
+			Songs: []models.SongQuery{{Title: "Test Song", Artist: "Test Artist"}},
+			Genre: "soul",
+		}
+
+		jsonRequest, _ := json.Marshal(searchRequest)
+		req := httptest.NewRequest("POST", "/search?format=xlsx", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		assert.Contains(t, resp.Body.String(), "format must be one of", "Error message should list valid formats")
+		mockRepo.AssertNotCalled(t, "MatchSongs", mock.Anything)
+	})
+}
+
+func TestSearchSamplePath(t *testing.T) {
+	from := models.SongQuery{Title: "Sampler", Artist: "Producer"}
+	to := models.SongQuery{Title: "Original", Artist: "Band"}
+
+	t.Run("Found_Path", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("MatchSongs", from).Return([]models.SongMatch{{SongID: 1, Confidence: 1}}, nil)
+		mockRepo.On("MatchSongs", to).Return([]models.SongMatch{{SongID: 3, Confidence: 0.95}}, nil)
+		mockRepo.On("FindSamplePaths", []int{1}, []int{3}, models.SearchOptions{MaxDepth: defaultPathSearchDepth, Direction: models.DirectionBoth, MaxPaths: 2}).
+			Return([]models.SearchResult{
+				{
+					SourceSong:    models.SongNode{ID: 1, Title: "Sampler"},
+					MatchedSong:   models.SongNode{ID: 3, Title: "Original"},
+					Distance:      2,
+					Path:          []models.SongNode{{ID: 1}, {ID: 2}, {ID: 3}},
+					HopDirections: []models.TraversalDirection{models.DirectionAncestors, models.DirectionAncestors},
+				},
+			}, nil)
+
+		jsonRequest, _ := json.Marshal(PathSearchRequest{From: from, To: to, MaxPaths: 2})
+		req := httptest.NewRequest("POST", "/search/path", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var graphResponse GraphResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &graphResponse), "Should parse response JSON")
+		require.Len(t, graphResponse.Paths, 1)
+		assert.Equal(t, []string{"1", "2", "3"}, graphResponse.Paths[0].PathNodes)
+		assert.Len(t, graphResponse.Nodes, 3)
+		require.Len(t, graphResponse.Seeds, 2)
+		assert.Equal(t, SeedFuzzyMatched, graphResponse.Seeds[1].Status)
+		assert.Equal(t, 1, graphResponse.Seeds[1].Results)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Song_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("MatchSongs", from).Return([]models.SongMatch{{SongID: 1, Confidence: 1}}, nil)
+		mockRepo.On("MatchSongs", to).Return(nil, nil)
+
+		jsonRequest, _ := json.Marshal(PathSearchRequest{From: from, To: to})
+		req := httptest.NewRequest("POST", "/search/path", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+		assert.Contains(t, resp.Body.String(), "not_found", "Should report which song was not found")
+		mockRepo.AssertNotCalled(t, "FindSamplePaths", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Caps_Depth", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		pinnedFrom := models.SongQuery{SongIDs: []int{1}}
+		pinnedTo := models.SongQuery{SongIDs: []int{3}}
+		mockRepo.On("GetSongsWithDetails", []int{1}).Return(map[int]*models.SongNode{1: {ID: 1}}, nil)
+		mockRepo.On("GetSongsWithDetails", []int{3}).Return(map[int]*models.SongNode{3: {ID: 3}}, nil)
+		mockRepo.On("FindSamplePaths", []int{1}, []int{3}, models.SearchOptions{MaxDepth: testSearchBudget.MaxDepth, Direction: models.DirectionDescendants}).
+			Return(nil, nil)
+
+		jsonRequest, _ := json.Marshal(PathSearchRequest{From: pinnedFrom, To: pinnedTo, MaxDepth: 100, Direction: "descendants"})
+		req := httptest.NewRequest("POST", "/search/path", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		mockRepo.AssertNotCalled(t, "MatchSongs", mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Over_Budget", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.POST("/search/path", SearchSamplePath(mockRepo, models.SearchBudget{MaxDepth: 6, MaxCost: 100}))
+
+		jsonRequest, _ := json.Marshal(PathSearchRequest{From: models.SongQuery{SongIDs: []int{1}}, To: models.SongQuery{SongIDs: []int{3}}, MaxDepth: 6})
+		req := httptest.NewRequest("POST", "/search/path", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should reject path searches over budget")
+		assert.Contains(t, resp.Body.String(), "estimated cost")
+		mockRepo.AssertNotCalled(t, "FindSamplePaths", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Row_Cap", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.POST("/search/path", SearchSamplePath(mockRepo, models.SearchBudget{MaxDepth: 6, MaxRows: 50}))
+
+		mockRepo.On("GetSongsWithDetails", []int{1}).Return(map[int]*models.SongNode{1: {ID: 1}}, nil)
+		mockRepo.On("GetSongsWithDetails", []int{3}).Return(map[int]*models.SongNode{3: {ID: 3}}, nil)
+		mockRepo.On("FindSamplePaths", []int{1}, []int{3}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionBoth, MaxRows: 50}).
+			Return(nil, fmt.Errorf("%w: too many songs", models.ErrSearchOverBudget))
+
+		jsonRequest, _ := json.Marshal(PathSearchRequest{From: models.SongQuery{SongIDs: []int{1}}, To: models.SongQuery{SongIDs: []int{3}}})
+		req := httptest.NewRequest("POST", "/search/path", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should reject path searches reaching too many songs")
+		assert.Contains(t, resp.Body.String(), "too many songs")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Search_Error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongsWithDetails", []int{1}).Return(map[int]*models.SongNode{1: {ID: 1}}, nil)
+		mockRepo.On("GetSongsWithDetails", []int{3}).Return(map[int]*models.SongNode{3: {ID: 3}}, nil)
+		mockRepo.On("FindSamplePaths", []int{1}, []int{3}, mock.Anything).Return(nil, assert.AnError)
+
+		jsonRequest, _ := json.Marshal(PathSearchRequest{From: models.SongQuery{SongIDs: []int{1}}, To: models.SongQuery{SongIDs: []int{3}}})
+		req := httptest.NewRequest("POST", "/search/path", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestSearchSongs(t *testing.T) {
+	t.Run("Found_Songs", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("SearchSongs", models.SongSearchQuery{Query: "Amen", Artist: "Winstons", Limit: 5}).
+			Return([]models.SongNode{
+				{
+					ID:          1,
+					Title:       "Amen, Brother",
+					Artists:     []models.Artist{{ID: 10, Name: "The Winstons", IsMain: true}},
+					Genres:      []string{"soul"},
+					ReleaseYear: 1969,
+				},
+			}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/search?q=Amen&artist=Winstons&limit=5", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response struct {
+			Songs []SongNode `json:"songs"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		require.Len(t, response.Songs, 1)
+		assert.Equal(t, "Amen, Brother", response.Songs[0].Title)
+		assert.Equal(t, 1969, response.Songs[0].ReleaseYear)
+		assert.Equal(t, "The Winstons", response.Songs[0].Artists[0].Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Caps_Limit", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("SearchSongs", models.SongSearchQuery{Query: "Amen", Limit: maxSongSearchLimit}).
+			Return([]models.SongNode{}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/search?q=Amen&limit=1000", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		assert.JSONEq(t, `{"songs": []}`, resp.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Missing_Query", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/search?limit=5", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		mockRepo.AssertNotCalled(t, "SearchSongs", mock.Anything)
+	})
+
+	t.Run("Invalid_Limit", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/search?q=Amen&limit=abc", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		assert.Contains(t, resp.Body.String(), "limit must be a positive integer")
+	})
+
+	t.Run("Search_Error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("SearchSongs", mock.Anything).Return(nil, assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/search?q=Amen", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetSongDetails(t *testing.T) {
+	t.Run("Found_Song", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongWithDetails", 1).Return(&models.SongNode{ID: 1, Title: "Song", ReleaseYear: 1994}, nil)
+		mockRepo.On("GetSampleLineage", 1).
+			Return(&models.SampleLineage{SamplesUsed: []int{2, 3}, SampledIn: []int{4}}, nil)
+		mockRepo.On("GetSongsWithDetails", []int{2, 3, 4}).
+			Return(map[int]*models.SongNode{
+				2: {ID: 2, Title: "Original"},
+				4: {ID: 4, Title: "Sampler"},
+			}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/1", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response SongDetailsResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, "Song", response.Song.Title)
+		assert.Equal(t, 1994, response.Song.ReleaseYear)
+		require.Len(t, response.SamplesUsed, 1, "Songs that fail to hydrate should be skipped")
+		assert.Equal(t, 2, response.SamplesUsed[0].ID)
+		assert.Equal(t, 1, response.SamplesUsedCount)
+		require.Len(t, response.SampledIn, 1)
+		assert.Equal(t, 4, response.SampledIn[0].ID)
+		assert.Equal(t, 1, response.SampledInCount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Song_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongWithDetails", 999).Return((*models.SongNode)(nil), fmt.Errorf("error getting song: %w", sql.ErrNoRows))
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/999", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetSampleLineage", mock.Anything)
+	})
+
+	t.Run("Duplicate_Song", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		// Song 7 is a duplicate of song 1
+		mockRepo.On("GetSongWithDetails", 7).Return(&models.SongNode{ID: 1, Title: "Song"}, nil)
+		mockRepo.On("GetSampleLineage", 1).Return(&models.SampleLineage{SamplesUsed: []int{2}}, nil)
+		mockRepo.On("GetSongsWithDetails", []int{2}).
+			Return(map[int]*models.SongNode{2: {ID: 2, Title: "Original"}}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/7", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response SongDetailsResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, 1, response.Song.ID, "Should answer with the canonical song its lineage belongs to")
+		require.Len(t, response.SamplesUsed, 1)
+		assert.Equal(t, 2, response.SamplesUsed[0].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_ID", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/abc", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		mockRepo.AssertNotCalled(t, "GetSampleLineage", mock.Anything)
+	})
+
+	t.Run("Lineage_Error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongWithDetails", 1).Return(&models.SongNode{ID: 1, Title: "Song"}, nil)
+		mockRepo.On("GetSampleLineage", 1).Return(nil, assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/1", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetSongGraph(t *testing.T) {
+	t.Run("Neighborhood", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongNeighborhood", 1, models.NeighborhoodOptions{Radius: 2, Direction: models.DirectionAncestors, MaxNodes: 10}).
+			Return(&models.SongNeighborhood{
+				SongID: 1,
+				Songs: []models.SongNode{
+					{ID: 1, Title: "Song", Genres: []string{"hip hop"}},
+					{ID: 2, Title: "Original", Genres: []string{"soul"}},
+					{ID: 3, Title: "Older Original", Genres: []string{"jazz"}},
+				},
+				Edges:     []models.SampleEdge{{SampledInID: 1, OriginalID: 2}, {SampledInID: 2, OriginalID: 3}},
+				Truncated: true,
+			}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/1/graph?radius=2&direction=ancestors&maxNodes=10", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		var response GraphResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response), "Should parse response JSON")
+		assert.Equal(t, "1", response.Center)
+		assert.True(t, response.Truncated)
+		assert.Len(t, response.Nodes, 3)
+		assert.Equal(t, "Older Original", response.Nodes["3"].Title)
+		assert.Contains(t, response.AdjacencyList["2"], "1")
+		assert.Contains(t, response.AdjacencyList["2"], "3")
+		assert.Empty(t, response.Paths)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Caps_Radius_And_Nodes", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongNeighborhood", 1, models.NeighborhoodOptions{Radius: maxNeighborhoodRadius, Direction: models.DirectionBoth, MaxNodes: maxNeighborhoodNodes}).
+			Return(&models.SongNeighborhood{SongID: 1, Songs: []models.SongNode{{ID: 1, Title: "Song"}}}, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/1/graph?radius=10&maxNodes=100000", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+		assert.NotContains(t, resp.Body.String(), "truncated", "Should leave the flag out when nothing was cut")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_Parameters", func(t *testing.T) {
+		for _, target := range []string{
+			"/songs/abc/graph",
+			"/songs/1/graph?radius=0",
+			"/songs/1/graph?maxNodes=-1",
+			"/songs/1/graph?direction=sideways",
+		} {
+			// Arrange
+			mockRepo := new(MockSongRepository)
+			r := setupSongHandlerTest(mockRepo)
+
+			// Act
+			req := httptest.NewRequest("GET", target, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code, "Should reject %s", target)
+			mockRepo.AssertNotCalled(t, "GetSongNeighborhood", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Song_Not_Found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongNeighborhood", 999, mock.Anything).Return(nil, nil)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/999/graph", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found status")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository_Error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSongRepository)
+		r := setupSongHandlerTest(mockRepo)
+
+		mockRepo.On("GetSongNeighborhood", 1, mock.Anything).Return(nil, assert.AnError)
+
+		// Act
+		req := httptest.NewRequest("GET", "/songs/1/graph", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Should return Internal Server Error status")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestAnalyzeSongsGivenGenre(t *testing.T) {
+	t.Run("Successful_Analysis", func(t *testing.T) {
+		// Arrange
+		mockSongRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockSpotifyService := new(MockSpotifyService)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService, mockGenreTaxonomy, mockExclusionRepo)
+
+		// Mock Spotify client
+		mockClient := new(MockSpotifyClient)
+		mockTracks := &spotify.FullTrackPage{
+			Tracks: []spotify.FullTrack{
+				{
+					SimpleTrack: spotify.SimpleTrack{
+						Name: "Test Song",
+						Artists: []spotify.SimpleArtist{
+							{Name: "Test Artist"},
+						},
+					},
+				},
+			},
+		}
+
+		// Prepare mock search request
+		analysisRequest := TopTracksAnalysisRequest{
+			Genre: "rock",
+		}
+
+		// Setup mock expectations
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", mock.Anything).Return(mockTracks, nil)
+		mockGenreTaxonomy.On("ResolveGenre", "rock").Return(rockGenreGroup)
+
+		mockSongRepo.On("MatchSongs", models.SongQuery{Title: "Test Song", Artist: "Test Artist"}).
+			Return([]models.SongMatch{
+				{SongID: 7, Title: "Test Song", Artist: "Test Artist", Confidence: 1},
+				{SongID: 8, Title: "Test Song Interlude", Artist: "Test Artist", Confidence: 0.7},
+			}, nil)
+
+		mockExclusionRepo.On("ListExclusions", "test-user-id").Return([]models.UserExclusion{}, nil)
+
+		seeds := []models.SongQuery{{Title: "Test Song", Artist: "Test Artist", SongIDs: []int{7}}}
+		genreFilter := models.GenreFilter{Genres: []string{"pop", "rock"}, Match: models.GenreMatchAny}
+		mockSongRepo.On("FindSongsByGenreBFS", seeds, genreFilter, models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: playlistCandidateLimit}).Return(
+			[]models.SearchResult{
+				{
+					MatchedSong: models.SongNode{
+						Title: "Matched Song",
+						Artists: []models.Artist{
+							{Name: "Matched Artist"},
+						},
+					},
+				},
+			}, nil)
+
+		mockSpotifyService.On("GetSongURL", "test-user-id", "Matched Song", "Matched Artist").
+			Return("https://open.spotify.com/track/123", nil)
+
+		mockSpotifyService.On("CreatePlaylistFromSongs",
+			"test-user-id",
+			mock.Anything,
+			mock.Anything,
+			mock.Anything).
+			Return("https://open.spotify.com/playlist/test-playlist", nil)
+
+		// Convert request to JSON
+		jsonRequest, _ := json.Marshal(analysisRequest)
+
+		// Create test HTTP request
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		// Serve the request
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should return OK status")
+
+		// Parse the response
+		var topTracksResponse TopTracksAnalysisResponse
+		err := json.Unmarshal(resp.Body.Bytes(), &topTracksResponse)
+		require.NoError(t, err, "Should parse response JSON")
+
+		// Verify response contents
+		assert.NotEmpty(t, topTracksResponse.Playlist, "Should have a playlist URL")
+		assert.NotEmpty(t, topTracksResponse.Songs, "Should have songs")
+		require.Len(t, topTracksResponse.Seeds, 1, "Should report the top track's match")
+		assert.Equal(t, SeedMatched, topTracksResponse.Seeds[0].Status)
+		assert.Equal(t, []int{7}, topTracksResponse.Seeds[0].SongIDs, "Low confidence candidates should not seed the search")
+
+		// Verify mock expectations
+		mockClientManager.AssertExpectations(t)
+		mockSongRepo.AssertExpectations(t)
+		mockSpotifyService.AssertExpectations(t)
+	})
+
+	t.Run("Excludes_Known_Tracks", func(t *testing.T) {
+		// Arrange
+		mockSongRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockSpotifyService := new(MockSpotifyService)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService, mockGenreTaxonomy, mockExclusionRepo)
+
+		mockClient := new(MockSpotifyClient)
+		mockTracks := &spotify.FullTrackPage{
+			Tracks: []spotify.FullTrack{
+				{
+					SimpleTrack: spotify.SimpleTrack{
+						Name:    "Test Song",
+						Artists: []spotify.SimpleArtist{{Name: "Test Artist"}},
+					},
+				},
+			},
+		}
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", mock.Anything).Return(mockTracks, nil)
+		mockGenreTaxonomy.On("ResolveGenre", "rock").Return(rockGenreGroup)
+		mockSongRepo.On("MatchSongs", models.SongQuery{Title: "Test Song", Artist: "Test Artist"}).
+			Return([]models.SongMatch{{SongID: 7, Title: "Test Song", Artist: "Test Artist", Confidence: 1}}, nil)
+		mockExclusionRepo.On("ListExclusions", "test-user-id").Return([]models.UserExclusion{
+			{ID: 1, UserID: "test-user-id", Kind: models.ExclusionArtist, Artist: "Saved Artist"},
+			{ID: 2, UserID: "test-user-id", Kind: models.ExclusionSong, Artist: "Saved Artist 2", Title: "Saved Song"},
+		}, nil)
+		mockSpotifyService.On("GetUserPlaylistSongs", "test-user-id").
+			Return([]models.SongQuery{{Title: "Playlist Song", Artist: "Playlist Artist"}}, nil)
+
+		seeds := []models.SongQuery{{Title: "Test Song", Artist: "Test Artist", SongIDs: []int{7}}}
+		genreFilter := models.GenreFilter{Genres: []string{"pop", "rock"}, Match: models.GenreMatchAny}
+		mockSongRepo.On("FindSongsByGenreBFS", seeds, genreFilter, models.SearchOptions{
+			MaxDepth:  2,
+			Direction: models.DirectionBoth,
+			Limit:     playlistCandidateLimit,
+			Exclude: models.SearchExclusions{
+				Artists: []string{"Request Artist", "Saved Artist"},
+				Songs: []models.SongQuery{
+					{Title: "Saved Song", Artist: "Saved Artist 2"},
+					{Title: "Test Song", Artist: "Test Artist", SongIDs: []int{7}},
+					{Title: "Playlist Song", Artist: "Playlist Artist"},
+				},
+			},
+		}).Return([]models.SearchResult{}, nil)
+
+		jsonRequest, _ := json.Marshal(TopTracksAnalysisRequest{
+			Genre:              "rock",
+			ExcludeArtists:     []string{"Request Artist"},
+			ExcludeKnownTracks: true,
+		})
+
+		// Act
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockSongRepo.AssertExpectations(t)
+		mockExclusionRepo.AssertExpectations(t)
+		mockSpotifyService.AssertExpectations(t)
+	})
+
+	t.Run("Unmatched_Top_Tracks", func(t *testing.T) {
+		// Arrange
+		mockSongRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockSpotifyService := new(MockSpotifyService)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService, mockGenreTaxonomy, mockExclusionRepo)
+
+		mockClient := new(MockSpotifyClient)
+		mockTracks := &spotify.FullTrackPage{
+			Tracks: []spotify.FullTrack{
+				{
+					SimpleTrack: spotify.SimpleTrack{
+						Name:    "Unknown Song",
+						Artists: []spotify.SimpleArtist{{Name: "Unknown Artist"}},
+					},
+				},
+			},
+		}
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", mock.Anything).Return(mockTracks, nil)
+		mockGenreTaxonomy.On("ResolveGenre", "rock").Return(rockGenreGroup)
+		mockSongRepo.On("MatchSongs", models.SongQuery{Title: "Unknown Song", Artist: "Unknown Artist"}).Return(nil, nil)
+
+		jsonRequest, _ := json.Marshal(TopTracksAnalysisRequest{Genre: "rock"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code, "Should fall back to a curated playlist")
+
+		var topTracksResponse TopTracksAnalysisResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &topTracksResponse))
+		assert.NotEmpty(t, topTracksResponse.Playlist, "Should have a fallback playlist URL")
+		assert.Equal(t, "https://open.spotify.com/playlist/rock", topTracksResponse.Playlist, "Should fall back to the genre group's playlist")
+		assert.Empty(t, topTracksResponse.Songs)
+
+		mockSongRepo.AssertNotCalled(t, "FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything)
+		mockSpotifyService.AssertNotCalled(t, "CreatePlaylistFromSongs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Top_Artist_Seeds", func(t *testing.T) {
+		// Arrange
+		mockSongRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockSpotifyService := new(MockSpotifyService)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService, mockGenreTaxonomy, mockExclusionRepo)
+
+		mockClient := new(MockSpotifyClient)
+		mockTracks := &spotify.FullTrackPage{
+			Tracks: []spotify.FullTrack{
+				{
+					SimpleTrack: spotify.SimpleTrack{
+						Name:    "Test Song",
+						Artists: []spotify.SimpleArtist{{Name: "Test Artist"}},
+					},
+				},
+			},
+		}
+		mockArtists := &spotify.FullArtistPage{
+			Artists: []spotify.FullArtist{
+				{SimpleArtist: spotify.SimpleArtist{Name: "Top Artist"}},
+				{SimpleArtist: spotify.SimpleArtist{Name: "Unknown Artist"}},
+			},
+		}
+		withLimit := func(limit int) interface{} {
+			return mock.MatchedBy(func(opts *spotify.Options) bool {
+				return opts.Limit != nil && *opts.Limit == limit
+			})
+		}
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopTracksOpt", withLimit(analysisTopTracks-analysisTopArtists*artistSeedSongs)).Return(mockTracks, nil)
+		mockClient.On("CurrentUsersTopArtistsOpt", withLimit(analysisTopArtists)).Return(mockArtists, nil)
+		mockGenreTaxonomy.On("ResolveGenre", "rock").Return(rockGenreGroup)
+		mockSongRepo.On("MatchSongs", models.SongQuery{Title: "Test Song", Artist: "Test Artist"}).
+			Return([]models.SongMatch{{SongID: 7, Title: "Test Song", Artist: "Test Artist", Confidence: 1}}, nil)
+		mockSongRepo.On("GetSongIDsByArtist", "Top Artist", artistSeedSongs).Return([]int{11, 12}, nil)
+		mockSongRepo.On("GetSongIDsByArtist", "Unknown Artist", artistSeedSongs).Return(nil, nil)
+		mockExclusionRepo.On("ListExclusions", "test-user-id").Return([]models.UserExclusion{}, nil)
+		mockSpotifyService.On("GetUserPlaylistSongs", "test-user-id").Return([]models.SongQuery{}, nil)
+
+		trackSeed := models.SongQuery{Title: "Test Song", Artist: "Test Artist", SongIDs: []int{7}}
+		seeds := []models.SongQuery{trackSeed, {Artist: "Top Artist", SongIDs: []int{11, 12}}}
+		genreFilter := models.GenreFilter{Genres: []string{"pop", "rock"}, Match: models.GenreMatchAny}
+		mockSongRepo.On("FindSongsByGenreBFS", seeds, genreFilter, models.SearchOptions{
+			MaxDepth:  2,
+			Direction: models.DirectionBoth,
+			Limit:     playlistCandidateLimit,
+			Exclude:   models.SearchExclusions{Songs: []models.SongQuery{trackSeed}},
+		}).Return([]models.SearchResult{
+			{SourceSong: models.SongNode{ID: 12}, MatchedSong: models.SongNode{Title: "Matched Song", Artists: []models.Artist{{Name: "Matched Artist"}}}},
+		}, nil)
+		mockSpotifyService.On("GetSongURL", "test-user-id", "Matched Song", "Matched Artist").Return("", nil)
+
+		jsonRequest, _ := json.Marshal(TopTracksAnalysisRequest{
+			Genre:              "rock",
+			SeedSource:         "both",
+			ExcludeKnownTracks: true,
+		})
+
+		// Act
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var topTracksResponse TopTracksAnalysisResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &topTracksResponse))
+		require.Len(t, topTracksResponse.Seeds, 3, "Should report the top track and both top artists")
+		assert.Equal(t, "Top Artist", topTracksResponse.Seeds[1].Artist)
+		assert.Empty(t, topTracksResponse.Seeds[1].Title, "Artist seeds have no title")
+		assert.Equal(t, SeedMatched, topTracksResponse.Seeds[1].Status)
+		assert.Equal(t, 1, topTracksResponse.Seeds[1].Results)
+		assert.Equal(t, SeedNotFound, topTracksResponse.Seeds[2].Status)
+		mockClient.AssertExpectations(t)
+		mockSongRepo.AssertExpectations(t)
+	})
+
+	t.Run("Top_Artists_Only", func(t *testing.T) {
+		// Arrange
+		mockSongRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockSpotifyService := new(MockSpotifyService)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService, mockGenreTaxonomy, mockExclusionRepo)
+
+		mockClient := new(MockSpotifyClient)
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopArtistsOpt", mock.Anything).Return(&spotify.FullArtistPage{}, nil)
+
+		jsonRequest, _ := json.Marshal(TopTracksAnalysisRequest{Genre: "rock", SeedSource: "artists"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Should return Not Found without top artists")
+		mockClient.AssertNotCalled(t, "CurrentUsersTopTracksOpt", mock.Anything)
+	})
+
+	t.Run("Artist_Songs_Over_Budget", func(t *testing.T) {
+		// Arrange
+		mockSongRepo := new(MockSongRepository)
+		mockClientManager := new(MockClientManager)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, new(MockSpotifyService), mockGenreTaxonomy, new(MockExclusionRepository))
+
+		mockClient := new(MockSpotifyClient)
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+		mockClient.On("CurrentUsersTopArtistsOpt", mock.Anything).Return(&spotify.FullArtistPage{
+			Artists: []spotify.FullArtist{
+				{SimpleArtist: spotify.SimpleArtist{Name: "Artist 1"}},
+				{SimpleArtist: spotify.SimpleArtist{Name: "Artist 2"}},
+			},
+		}, nil)
+		mockGenreTaxonomy.On("ResolveGenre", "rock").Return(rockGenreGroup)
+		mockSongRepo.On("GetSongIDsByArtist", "Artist 1", artistSeedSongs).Return([]int{1, 2, 3}, nil)
+		mockSongRepo.On("GetSongIDsByArtist", "Artist 2", artistSeedSongs).Return([]int{4, 5, 6}, nil)
+
+		jsonRequest, _ := json.Marshal(TopTracksAnalysisRequest{Genre: "rock", SeedSource: "artists"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Should count every song a top artist seeds")
+		assert.Contains(t, resp.Body.String(), "6 seed songs")
+		mockSongRepo.AssertNotCalled(t, "FindSongsByGenreBFS", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid_Seed_Source", func(t *testing.T) {
+		// Arrange
+		mockClientManager := new(MockClientManager)
+		mockClient := new(MockSpotifyClient)
+		r := setupAnalyzeSongsTest(new(MockSongRepository), mockClientManager, new(MockSpotifyService), new(MockGenreTaxonomyService), new(MockExclusionRepository))
+
+		mockClientManager.On("GetClient", "test-user-id").Return(mockClient, true)
+
+		jsonRequest, _ := json.Marshal(TopTracksAnalysisRequest{Genre: "rock", SeedSource: "albums"})
+
+		// Act
+		req := httptest.NewRequest("POST", "/toptracks-analysis", bytes.NewBuffer(jsonRequest))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Should return Bad Request status")
+		assert.Contains(t, resp.Body.String(), "seedSource")
 	})
 
 	t.Run("Missing_Genre", func(t *testing.T) {
@@ -304,7 +1643,9 @@ func TestAnalyzeSongsGivenGenre(t *testing.T) {
 		mockSongRepo := new(MockSongRepository)
 		mockClientManager := new(MockClientManager)
 		mockSpotifyService := new(MockSpotifyService)
-		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService)
+		mockGenreTaxonomy := new(MockGenreTaxonomyService)
+		mockExclusionRepo := new(MockExclusionRepository)
+		r := setupAnalyzeSongsTest(mockSongRepo, mockClientManager, mockSpotifyService, mockGenreTaxonomy, mockExclusionRepo)
 
 		// Mock the GetClient method to return a mock client
 		mockClient := new(MockSpotifyClient)
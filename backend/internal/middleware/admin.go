@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware only lets through users in adminUserIDs. It must run after
+// AuthMiddleware, which sets the userID.
+func AdminMiddleware(adminUserIDs []string) gin.HandlerFunc {
+	admins := make(map[string]struct{}, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = struct{}{}
+	}
+
+	return func(ctx *gin.Context) {
+		userID, exists := ctx.Get("userID")
+		if !exists {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			ctx.Abort()
+			return
+		}
+
+		id, _ := userID.(string)
+		if _, isAdmin := admins[id]; !isAdmin {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAdminTest(userID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	r.Use(AdminMiddleware([]string{"admin-id"}))
+	r.GET("/admin", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	return r
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		userID string
+		status int
+	}{
+		"Admin_User":   {userID: "admin-id", status: http.StatusOK},
+		"Regular_User": {userID: "user-id", status: http.StatusForbidden},
+		"No_User":      {userID: "", status: http.StatusUnauthorized},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			r := setupAdminTest(tc.userID)
+
+			// Act
+			req := httptest.NewRequest("GET", "/admin", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, tc.status, resp.Code)
+		})
+	}
+}
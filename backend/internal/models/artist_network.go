@@ -0,0 +1,37 @@
+package models
+
+// ArtistNetworkOptions tunes an artist sampling network lookup
+type ArtistNetworkOptions struct {
+	// Depth is how many artist hops to expand from the starting artist
+	Depth int
+	// MainArtistsOnly ignores featured credits on both sides of a sample
+	MainArtistsOnly bool
+	// MaxNodes caps the artists returned, the artist itself included
+	MaxNodes int
+}
+
+// SamplePair is one Sample row with both song titles
+type SamplePair struct {
+	SampledInSongID int
+	SampledInTitle  string
+	OriginalSongID  int
+	OriginalTitle   string
+}
+
+// ArtistEdge says FromArtistID's songs sample ToArtistID's songs. Count is the
+// number of distinct song pairs behind the edge.
+type ArtistEdge struct {
+	FromArtistID int
+	ToArtistID   int
+	Count        int
+	Examples     []SamplePair
+}
+
+// ArtistNetwork is the "who samples whom" graph around an artist
+type ArtistNetwork struct {
+	ArtistID int
+	Artists  map[int]Artist
+	Edges    []ArtistEdge
+	// Truncated is set when MaxNodes left reachable artists out
+	Truncated bool
+}
@@ -0,0 +1,12 @@
+package models
+
+// DiversityOptions tunes how generated playlists are spread across artists
+// and seeds. Zero caps mean no cap.
+type DiversityOptions struct {
+	// MaxPerArtist caps the tracks sharing a main artist
+	MaxPerArtist int
+	// MaxPerSeed caps the tracks reached from the same seed song
+	MaxPerSeed int
+	// Lambda trades score (1) against variety (0) when re-ranking
+	Lambda float64
+}
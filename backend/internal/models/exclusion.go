@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExclusionKind says what a user exclusion leaves out of search results
+type ExclusionKind string
+
+const (
+	// ExclusionArtist leaves out every song by an artist
+	ExclusionArtist ExclusionKind = "artist"
+	// ExclusionSong leaves out one song
+	ExclusionSong ExclusionKind = "song"
+)
+
+// ParseExclusionKind validates an exclusion kind from a request.
+func ParseExclusionKind(value string) (ExclusionKind, error) {
+	switch kind := ExclusionKind(strings.ToLower(strings.TrimSpace(value))); kind {
+	case ExclusionArtist, ExclusionSong:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("kind must be one of %s or %s", ExclusionArtist, ExclusionSong)
+	}
+}
+
+// UserExclusion is an artist or song a user never wants in generated playlists.
+// Users are Spotify or non-Spotify users, so UserID is not a foreign key.
+type UserExclusion struct {
+	ID     uint          `gorm:"primaryKey" json:"id"`
+	UserID string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_exclusion" json:"-"`
+	Kind   ExclusionKind `gorm:"type:varchar(16);not null;uniqueIndex:idx_user_exclusion" json:"kind"`
+	Artist string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_exclusion" json:"artist"`
+	// Title is only set for song exclusions
+	Title     string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_user_exclusion" json:"title,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchExclusions lists songs a genre search must not return as matches.
+// Excluded songs can still link other songs together.
+type SearchExclusions struct {
+	// Artists drops every song by one of these artists
+	Artists []string
+	// Songs drops these songs, by SongIDs when set and by title and artist
+	// otherwise
+	Songs []SongQuery
+}
+
+// IsEmpty reports whether nothing is excluded
+func (e SearchExclusions) IsEmpty() bool {
+	return len(e.Artists) == 0 && len(e.Songs) == 0
+}
+
+// Add merges a saved user exclusion in
+func (e *SearchExclusions) Add(exclusion UserExclusion) {
+	switch exclusion.Kind {
+	case ExclusionArtist:
+		e.Artists = append(e.Artists, exclusion.Artist)
+	case ExclusionSong:
+		e.Songs = append(e.Songs, SongQuery{Title: exclusion.Title, Artist: exclusion.Artist})
+	}
+}
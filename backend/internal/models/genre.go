@@ -0,0 +1,70 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// GenreGroup is one entry of the genre taxonomy: a set of samples DB genres
+// searched together, plus the fallback playlists and cover images used for it
+type GenreGroup struct {
+	// ID is the slug clients send, e.g. "hip-hop"
+	ID       string `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	Name     string `gorm:"not null" json:"name"`
+	Position int    `gorm:"default:0" json:"position"`
+
+	Genres      []GenreGroupGenre      `gorm:"foreignKey:GroupID" json:"genres"`
+	Playlists   []GenreGroupPlaylist   `gorm:"foreignKey:GroupID" json:"playlists"`
+	CoverImages []GenreGroupCoverImage `gorm:"foreignKey:GroupID" json:"coverImages"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GenreGroupGenre is a genre name that belongs to a group. A name can only
+// belong to one group.
+type GenreGroupGenre struct {
+	ID      uint   `gorm:"primaryKey" json:"-"`
+	GroupID string `gorm:"type:varchar(64);index;not null" json:"-"`
+	Name    string `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	// Synonym names resolve to the group but are not searched for, because the
+	// samples DB has no genre by that name
+	Synonym bool `gorm:"default:false" json:"synonym"`
+}
+
+// GenreGroupPlaylist is a Spotify playlist offered when a search finds nothing
+type GenreGroupPlaylist struct {
+	ID      uint   `gorm:"primaryKey" json:"-"`
+	GroupID string `gorm:"type:varchar(64);index;not null" json:"-"`
+	URL     string `gorm:"not null" json:"url"`
+}
+
+// GenreGroupCoverImage is a cover image file for generated playlists
+type GenreGroupCoverImage struct {
+	ID      uint   `gorm:"primaryKey" json:"-"`
+	GroupID string `gorm:"type:varchar(64);index;not null" json:"-"`
+	Image   string `gorm:"not null" json:"image"`
+}
+
+// SearchGenres returns the lowercased samples DB genres of the group, sorted
+func (g GenreGroup) SearchGenres() []string {
+	var genres []string
+	for _, genre := range g.Genres {
+		if !genre.Synonym {
+			genres = append(genres, strings.ToLower(genre.Name))
+		}
+	}
+	sort.Strings(genres)
+	return genres
+}
+
+// Names returns every lowercased name the group is known by: its ID, its
+// display name, its genres and their synonyms
+func (g GenreGroup) Names() []string {
+	names := []string{strings.ToLower(g.ID), strings.ToLower(g.Name)}
+	for _, genre := range g.Genres {
+		names = append(names, strings.ToLower(genre.Name))
+	}
+	return names
+}
@@ -0,0 +1,32 @@
+package models
+
+// GenreSetCount is how many songs are tagged with exactly Genres
+type GenreSetCount struct {
+	// Genres are lowercased and sorted, empty for songs without a genre
+	Genres []string
+	Songs  int
+}
+
+// GenreProfileLevel groups the songs a search first reaches Depth hops away
+// from its seeds by the genres they are tagged with
+type GenreProfileLevel struct {
+	Depth     int
+	GenreSets []GenreSetCount
+}
+
+// Songs returns how many songs are first reached at the level's depth
+func (l GenreProfileLevel) Songs() int {
+	total := 0
+	for _, set := range l.GenreSets {
+		total += set.Songs
+	}
+	return total
+}
+
+// GenreProfile counts the songs a search reaches, level by level
+type GenreProfile struct {
+	// Levels are shallowest first, leaving out depths reaching no counted song
+	Levels []GenreProfileLevel
+	// Truncated is set when SearchOptions.MaxRows left reachable songs out
+	Truncated bool
+}
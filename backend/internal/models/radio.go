@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// RadioOptions tunes the random walk behind sample radio sessions
+type RadioOptions struct {
+	// RestartProbability is the chance each step jumps back to a seed song
+	RestartProbability float64
+	// GenreBias multiplies the odds of stepping to a song of the target genre
+	GenreBias float64
+	// RecentWindow is how many played songs a session steers away from
+	RecentWindow int
+	// SessionTTL drops sessions left idle for longer
+	SessionTTL time.Duration
+}
+
+// RadioTrack is a song a radio session played and the hop that led to it
+type RadioTrack struct {
+	Song SongNode
+	// FromSongID is the song the walk stepped from
+	FromSongID int
+	// Direction is DirectionAncestors when FromSongID samples Song and
+	// DirectionDescendants when Song samples FromSongID
+	Direction TraversalDirection
+	// Restarted is set when the walk jumped back to a seed before this hop
+	Restarted bool
+}
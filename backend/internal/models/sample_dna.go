@@ -0,0 +1,73 @@
+package models
+
+// TopTrack is one of a user's Spotify top tracks, matched to the samples DB
+type TopTrack struct {
+	Title  string
+	Artist string
+	// TimeRanges are the Spotify time ranges ("short", "medium", "long") the
+	// track is a top track in
+	TimeRanges []string
+	// SongIDs are the matched samples DB songs, empty when nothing matched
+	SongIDs []int
+}
+
+// SampleDNAOptions tunes a sample DNA report
+type SampleDNAOptions struct {
+	// Depth is how many sample hops back from a top track ancestors are looked for
+	Depth int
+	// Entries caps the entries of each section of the report
+	Entries int
+	// Examples caps the example paths of each entry
+	Examples int
+	// MaxNodes caps the ancestors walked from each matched song
+	MaxNodes int
+}
+
+// SampleDNAPath is a sample chain from one of the user's top tracks back to
+// one of its ancestors
+type SampleDNAPath struct {
+	// Songs start with the top track, every song samples the next one
+	Songs      []SongNode
+	TimeRanges []string
+}
+
+// SampleDNAEntry is one song, artist, genre or era the user's top tracks draw
+// from. Tracks is how many top tracks have it among their ancestors.
+type SampleDNAEntry struct {
+	Tracks   int
+	Examples []SampleDNAPath
+}
+
+type SampleDNASong struct {
+	Song SongNode
+	SampleDNAEntry
+}
+
+type SampleDNAArtist struct {
+	Artist Artist
+	SampleDNAEntry
+}
+
+type SampleDNAGenre struct {
+	Genre string
+	SampleDNAEntry
+}
+
+// SampleDNAEra is a decade, e.g. 1970
+type SampleDNAEra struct {
+	Decade int
+	SampleDNAEntry
+}
+
+// SampleDNA is what a user's top tracks sample, directly or through other
+// samples
+type SampleDNA struct {
+	// Tracks is how many top tracks the report was built from and Matched how
+	// many of them the samples DB knows
+	Tracks    int
+	Matched   int
+	Ancestors []SampleDNASong
+	Artists   []SampleDNAArtist
+	Genres    []SampleDNAGenre
+	Eras      []SampleDNAEra
+}
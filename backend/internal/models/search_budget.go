@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSearchOverBudget marks searches a SearchBudget rejects
+var ErrSearchOverBudget = errors.New("search exceeds the query budget")
+
+// sampleFanout is roughly how many songs one Sample hop reaches in a single
+// direction. EstimateSearchCost uses it to size a search before running it.
+const sampleFanout = 3
+
+// SearchBudget caps what one sample-graph search may cost so a single request
+// cannot pin the samples DB. Zero values mean no cap.
+type SearchBudget struct {
+	// MaxDepth caps SearchOptions.MaxDepth
+	MaxDepth int
+	// MaxSeeds caps how many seed songs a search starts from
+	MaxSeeds int
+	// MaxRows caps SearchOptions.MaxRows
+	MaxRows int
+	// MaxCost caps EstimateSearchCost
+	MaxCost float64
+	// Timeout bounds how long a search may run
+	Timeout time.Duration
+}
+
+// EstimateSearchCost estimates how many paths a genre search walks: every seed
+// reaches sampleFanout songs per direction and hop, for MaxDepth hops, and
+// each song may be walked MaxPaths times.
+func EstimateSearchCost(seeds int, opts SearchOptions) float64 {
+	fanout := float64(sampleFanout)
+	if opts.Direction == DirectionBoth || opts.Direction == "" {
+		fanout *= 2
+	}
+
+	perSeed, level := 0.0, 1.0
+	for depth := 0; depth < opts.MaxDepth; depth++ {
+		level *= fanout
+		perSeed += level
+	}
+	return float64(seeds) * perSeed * float64(opts.PathsPerMatch())
+}
+
+// Check returns an ErrSearchOverBudget error when a search from seeds seed
+// songs with opts goes over one of the caps.
+func (b SearchBudget) Check(seeds int, opts SearchOptions) error {
+	if b.MaxDepth > 0 && opts.MaxDepth > b.MaxDepth {
+		return fmt.Errorf("%w: maxDepth %d is above the limit of %d", ErrSearchOverBudget, opts.MaxDepth, b.MaxDepth)
+	}
+	if b.MaxSeeds > 0 && seeds > b.MaxSeeds {
+		return fmt.Errorf("%w: %d seed songs is above the limit of %d", ErrSearchOverBudget, seeds, b.MaxSeeds)
+	}
+	if cost := EstimateSearchCost(seeds, opts); b.MaxCost > 0 && cost > b.MaxCost {
+		return fmt.Errorf("%w: estimated cost %.0f is above the limit of %.0f, lower maxDepth, maxPaths or the number of songs", ErrSearchOverBudget, cost, b.MaxCost)
+	}
+	return nil
+}
+
+// Apply caps the result rows of opts
+func (b SearchBudget) Apply(opts SearchOptions) SearchOptions {
+	if b.MaxRows > 0 && (opts.MaxRows <= 0 || opts.MaxRows > b.MaxRows) {
+		opts.MaxRows = b.MaxRows
+	}
+	return opts
+}
+
+// Context returns parent bounded by Timeout
+func (b SearchBudget) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if b.Timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, b.Timeout)
+}
@@ -0,0 +1,14 @@
+package models
+
+// SearchCacheStats counts how a genre search cache has been used
+type SearchCacheStats struct {
+	Hits   int64
+	Misses int64
+	// Evictions counts searches dropped to stay within MaxEntries and
+	// Expirations searches dropped after their TTL
+	Evictions     int64
+	Expirations   int64
+	Invalidations int64
+	Entries       int
+	MaxEntries    int
+}
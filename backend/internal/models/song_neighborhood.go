@@ -0,0 +1,29 @@
+package models
+
+// NeighborhoodOptions tunes a song neighborhood (ego network) lookup
+type NeighborhoodOptions struct {
+	// Radius is how many sample hops to expand from the song
+	Radius int
+	// Direction selects which Sample edges are followed
+	Direction TraversalDirection
+	// MaxNodes caps the songs returned, the song itself included
+	MaxNodes int
+}
+
+// SampleEdge says SampledInID samples OriginalID
+type SampleEdge struct {
+	SampledInID int
+	OriginalID  int
+}
+
+// SongNeighborhood is every song within a few sample hops of a song, whatever
+// its genres
+type SongNeighborhood struct {
+	SongID int
+	// Songs are in BFS order, the song itself first
+	Songs []SongNode
+	// Edges are the Sample edges walked between Songs
+	Edges []SampleEdge
+	// Truncated is set when MaxNodes left reachable songs out
+	Truncated bool
+}
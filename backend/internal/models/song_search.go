@@ -1,8 +1,28 @@
 package models
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type SongQuery struct {
 	Title  string
 	Artist string
+	// SongIDs pins the query to already matched songs. When set, searches start
+	// from these songs instead of an exact title and artist lookup.
+	SongIDs []int
+}
+
+// SongMatch is a candidate song for a SongQuery. Confidence ranges from 0 to 1,
+// where 1 means the title and artist matched exactly.
+type SongMatch struct {
+	SongID     int
+	Title      string
+	Artist     string
+	Confidence float64
 }
 
 type Artist struct {
@@ -16,6 +36,265 @@ type SongNode struct {
 	Title   string
 	Artists []Artist
 	Genres  []string
+	// ReleaseYear is 0 when the samples DB does not know it
+	ReleaseYear int
+}
+
+// SongSearchQuery looks songs up in the samples catalog by partial title or
+// artist name.
+type SongSearchQuery struct {
+	// Query is matched against song titles and artist names
+	Query string
+	// Artist narrows the results to songs by a matching artist
+	Artist string
+	Limit  int
+}
+
+// SampleLineage holds the songs one Sample hop away from a song and its
+// duplicates, split by direction.
+type SampleLineage struct {
+	// SamplesUsed are the songs it samples
+	SamplesUsed []int
+	// SampledIn are the songs that sample it
+	SampledIn []int
+}
+
+// TraversalDirection selects which Sample edges a graph search follows.
+type TraversalDirection string
+
+const (
+	// DirectionAncestors follows original_song_id: the songs a song sampled.
+	DirectionAncestors TraversalDirection = "ancestors"
+	// DirectionDescendants follows sampled_in_song_id: the songs that sampled a song.
+	DirectionDescendants TraversalDirection = "descendants"
+	// DirectionBoth follows Sample edges either way.
+	DirectionBoth TraversalDirection = "both"
+)
+
+// ParseTraversalDirection validates a direction coming from a request. An empty
+// value defaults to DirectionBoth.
+func ParseTraversalDirection(value string) (TraversalDirection, error) {
+	switch TraversalDirection(value) {
+	case "", DirectionBoth:
+		return DirectionBoth, nil
+	case DirectionAncestors, DirectionDescendants:
+		return TraversalDirection(value), nil
+	}
+	return "", fmt.Errorf("direction must be one of %s, %s or %s", DirectionAncestors, DirectionDescendants, DirectionBoth)
+}
+
+// GenreMatch says whether a song needs any or all of a GenreFilter's genres.
+type GenreMatch string
+
+const (
+	GenreMatchAny GenreMatch = "any"
+	GenreMatchAll GenreMatch = "all"
+)
+
+// ParseGenreMatch validates a genre match mode coming from a request. An empty
+// value defaults to GenreMatchAny.
+func ParseGenreMatch(value string) (GenreMatch, error) {
+	switch GenreMatch(value) {
+	case "", GenreMatchAny:
+		return GenreMatchAny, nil
+	case GenreMatchAll:
+		return GenreMatchAll, nil
+	}
+	return "", fmt.Errorf("genreMatch must be one of %s or %s", GenreMatchAny, GenreMatchAll)
+}
+
+// SeedSource says where an analysis of a user's listening takes its seed songs
+// from: their top tracks, the songs of their top artists, or both.
+type SeedSource string
+
+const (
+	SeedSourceTracks  SeedSource = "tracks"
+	SeedSourceArtists SeedSource = "artists"
+	SeedSourceBoth    SeedSource = "both"
+)
+
+// ParseSeedSource validates a seed source coming from a request. An empty
+// value defaults to SeedSourceTracks.
+func ParseSeedSource(value string) (SeedSource, error) {
+	switch SeedSource(value) {
+	case "", SeedSourceTracks:
+		return SeedSourceTracks, nil
+	case SeedSourceArtists, SeedSourceBoth:
+		return SeedSource(value), nil
+	}
+	return "", fmt.Errorf("seedSource must be one of %s, %s or %s", SeedSourceTracks, SeedSourceArtists, SeedSourceBoth)
+}
+
+// GenreFilter selects the songs a search is looking for. Genre names compare
+// case-insensitively, like the samples DB collation.
+type GenreFilter struct {
+	Genres []string
+	Match  GenreMatch
+	// Exclude rejects songs tagged with any of these genres
+	Exclude []string
+}
+
+// NewGenreFilter targets songs with any of genres.
+func NewGenreFilter(genres ...string) GenreFilter {
+	return GenreFilter{Genres: genres, Match: GenreMatchAny}
+}
+
+// TargetGenres returns the distinct, lowercased target genres.
+func (f GenreFilter) TargetGenres() []string {
+	return distinctGenres(f.Genres)
+}
+
+// ExcludedGenres returns the distinct, lowercased excluded genres.
+func (f GenreFilter) ExcludedGenres() []string {
+	return distinctGenres(f.Exclude)
+}
+
+// Matches reports whether a song tagged with genres passes the filter.
+func (f GenreFilter) Matches(genres []string) bool {
+	tagged := make(map[string]struct{}, len(genres))
+	for _, genre := range genres {
+		tagged[strings.ToLower(strings.TrimSpace(genre))] = struct{}{}
+	}
+
+	for _, genre := range f.ExcludedGenres() {
+		if _, ok := tagged[genre]; ok {
+			return false
+		}
+	}
+
+	targets := f.TargetGenres()
+	if len(targets) == 0 {
+		return false
+	}
+	for _, genre := range targets {
+		_, ok := tagged[genre]
+		if ok && f.Match != GenreMatchAll {
+			return true
+		}
+		if !ok && f.Match == GenreMatchAll {
+			return false
+		}
+	}
+	return f.Match == GenreMatchAll
+}
+
+func distinctGenres(genres []string) []string {
+	seen := make(map[string]struct{}, len(genres))
+	var distinct []string
+	for _, genre := range genres {
+		genre = strings.ToLower(strings.TrimSpace(genre))
+		if genre == "" {
+			continue
+		}
+		if _, exists := seen[genre]; exists {
+			continue
+		}
+		seen[genre] = struct{}{}
+		distinct = append(distinct, genre)
+	}
+	return distinct
+}
+
+// YearRange bounds the release years a genre search matches. A zero bound is
+// open, and a zero range matches every song.
+type YearRange struct {
+	From int
+	To   int
+}
+
+// eraPattern matches decades written as "1970s", "70s" or "'70s"
+var eraPattern = regexp.MustCompile(`^'?(\d{2}|\d{4})s$`)
+
+// ParseYearRange builds a year range from a request. era, e.g. "1970s" or
+// "70s", sets both bounds; from and to, when set, override them.
+func ParseYearRange(from, to int, era string) (YearRange, error) {
+	var years YearRange
+	if era = strings.ToLower(strings.TrimSpace(era)); era != "" {
+		match := eraPattern.FindStringSubmatch(era)
+		if match == nil {
+			return YearRange{}, fmt.Errorf("era must be a decade like 1970s or 70s")
+		}
+		decade, _ := strconv.Atoi(match[1])
+		if len(match[1]) == 2 {
+			// Two digit decades up to the current one are this century's
+			if decade <= time.Now().Year()%100 {
+				decade += 2000
+			} else {
+				decade += 1900
+			}
+		}
+		if decade%10 != 0 {
+			return YearRange{}, fmt.Errorf("era must be a decade like 1970s or 70s")
+		}
+		years = YearRange{From: decade, To: decade + 9}
+	}
+
+	if from != 0 {
+		years.From = from
+	}
+	if to != 0 {
+		years.To = to
+	}
+	if years.From < 0 || years.To < 0 {
+		return YearRange{}, fmt.Errorf("years must not be negative")
+	}
+	if years.From != 0 && years.To != 0 && years.From > years.To {
+		return YearRange{}, fmt.Errorf("year range must not end before it starts")
+	}
+	return years, nil
+}
+
+// IsZero reports whether the range matches every song
+func (r YearRange) IsZero() bool {
+	return r.From == 0 && r.To == 0
+}
+
+// Contains reports whether a release year is inside the range. Songs without
+// a known year (0) only match a zero range.
+func (r YearRange) Contains(year int) bool {
+	if r.IsZero() {
+		return true
+	}
+	if year == 0 {
+		return false
+	}
+	return (r.From == 0 || year >= r.From) && (r.To == 0 || year <= r.To)
+}
+
+// IsChronological reports whether a sample edge has its original released no
+// later than the song sampling it. Edges with an unknown year pass.
+func IsChronological(originalYear, sampledInYear int) bool {
+	return originalYear == 0 || sampledInYear == 0 || originalYear <= sampledInYear
+}
+
+// SearchOptions tunes a sample-graph search.
+type SearchOptions struct {
+	MaxDepth  int
+	Direction TraversalDirection
+	// MaxPaths is how many alternative simple paths to return for each
+	// (source, match) pair, shortest first. Values below 1 mean 1.
+	MaxPaths int
+	// Limit caps how many results a genre search returns after ranking. Zero
+	// means no limit.
+	Limit int
+	// Exclude drops matches of a genre search before ranking
+	Exclude SearchExclusions
+	// Years keeps the matches of a genre search released in the range
+	Years YearRange
+	// Chronological makes a genre search only follow samples whose original
+	// is not newer than the song sampling it, skipping inverted years
+	Chronological bool
+	// MaxRows caps the matched paths a genre search reads before ranking,
+	// nearest first, and the songs a genre profile reaches. Zero means no cap.
+	MaxRows int
+}
+
+// PathsPerMatch returns MaxPaths with its default applied.
+func (o SearchOptions) PathsPerMatch() int {
+	if o.MaxPaths < 1 {
+		return 1
+	}
+	return o.MaxPaths
 }
 
 type SearchResult struct {
@@ -23,4 +302,9 @@ type SearchResult struct {
 	MatchedSong SongNode
 	Distance    int
 	Path        []SongNode
+	// HopDirections holds the direction of each hop in Path, so it is one
+	// shorter than Path.
+	HopDirections []TraversalDirection
+	// Score ranks genre search results, higher first. It is between 0 and 1.
+	Score float64
 }
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// maxArtistEdgeExamples bounds the song pairs kept per artist edge
+const maxArtistEdgeExamples = 3
+
+// artistSample is one Sample row seen through the artists of both songs
+type artistSample struct {
+	sampler models.Artist
+	sampled models.Artist
+	pair    models.SamplePair
+}
+
+// artistSampleLoader returns every Sample row where one of artistIDs is
+// credited on either song
+type artistSampleLoader func(ctx context.Context, artistIDs []int, mainArtistsOnly bool) ([]artistSample, error)
+
+// buildArtistNetwork expands the artist network level by level from start,
+// aggregating song-level samples into weighted artist edges. It keeps at most
+// opts.MaxNodes artists and the edges between them. Artists sampling
+// themselves are left out. It stops with ctx's error once ctx is cancelled.
+func buildArtistNetwork(ctx context.Context, load artistSampleLoader, start models.Artist, opts models.ArtistNetworkOptions) (*models.ArtistNetwork, error) {
+	network := &models.ArtistNetwork{
+		ArtistID: start.ID,
+		Artists:  map[int]models.Artist{start.ID: {ID: start.ID, Name: start.Name}},
+	}
+
+	type edgeKey struct {
+		from, to int
+	}
+	edges := make(map[edgeKey]*models.ArtistEdge)
+	seenPairs := make(map[edgeKey]map[models.SamplePair]struct{})
+
+	frontier := []int{start.ID}
+	for depth := 0; depth < opts.Depth && len(frontier) > 0; depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		samples, err := load(ctx, frontier, opts.MainArtistsOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []int
+		for _, sample := range samples {
+			if sample.sampler.ID == sample.sampled.ID {
+				continue
+			}
+
+			var unknown []models.Artist
+			for _, artist := range []models.Artist{sample.sampler, sample.sampled} {
+				if _, known := network.Artists[artist.ID]; !known {
+					unknown = append(unknown, artist)
+				}
+			}
+			if opts.MaxNodes > 0 && len(network.Artists)+len(unknown) > opts.MaxNodes {
+				network.Truncated = true
+				continue
+			}
+			for _, artist := range unknown {
+				network.Artists[artist.ID] = models.Artist{ID: artist.ID, Name: artist.Name}
+				next = append(next, artist.ID)
+			}
+
+			key := edgeKey{sample.sampler.ID, sample.sampled.ID}
+			if seenPairs[key] == nil {
+				seenPairs[key] = make(map[models.SamplePair]struct{})
+			}
+			if _, seen := seenPairs[key][sample.pair]; seen {
+				continue
+			}
+			seenPairs[key][sample.pair] = struct{}{}
+
+			edge, ok := edges[key]
+			if !ok {
+				edge = &models.ArtistEdge{FromArtistID: key.from, ToArtistID: key.to}
+				edges[key] = edge
+			}
+			edge.Count++
+			if len(edge.Examples) < maxArtistEdgeExamples {
+				edge.Examples = append(edge.Examples, sample.pair)
+			}
+		}
+		frontier = next
+	}
+
+	for _, edge := range edges {
+		network.Edges = append(network.Edges, *edge)
+	}
+	sort.Slice(network.Edges, func(i, j int) bool {
+		a, b := network.Edges[i], network.Edges[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.FromArtistID != b.FromArtistID {
+			return a.FromArtistID < b.FromArtistID
+		}
+		return a.ToArtistID < b.ToArtistID
+	})
+
+	return network, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"gorm.io/gorm"
+)
+
+// ExclusionRepository stores the artists and songs users never want in
+// generated playlists
+type ExclusionRepository struct {
+	db *gorm.DB
+}
+
+// NewExclusionRepository creates a new repository instance
+func NewExclusionRepository(db *gorm.DB) *ExclusionRepository {
+	return &ExclusionRepository{db: db}
+}
+
+// ListExclusions returns a user's exclusions, oldest first
+func (r *ExclusionRepository) ListExclusions(userID string) ([]models.UserExclusion, error) {
+	var exclusions []models.UserExclusion
+	result := r.db.Where("user_id = ?", userID).Order("id").Find(&exclusions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error listing exclusions: %v", result.Error)
+	}
+	return exclusions, nil
+}
+
+// AddExclusion saves an exclusion. Adding one the user already has returns the
+// existing row instead of failing.
+func (r *ExclusionRepository) AddExclusion(exclusion *models.UserExclusion) error {
+	exclusion.Artist = strings.TrimSpace(exclusion.Artist)
+	exclusion.Title = strings.TrimSpace(exclusion.Title)
+	if exclusion.Kind != models.ExclusionSong {
+		exclusion.Title = ""
+	}
+
+	result := r.db.
+		Where(models.UserExclusion{
+			UserID: exclusion.UserID,
+			Kind:   exclusion.Kind,
+			Artist: exclusion.Artist,
+			Title:  exclusion.Title,
+		}).
+		FirstOrCreate(exclusion)
+	if result.Error != nil {
+		return fmt.Errorf("error adding exclusion: %v", result.Error)
+	}
+	return nil
+}
+
+// DeleteExclusion deletes one of a user's exclusions. It reports whether the
+// exclusion existed.
+func (r *ExclusionRepository) DeleteExclusion(userID string, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.UserExclusion{})
+	if result.Error != nil {
+		return false, fmt.Errorf("error deleting exclusion: %v", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupExclusionTestDB creates an in-memory SQLite database for testing
+func setupExclusionTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	require.NoError(t, err, "Failed to open in-memory database")
+
+	// Drop any existing tables first
+	err = db.Migrator().DropTable(&models.UserExclusion{})
+	require.NoError(t, err, "Failed to drop existing tables")
+
+	err = db.AutoMigrate(&models.UserExclusion{})
+	require.NoError(t, err, "Failed to migrate exclusion model")
+
+	return db
+}
+
+func TestExclusionRepository(t *testing.T) {
+	db := setupExclusionTestDB(t)
+	repo := NewExclusionRepository(db)
+
+	t.Run("Add_Exclusions", func(t *testing.T) {
+		// Arrange
+		artist := &models.UserExclusion{UserID: "user-1", Kind: models.ExclusionArtist, Artist: " Some Band ", Title: "ignored"}
+		song := &models.UserExclusion{UserID: "user-1", Kind: models.ExclusionSong, Artist: "Some Singer", Title: "Some Song"}
+		other := &models.UserExclusion{UserID: "user-2", Kind: models.ExclusionArtist, Artist: "Some Band"}
+
+		// Act
+		require.NoError(t, repo.AddExclusion(artist))
+		require.NoError(t, repo.AddExclusion(song))
+		require.NoError(t, repo.AddExclusion(other))
+
+		// Assert
+		exclusions, err := repo.ListExclusions("user-1")
+		require.NoError(t, err)
+		require.Len(t, exclusions, 2, "Should only list the user's own exclusions")
+		assert.Equal(t, "Some Band", exclusions[0].Artist, "Names should be trimmed")
+		assert.Empty(t, exclusions[0].Title, "Artist exclusions should not keep a title")
+		assert.Equal(t, "Some Song", exclusions[1].Title)
+	})
+
+	t.Run("Add_Duplicate", func(t *testing.T) {
+		// Arrange
+		duplicate := &models.UserExclusion{UserID: "user-1", Kind: models.ExclusionArtist, Artist: "Some Band"}
+
+		// Act
+		err := repo.AddExclusion(duplicate)
+
+		// Assert
+		require.NoError(t, err, "Adding an existing exclusion should not fail")
+		assert.NotZero(t, duplicate.ID, "Should return the existing exclusion")
+		exclusions, err := repo.ListExclusions("user-1")
+		require.NoError(t, err)
+		assert.Len(t, exclusions, 2)
+	})
+
+	t.Run("Delete_Exclusion", func(t *testing.T) {
+		// Arrange
+		exclusions, err := repo.ListExclusions("user-1")
+		require.NoError(t, err)
+		id := exclusions[0].ID
+
+		// Act
+		deletedByOther, err := repo.DeleteExclusion("user-2", id)
+		require.NoError(t, err)
+		deleted, err := repo.DeleteExclusion("user-1", id)
+		require.NoError(t, err)
+		deletedAgain, err := repo.DeleteExclusion("user-1", id)
+		require.NoError(t, err)
+
+		// Assert
+		assert.False(t, deletedByOther, "Users should not delete each other's exclusions")
+		assert.True(t, deleted)
+		assert.False(t, deletedAgain, "Should report a missing exclusion")
+	})
+}
+
+func TestResultExcluder(t *testing.T) {
+	// Arrange
+	song := func(id int, title, artist string) models.SongNode {
+		return models.SongNode{
+			ID:      id,
+			Title:   title,
+			Artists: []models.Artist{{Name: artist, IsMain: true}},
+		}
+	}
+	songs := []models.SongNode{
+		song(1, "Keep Me", "Kept Artist"),
+		song(2, "Any Song", "The Excluded Band"),
+		song(3, "Known Song - 2011 Remaster", "Known Artist"),
+		song(4, "Known Song", "Other Artist"),
+		song(5, "Pinned Song", "Pinned Artist"),
+	}
+	excluder := newResultExcluder(models.SearchExclusions{
+		Artists: []string{"excluded band"},
+		Songs: []models.SongQuery{
+			{Title: "known song", Artist: "KNOWN ARTIST"},
+			{Title: "Different Title", Artist: "Pinned Artist", SongIDs: []int{5}},
+		},
+	})
+
+	// Act
+	var kept []int
+	for _, s := range songs {
+		if !excluder.excludes(s) {
+			kept = append(kept, s.ID)
+		}
+	}
+
+	// Assert
+	assert.Equal(t, []int{1, 4}, kept, "Should drop excluded artists, songs and song IDs only")
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// walkGenreProfile expands the seeds one level at a time like walkGenreSearch,
+// up to opts.MaxDepth hops. As a profile counts songs rather than paths, it
+// visits every song once, at the depth it is first reached. It returns the
+// songs first reached at each depth, depth 1 first, and whether it stopped
+// after reaching opts.MaxRows songs.
+func walkGenreProfile(ctx context.Context, load sampleHopLoader, seeds []int, opts models.SearchOptions) ([][]int, bool, error) {
+	visited := make(map[int]struct{}, len(seeds))
+	frontier := make([]int, 0, len(seeds))
+	for _, id := range seeds {
+		if _, seen := visited[id]; seen {
+			continue
+		}
+		visited[id] = struct{}{}
+		frontier = append(frontier, id)
+	}
+
+	var levels [][]int
+	reached := 0
+	for depth := 1; depth <= opts.MaxDepth && len(frontier) > 0; depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		hops, err := load(ctx, frontier, opts.Direction)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var next []int
+		for _, id := range frontier {
+			for _, hop := range hops[id] {
+				if _, seen := visited[hop.to]; seen {
+					continue
+				}
+				if opts.MaxRows > 0 && reached >= opts.MaxRows {
+					return append(levels, next), true, nil
+				}
+				visited[hop.to] = struct{}{}
+				next = append(next, hop.to)
+				reached++
+			}
+		}
+		levels = append(levels, next)
+		frontier = next
+	}
+	return levels, false, nil
+}
+
+// genreSetKey lowercases, dedupes and sorts genres into the key a genre
+// profile counts songs under
+func genreSetKey(genres []string) string {
+	return strings.Join(distinctSortedGenres(genres), ",")
+}
+
+func distinctSortedGenres(genres []string) []string {
+	seen := make(map[string]struct{}, len(genres))
+	var distinct []string
+	for _, genre := range genres {
+		genre = strings.ToLower(strings.TrimSpace(genre))
+		if genre == "" {
+			continue
+		}
+		if _, exists := seen[genre]; exists {
+			continue
+		}
+		seen[genre] = struct{}{}
+		distinct = append(distinct, genre)
+	}
+	sort.Strings(distinct)
+	return distinct
+}
+
+// genreProfile turns song counts by depth and genreSetKey into profile levels,
+// shallowest first. Within a level the most common genre sets come first.
+func genreProfile(counts map[int]map[string]int) []models.GenreProfileLevel {
+	levels := make([]models.GenreProfileLevel, 0, len(counts))
+	for depth, sets := range counts {
+		level := models.GenreProfileLevel{Depth: depth}
+		for key, songs := range sets {
+			level.GenreSets = append(level.GenreSets, models.GenreSetCount{
+				Genres: distinctSortedGenres(strings.Split(key, ",")),
+				Songs:  songs,
+			})
+		}
+		sort.Slice(level.GenreSets, func(i, j int) bool {
+			a, b := level.GenreSets[i], level.GenreSets[j]
+			if a.Songs != b.Songs {
+				return a.Songs > b.Songs
+			}
+			return strings.Join(a.Genres, ",") < strings.Join(b.Genres, ",")
+		})
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Depth < levels[j].Depth
+	})
+	return levels
+}
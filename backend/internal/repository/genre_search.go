@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// genreSearchWalk is a chain of songs from a genre search seed (first)
+type genreSearchWalk struct {
+	source int
+	path   []int
+	hops   []models.TraversalDirection
+}
+
+// genreSearchMatcher returns which of ids a genre search is looking for
+type genreSearchMatcher func(ctx context.Context, ids []int) (map[int]bool, error)
+
+// walkGenreSearch runs the genre search traversal both SongRepositoryInterface
+// implementations share. It expands the seeds one level at a time, up to
+// opts.MaxDepth hops. A walk never re-enters a song on its own path, and at
+// most opts.PathsPerMatch() walks from a seed reach each song, so every level
+// grows with the songs reached rather than with the paths to them. It returns
+// the walks ending on a matching song, nearest first, at most opts.MaxRows of
+// them, or ctx's error once ctx is cancelled.
+func walkGenreSearch(ctx context.Context, load sampleHopLoader, seeds []int, opts models.SearchOptions, matches genreSearchMatcher) ([]genreSearchWalk, error) {
+	type visit struct {
+		source, song int
+	}
+
+	maxPaths := opts.PathsPerMatch()
+	visits := make(map[visit]int)
+	var frontier []genreSearchWalk
+	for _, id := range seeds {
+		if _, seeded := visits[visit{id, id}]; seeded {
+			continue
+		}
+		visits[visit{id, id}] = maxPaths
+		frontier = append(frontier, genreSearchWalk{source: id, path: []int{id}})
+	}
+
+	var found []genreSearchWalk
+	for distance := 0; len(frontier) > 0; distance++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		songIDs := make([]int, len(frontier))
+		for i, w := range frontier {
+			songIDs[i] = w.path[len(w.path)-1]
+		}
+		matched, err := matches(ctx, uniqueSongIDs(songIDs))
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range frontier {
+			if !matched[w.path[len(w.path)-1]] {
+				continue
+			}
+			found = append(found, w)
+			if opts.MaxRows > 0 && len(found) >= opts.MaxRows {
+				return found, nil
+			}
+		}
+
+		if distance >= opts.MaxDepth {
+			break
+		}
+
+		hops, err := load(ctx, uniqueSongIDs(songIDs), opts.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []genreSearchWalk
+		for _, w := range frontier {
+			for _, hop := range hops[w.path[len(w.path)-1]] {
+				key := visit{w.source, hop.to}
+				if visits[key] >= maxPaths || containsSong(w.path, hop.to) {
+					continue
+				}
+				visits[key]++
+
+				path := make([]int, len(w.path), len(w.path)+1)
+				copy(path, w.path)
+				walkHops := make([]models.TraversalDirection, len(w.hops), len(w.hops)+1)
+				copy(walkHops, w.hops)
+				next = append(next, genreSearchWalk{
+					source: w.source,
+					path:   append(path, hop.to),
+					hops:   append(walkHops, hop.direction),
+				})
+			}
+		}
+		frontier = next
+	}
+
+	return found, nil
+}
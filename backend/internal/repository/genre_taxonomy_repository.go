@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"gorm.io/gorm"
+)
+
+// GenreTaxonomyRepository stores the genre groups in the app DB
+type GenreTaxonomyRepository struct {
+	db *gorm.DB
+}
+
+// NewGenreTaxonomyRepository creates a new repository instance
+func NewGenreTaxonomyRepository(db *gorm.DB) *GenreTaxonomyRepository {
+	return &GenreTaxonomyRepository{db: db}
+}
+
+func (r *GenreTaxonomyRepository) withChildren() *gorm.DB {
+	return r.db.
+		Preload("Genres", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Preload("Playlists", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("CoverImages", func(db *gorm.DB) *gorm.DB { return db.Order("image") })
+}
+
+// ListGroups returns every genre group in display order
+func (r *GenreTaxonomyRepository) ListGroups() ([]models.GenreGroup, error) {
+	var groups []models.GenreGroup
+	result := r.withChildren().Order("position, id").Find(&groups)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error listing genre groups: %v", result.Error)
+	}
+	return groups, nil
+}
+
+// GetGroup retrieves a genre group by ID, or nil if there is none
+func (r *GenreTaxonomyRepository) GetGroup(id string) (*models.GenreGroup, error) {
+	var group models.GenreGroup
+	result := r.withChildren().Where("id = ?", id).First(&group)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting genre group: %v", result.Error)
+	}
+	return &group, nil
+}
+
+// SaveGroup creates a genre group or replaces an existing one, genres,
+// playlists and cover images included
+func (r *GenreTaxonomyRepository) SaveGroup(group *models.GenreGroup) error {
+	// Children are always re-created, so drop the IDs of any loaded ones
+	for i := range group.Genres {
+		group.Genres[i].ID = 0
+	}
+	for i := range group.Playlists {
+		group.Playlists[i].ID = 0
+	}
+	for i := range group.CoverImages {
+		group.CoverImages[i].ID = 0
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteGenreGroupChildren(tx, group.ID); err != nil {
+			return err
+		}
+		if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(group).Error; err != nil {
+			return fmt.Errorf("error saving genre group: %v", err)
+		}
+		return nil
+	})
+}
+
+// DeleteGroup deletes a genre group with its genres, playlists and cover images
+func (r *GenreTaxonomyRepository) DeleteGroup(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteGenreGroupChildren(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&models.GenreGroup{}).Error; err != nil {
+			return fmt.Errorf("error deleting genre group: %v", err)
+		}
+		return nil
+	})
+}
+
+// SeedDefaults stores the default taxonomy if the app DB has no genre groups yet
+func (r *GenreTaxonomyRepository) SeedDefaults() error {
+	var count int64
+	if err := r.db.Model(&models.GenreGroup{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("error counting genre groups: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, group := range defaultGenreGroups() {
+		if err := r.SaveGroup(&group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteGenreGroupChildren(tx *gorm.DB, groupID string) error {
+	for _, child := range []interface{}{&models.GenreGroupGenre{}, &models.GenreGroupPlaylist{}, &models.GenreGroupCoverImage{}} {
+		if err := tx.Where("group_id = ?", groupID).Delete(child).Error; err != nil {
+			return fmt.Errorf("error deleting genre group children: %v", err)
+		}
+	}
+	return nil
+}
+
+// defaultGenreGroups is the taxonomy the app shipped with before it moved into
+// the app DB
+func defaultGenreGroups() []models.GenreGroup {
+	type seed struct {
+		id, name  string
+		genres    []string
+		synonyms  []string
+		playlists []string
+		covers    int
+	}
+
+	seeds := []seed{
+		{
+			id: "hip-hop", name: "Hip-Hop / Rap / R&B",
+			genres:   []string{"hip-hop", "rap", "r&b"},
+			synonyms: []string{"hip hop", "rnb"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/37i9dQZF1DXbkfWVLd8wE3?si=zqZ10XC9S2a095CXo8vW6Q",
+				"https://open.spotify.com/playlist/0h9Gaqt2sNJ8M5aMV3h9BO?si=eB4jwKD0RFKXFz9WUoqXkA",
+				"https://open.spotify.com/playlist/37i9dQZF1DX04mASjTsvf0?si=r6S-aGh0Q7a2gb3nCFLemQ",
+			},
+			covers: 6,
+		},
+		{
+			id: "electronic", name: "Electronic / Dance",
+			genres: []string{"electronic", "dance"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/37i9dQZF1DWZBCPUIUs2iR?si=0REkYg79Sp6ghVwZnO0D6Q",
+				"https://open.spotify.com/playlist/44dFP8mNyCi3UcBlyaRICH?si=_bljU3wzSH6h7tfYIGQ0cw",
+			},
+			covers: 5,
+		},
+		{
+			id: "rock", name: "Rock / Pop",
+			genres: []string{"rock", "pop"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/37i9dQZF1DWXRqgorJj26U?si=nYX-RaKmTOqRcq73fyGEnQ",
+				"https://open.spotify.com/playlist/37i9dQZF1EIctsc1CJao2L?si=6MJxt2cpQeyYtAMYg0RKJw",
+			},
+			covers: 5,
+		},
+		{
+			id: "soul", name: "Soul / Funk / Disco",
+			genres: []string{"soul", "funk", "disco"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/73sIU7MIIIrSh664eygyjm?si=uKn9DEovQSqxb9P4l5u7RQ",
+				"https://open.spotify.com/playlist/37i9dQZF1DWWvhKV4FBciw?si=1lzYUcgJR-6DBpoluxX2OA",
+				"https://open.spotify.com/playlist/37i9dQZF1DX1MUPbVKMgJE?si=0nXq73dyRYGzdrrzEpd2Gw",
+			},
+			covers: 6,
+		},
+		{
+			id: "jazz", name: "Jazz / Blues",
+			genres: []string{"jazz", "blues"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/4pIwPQAiZk4JGiWRzAaxwK?si=gxXRMeGQS_SeqeTLhTIGbQ",
+				"https://open.spotify.com/playlist/0A1IHcqjyImN9uoHRsVtBn?si=f1z51sRCRx6gd7dPEfg5_g",
+			},
+			covers: 6,
+		},
+		{
+			id: "reggae", name: "Reggae / Dub",
+			genres: []string{"reggae", "dub"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/37i9dQZF1EQpjs4F0vUZ1x?si=03B58nZISEeLoLDvbZ7jbw",
+				"https://open.spotify.com/playlist/7AI62FuDUugLcg1IyVgMwU?si=GiAjkEvDT2mdloDn-zsKpQ",
+			},
+			covers: 5,
+		},
+		{
+			id: "country", name: "Country / Folk",
+			genres: []string{"country", "folk"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/0QFaFgDQQiKBob7VIZIilG?si=kjNuzW4iS86D9Udo0VSeUA",
+				"https://open.spotify.com/playlist/37i9dQZF1DWVmps5U8gHNv?si=ElAZI6eRS9OWr0EMMlh2Ow",
+			},
+			covers: 6,
+		},
+		{
+			id: "world", name: "World / Latin",
+			genres: []string{"world", "latin"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/37i9dQZF1DXcIme26eJxid?si=F382j4bBTjSa8oS2NC3C_w",
+				"https://open.spotify.com/playlist/37i9dQZF1DX6ThddIjWuGT?si=B0r6U0sNQlS8DD1xC4g9ng",
+			},
+			covers: 6,
+		},
+		{
+			id: "soundtrack", name: "Soundtrack / Library",
+			genres: []string{"soundtrack", "library"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/3vDe8D64ytZRKXt0AsJT0B?si=4HFGEkdPRwSPajsaU23T2A",
+			},
+			covers: 5,
+		},
+		{
+			id: "classical", name: "Classical",
+			genres: []string{"classical"},
+			playlists: []string{
+				"https://open.spotify.com/playlist/2AIyLES2xJfPa6EOxmKySl?si=qpeStpIiQO--nr__oaFQCg",
+			},
+			covers: 5,
+		},
+	}
+
+	groups := make([]models.GenreGroup, 0, len(seeds))
+	for position, s := range seeds {
+		group := models.GenreGroup{ID: s.id, Name: s.name, Position: position}
+		for _, genre := range s.genres {
+			group.Genres = append(group.Genres, models.GenreGroupGenre{Name: genre})
+		}
+		for _, synonym := range s.synonyms {
+			group.Genres = append(group.Genres, models.GenreGroupGenre{Name: synonym, Synonym: true})
+		}
+		for _, url := range s.playlists {
+			group.Playlists = append(group.Playlists, models.GenreGroupPlaylist{URL: url})
+		}
+		for i := 1; i <= s.covers; i++ {
+			group.CoverImages = append(group.CoverImages, models.GenreGroupCoverImage{Image: fmt.Sprintf("%s_%d.jpg", s.id, i)})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupGenreTaxonomyTestDB creates an in-memory SQLite database for testing
+func setupGenreTaxonomyTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	require.NoError(t, err, "Failed to open in-memory database")
+
+	// Drop any existing tables first
+	err = db.Migrator().DropTable(&models.GenreGroup{}, &models.GenreGroupGenre{}, &models.GenreGroupPlaylist{}, &models.GenreGroupCoverImage{})
+	require.NoError(t, err, "Failed to drop existing tables")
+
+	err = db.AutoMigrate(&models.GenreGroup{}, &models.GenreGroupGenre{}, &models.GenreGroupPlaylist{}, &models.GenreGroupCoverImage{})
+	require.NoError(t, err, "Failed to migrate genre taxonomy models")
+
+	return db
+}
+
+func TestGenreTaxonomyRepository_SeedDefaults(t *testing.T) {
+	db := setupGenreTaxonomyTestDB(t)
+	repo := NewGenreTaxonomyRepository(db)
+
+	// Act
+	require.NoError(t, repo.SeedDefaults(), "Should seed an empty taxonomy")
+	require.NoError(t, repo.SeedDefaults(), "Should leave an existing taxonomy alone")
+
+	// Assert
+	groups, err := repo.ListGroups()
+	require.NoError(t, err)
+	require.Len(t, groups, len(defaultGenreGroups()), "Should seed every default group once")
+	assert.Equal(t, "hip-hop", groups[0].ID, "Groups should come back in display order")
+	assert.Equal(t, []string{"hip-hop", "r&b", "rap"}, groups[0].SearchGenres())
+	assert.Len(t, groups[0].Playlists, 3)
+	assert.Len(t, groups[0].CoverImages, 6)
+
+	rock, err := repo.GetGroup("rock")
+	require.NoError(t, err)
+	require.NotNil(t, rock)
+	assert.Equal(t, "rock_1.jpg", rock.CoverImages[0].Image, "Rock / Pop covers should use the rock images")
+}
+
+func TestGenreTaxonomyRepository_SaveGroup(t *testing.T) {
+	db := setupGenreTaxonomyTestDB(t)
+	repo := NewGenreTaxonomyRepository(db)
+
+	group := &models.GenreGroup{
+		ID:          "jazz",
+		Name:        "Jazz",
+		Genres:      []models.GenreGroupGenre{{Name: "jazz"}, {Name: "bebop"}},
+		Playlists:   []models.GenreGroupPlaylist{{URL: "https://open.spotify.com/playlist/jazz"}},
+		CoverImages: []models.GenreGroupCoverImage{{Image: "jazz_1.jpg"}},
+	}
+
+	t.Run("Create_Group", func(t *testing.T) {
+		// Act
+		err := repo.SaveGroup(group)
+
+		// Assert
+		require.NoError(t, err, "Should create the group")
+		saved, err := repo.GetGroup("jazz")
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.Equal(t, "Jazz", saved.Name)
+		assert.Equal(t, []string{"bebop", "jazz"}, saved.SearchGenres())
+		assert.Len(t, saved.Playlists, 1)
+		assert.Len(t, saved.CoverImages, 1)
+	})
+
+	t.Run("Replace_Group", func(t *testing.T) {
+		// Arrange
+		saved, err := repo.GetGroup("jazz")
+		require.NoError(t, err)
+		saved.Name = "Jazz / Blues"
+		saved.Genres = []models.GenreGroupGenre{{Name: "jazz"}, {Name: "blues"}, {Name: "bop", Synonym: true}}
+		saved.Playlists = nil
+
+		// Act
+		err = repo.SaveGroup(saved)
+
+		// Assert
+		require.NoError(t, err, "Should replace the group")
+		replaced, err := repo.GetGroup("jazz")
+		require.NoError(t, err)
+		assert.Equal(t, "Jazz / Blues", replaced.Name)
+		assert.Equal(t, []string{"blues", "jazz"}, replaced.SearchGenres(), "Old genres should be gone")
+		assert.Len(t, replaced.Genres, 3)
+		assert.Empty(t, replaced.Playlists, "Old playlists should be gone")
+	})
+
+	t.Run("Get_Missing_Group", func(t *testing.T) {
+		// Act
+		missing, err := repo.GetGroup("polka")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+}
+
+func TestGenreTaxonomyRepository_DeleteGroup(t *testing.T) {
+	db := setupGenreTaxonomyTestDB(t)
+	repo := NewGenreTaxonomyRepository(db)
+	require.NoError(t, repo.SeedDefaults())
+
+	// Act
+	err := repo.DeleteGroup("classical")
+
+	// Assert
+	require.NoError(t, err, "Should delete the group")
+	group, err := repo.GetGroup("classical")
+	require.NoError(t, err)
+	assert.Nil(t, group)
+
+	var orphans int64
+	require.NoError(t, db.Model(&models.GenreGroupGenre{}).Where("group_id = ?", "classical").Count(&orphans).Error)
+	assert.Zero(t, orphans, "Should delete the group's genres")
+}
@@ -1,6 +1,10 @@
 package repository
 
-import "github.com/Emeruem-Kennedy1/ghopper/internal/models"
+import (
+	"context"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
 
 // UserRepositoryInterface defines the methods we use from UserRepository
 type UserRepositoryInterface interface {
@@ -12,13 +16,24 @@ type UserRepositoryInterface interface {
 	Delete(id string) error
 }
 
-// song repository interface
-
+// SongRepositoryInterface defines the methods we use on the samples DB. When the
+// DB has the SongCanonical table, duplicate songs resolve to their canonical IDs.
+// Every method stops early once its context is cancelled.
 type SongRepositoryInterface interface {
-	GetSongIDsByTitleAndArtist(title, artist string) ([]int, error)
-	GetSongWithDetails(SongID int) (*models.SongNode, error)
-	GetAllSampledSongs(songID int) ([]int, error)
-	FindSongsByGenreBFS(songQueries []models.SongQuery, targetGenre string, maxDepth int) ([]models.SearchResult, error)
+	GetSongIDsByTitleAndArtist(ctx context.Context, title, artist string) ([]int, error)
+	GetSongIDsByArtist(ctx context.Context, artist string, limit int) ([]int, error)
+	MatchSongs(ctx context.Context, query models.SongQuery) ([]models.SongMatch, error)
+	GetSongWithDetails(ctx context.Context, SongID int) (*models.SongNode, error)
+	GetSongsWithDetails(ctx context.Context, ids []int) (map[int]*models.SongNode, error)
+	GetAllSampledSongs(ctx context.Context, songID int) ([]int, error)
+	GetSampleLineage(ctx context.Context, songID int) (*models.SampleLineage, error)
+	SearchSongs(ctx context.Context, query models.SongSearchQuery) ([]models.SongNode, error)
+	FindSongsByGenreBFS(ctx context.Context, songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) ([]models.SearchResult, error)
+	FindSamplePaths(ctx context.Context, fromIDs, toIDs []int, opts models.SearchOptions) ([]models.SearchResult, error)
+	GetArtistNetwork(ctx context.Context, artistID int, opts models.ArtistNetworkOptions) (*models.ArtistNetwork, error)
+	GetSongNeighborhood(ctx context.Context, songID int, opts models.NeighborhoodOptions) (*models.SongNeighborhood, error)
+	GetGenreProfile(ctx context.Context, songQueries []models.SongQuery, opts models.SearchOptions) (*models.GenreProfile, error)
+	CountSongsByGenreGroup(ctx context.Context, genreGroups map[string][]string) (map[string]int, error)
 }
 
 type SpotifySongRepositoryInterface interface {
@@ -40,13 +55,41 @@ type NonSpotifyUserRepositoryInterface interface {
 	Verify(id, passphrase string) (bool, error)
 	SavePlaylist(playlist *models.NonSpotifyPlaylist, tracks []models.NonSpotifyPlaylistTrack, seedTracks []models.NonSpotifyPlaylistSeedTrack) error
 	GetUserPlaylists(userID string) ([]models.NonSpotifyPlaylist, error)
+	GetUserTracks(userID string) ([]models.NonSpotifyPlaylistTrack, error)
 	GetPlaylistWithTracks(playlistID string) (*models.NonSpotifyPlaylistWithTracks, error)
 	UpdateTrackStatus(trackID string, addedToPlaylist bool) error
 	DeletePlaylist(playlistID string) error
 }
 
+// GenreTaxonomyRepositoryInterface defines the methods for the GenreTaxonomyRepository
+type GenreTaxonomyRepositoryInterface interface {
+	ListGroups() ([]models.GenreGroup, error)
+	GetGroup(id string) (*models.GenreGroup, error)
+	SaveGroup(group *models.GenreGroup) error
+	DeleteGroup(id string) error
+	SeedDefaults() error
+}
+
+// ExclusionRepositoryInterface defines the methods for the ExclusionRepository
+type ExclusionRepositoryInterface interface {
+	ListExclusions(userID string) ([]models.UserExclusion, error)
+	AddExclusion(exclusion *models.UserExclusion) error
+	DeleteExclusion(userID string, id uint) (bool, error)
+}
+
+// SearchCacheInterface defines the methods admins use on the genre search cache
+type SearchCacheInterface interface {
+	Stats() models.SearchCacheStats
+	Invalidate()
+}
+
 // Ensure the UserRepository, SpotifySongRepository and SongRepository implement our interfaces
 var _ UserRepositoryInterface = (*UserRepository)(nil)
 var _ SongRepositoryInterface = (*SongRepository)(nil)
+var _ SongRepositoryInterface = (*SongGraphIndex)(nil)
+var _ SongRepositoryInterface = (*CachedSongRepository)(nil)
 var _ SpotifySongRepositoryInterface = (*SpotifySongRepository)(nil)
 var _ NonSpotifyUserRepositoryInterface = (*NonSpotifyUserRepository)(nil)
+var _ GenreTaxonomyRepositoryInterface = (*GenreTaxonomyRepository)(nil)
+var _ ExclusionRepositoryInterface = (*ExclusionRepository)(nil)
+var _ SearchCacheInterface = (*CachedSongRepository)(nil)
@@ -100,6 +100,16 @@ func (r *NonSpotifyUserRepository) GetUserPlaylists(userID string) ([]models.Non
 	return playlists, result.Error
 }
 
+// GetUserTracks retrieves the tracks of every playlist a user generated
+func (r *NonSpotifyUserRepository) GetUserTracks(userID string) ([]models.NonSpotifyPlaylistTrack, error) {
+	var tracks []models.NonSpotifyPlaylistTrack
+	result := r.db.
+		Joins("JOIN non_spotify_playlists ON non_spotify_playlists.id = non_spotify_playlist_tracks.playlist_id").
+		Where("non_spotify_playlists.user_id = ?", userID).
+		Find(&tracks)
+	return tracks, result.Error
+}
+
 // GetPlaylistWithTracks retrieves a playlist with its tracks and seed tracks
 func (r *NonSpotifyUserRepository) GetPlaylistWithTracks(playlistID string) (*models.NonSpotifyPlaylistWithTracks, error) {
 	var playlist models.NonSpotifyPlaylist
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// Weights of the signals that make up a genre search result's score. They add
+// up to 1, so scores stay between 0 and 1.
+const (
+	distanceWeight    = 0.4
+	seedReachWeight   = 0.25
+	popularityWeight  = 0.2
+	specificityWeight = 0.15
+)
+
+// rankSearchResults scores every result, sorts them best first and applies
+// opts.Limit. sampledInCounts holds how many songs sample each matched song.
+//
+// A result scores higher when:
+//   - it is fewer hops from its seed,
+//   - more distinct seeds reach the same matched song,
+//   - the matched song is sampled by many songs (its in-degree in the graph),
+//   - more of the matched song's genres are target genres, so a pure jazz
+//     song beats a jazz/pop/rock crossover in a jazz search.
+func rankSearchResults(results []models.SearchResult, sampledInCounts map[int]int, filter models.GenreFilter, opts models.SearchOptions) []models.SearchResult {
+	if len(results) == 0 {
+		return results
+	}
+
+	seedsReaching := make(map[int]map[int]struct{})
+	for _, result := range results {
+		matchID := result.MatchedSong.ID
+		if seedsReaching[matchID] == nil {
+			seedsReaching[matchID] = make(map[int]struct{})
+		}
+		seedsReaching[matchID][result.SourceSong.ID] = struct{}{}
+	}
+
+	maxSeeds, maxSampledIn := 1, 0
+	for matchID, seeds := range seedsReaching {
+		maxSeeds = max(maxSeeds, len(seeds))
+		maxSampledIn = max(maxSampledIn, sampledInCounts[matchID])
+	}
+
+	targets := make(map[string]struct{})
+	for _, genre := range filter.TargetGenres() {
+		targets[genre] = struct{}{}
+	}
+
+	for i := range results {
+		matched := results[i].MatchedSong
+
+		distance := 1 / float64(1+results[i].Distance)
+		seedReach := float64(len(seedsReaching[matched.ID])) / float64(maxSeeds)
+
+		popularity := 0.0
+		if maxSampledIn > 0 {
+			popularity = math.Log1p(float64(sampledInCounts[matched.ID])) / math.Log1p(float64(maxSampledIn))
+		}
+
+		specificity := 0.0
+		if len(matched.Genres) > 0 {
+			inTarget := 0
+			for _, genre := range matched.Genres {
+				if _, ok := targets[strings.ToLower(strings.TrimSpace(genre))]; ok {
+					inTarget++
+				}
+			}
+			specificity = float64(inTarget) / float64(len(matched.Genres))
+		}
+
+		score := distanceWeight*distance +
+			seedReachWeight*seedReach +
+			popularityWeight*popularity +
+			specificityWeight*specificity
+		results[i].Score = math.Round(score*1000) / 1000
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].MatchedSong.ID < results[j].MatchedSong.ID
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
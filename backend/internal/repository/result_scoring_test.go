@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scoringResult(sourceID, matchedID, distance int, genres ...string) models.SearchResult {
+	return models.SearchResult{
+		SourceSong:  models.SongNode{ID: sourceID},
+		MatchedSong: models.SongNode{ID: matchedID, Genres: genres},
+		Distance:    distance,
+	}
+}
+
+func TestRankSearchResults(t *testing.T) {
+	t.Run("Seed_Reach", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			scoringResult(1, 10, 1, "jazz"),
+			scoringResult(1, 20, 1, "jazz"),
+			scoringResult(2, 20, 1, "jazz"),
+		}
+
+		// Act
+		ranked := rankSearchResults(results, nil, models.NewGenreFilter("jazz"), models.SearchOptions{})
+
+		// Assert
+		require.Len(t, ranked, 3)
+		assert.Equal(t, 20, ranked[0].MatchedSong.ID, "A match reached from two seeds should lead")
+		assert.Equal(t, 20, ranked[1].MatchedSong.ID)
+		assert.Equal(t, 10, ranked[2].MatchedSong.ID)
+		assert.Equal(t, 0.6, ranked[0].Score)
+		assert.Equal(t, 0.475, ranked[2].Score)
+	})
+
+	t.Run("Genre_Specificity", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			scoringResult(1, 10, 1, "jazz", "pop", "rock"),
+			scoringResult(1, 20, 1, "Jazz"),
+		}
+
+		// Act
+		ranked := rankSearchResults(results, nil, models.NewGenreFilter("jazz"), models.SearchOptions{})
+
+		// Assert
+		require.Len(t, ranked, 2)
+		assert.Equal(t, 20, ranked[0].MatchedSong.ID, "A pure jazz song should beat a crossover")
+		assert.Equal(t, 10, ranked[1].MatchedSong.ID)
+	})
+
+	t.Run("Popularity", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			scoringResult(1, 10, 1, "jazz"),
+			scoringResult(1, 20, 1, "jazz"),
+		}
+
+		// Act
+		ranked := rankSearchResults(results, map[int]int{10: 1, 20: 12}, models.NewGenreFilter("jazz"), models.SearchOptions{})
+
+		// Assert
+		require.Len(t, ranked, 2)
+		assert.Equal(t, 20, ranked[0].MatchedSong.ID, "The more sampled song should lead")
+		assert.Equal(t, 0.8, ranked[0].Score)
+	})
+
+	t.Run("Ties_Keep_Distance_Order", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			scoringResult(1, 30, 1),
+			scoringResult(1, 20, 1),
+		}
+
+		// Act
+		ranked := rankSearchResults(results, nil, models.NewGenreFilter("jazz"), models.SearchOptions{})
+
+		// Assert
+		assert.Equal(t, 20, ranked[0].MatchedSong.ID, "Equal scores should fall back to the matched song ID")
+		assert.Equal(t, 30, ranked[1].MatchedSong.ID)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		// Arrange
+		results := []models.SearchResult{
+			scoringResult(1, 10, 3, "jazz"),
+			scoringResult(1, 20, 1, "jazz"),
+			scoringResult(1, 30, 2, "jazz"),
+		}
+
+		// Act
+		ranked := rankSearchResults(results, nil, models.NewGenreFilter("jazz"), models.SearchOptions{Limit: 2})
+
+		// Assert
+		require.Len(t, ranked, 2, "Should cut results after ranking")
+		assert.Equal(t, 20, ranked[0].MatchedSong.ID)
+		assert.Equal(t, 30, ranked[1].MatchedSong.ID)
+	})
+
+	t.Run("No_Results", func(t *testing.T) {
+		// Act
+		ranked := rankSearchResults(nil, nil, models.NewGenreFilter("jazz"), models.SearchOptions{Limit: 5})
+
+		// Assert
+		assert.Empty(t, ranked)
+	})
+}
@@ -0,0 +1,1051 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"go.uber.org/zap"
+)
+
+// SongGraphIndex serves SongRepositoryInterface queries from an in-process copy
+// of the samples DB. Load must succeed once before the index is used; Run keeps
+// the copy fresh in the background.
+type SongGraphIndex struct {
+	db samplesDB
+
+	mu    sync.RWMutex
+	graph *sampleGraph
+	// onReload runs after every snapshot swapped in by a refresh
+	onReload []func()
+}
+
+// sampleGraph is an immutable snapshot of the samples DB. A refresh builds a
+// new snapshot and swaps it in, so readers never see a partially loaded graph.
+// Every index and sample edge is keyed by canonical song; duplicates are only
+// kept in songs so they can still be hydrated.
+type sampleGraph struct {
+	songs map[int]*graphSong
+	// canonical maps duplicate songs to their canonical song (SongCanonical)
+	// and duplicates maps canonical songs back to their duplicates
+	canonical     map[int]int
+	duplicates    map[int][]int
+	byTitleArtist map[string][]int
+	// matchTitles and byMatchArtist index songs by folded title, sorted for
+	// prefix lookups, and folded artist name for MatchSongs
+	matchTitles   []matchTitle
+	byMatchArtist map[string][]int
+	// byTitleYear indexes songs by title and release year when the samples DB
+	// has no SongCanonical table, for the lookups that still merge them
+	byTitleYear map[string][]int
+	// artists and artistSongs index the artists credited on any song
+	artists     map[int]models.Artist
+	artistSongs map[int][]int
+	// samplesUsed maps a song to the songs it samples (original_song_id side)
+	samplesUsed map[int][]int
+	// sampledIn maps a song to the songs that sample it (sampled_in_song_id side)
+	sampledIn   map[int][]int
+	sampleCount int
+}
+
+// matchTitle is a song's folded title and the canonical song it resolves to
+type matchTitle struct {
+	title string
+	id    int
+}
+
+type graphSong struct {
+	id          int
+	title       string
+	releaseYear sql.NullInt64
+	artists     []models.Artist
+	genres      []string
+	genreSet    map[string]struct{}
+}
+
+// NewSongGraphIndex indexes the samples DB. canonical says whether it has the
+// SongCanonical table; without it, duplicate songs are not merged.
+func NewSongGraphIndex(db *sql.DB, canonical bool) *SongGraphIndex {
+	return &SongGraphIndex{db: samplesDB{DB: db, canonical: canonical}}
+}
+
+// Load reads the whole sample graph from the samples DB and replaces the
+// current snapshot with it.
+func (idx *SongGraphIndex) Load() error {
+	graph, err := loadSampleGraph(idx.db)
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	idx.graph = graph
+	onReload := idx.onReload
+	idx.mu.Unlock()
+
+	zap.L().Info("Loaded sample graph index",
+		zap.Int("songs", len(graph.songs)),
+		zap.Int("samples", graph.sampleCount))
+	for _, fn := range onReload {
+		fn()
+	}
+	return nil
+}
+
+// OnReload registers fn to run after every later Load, once the new snapshot
+// is served. Caches of the index's results use it to drop what they read from
+// the previous snapshot.
+func (idx *SongGraphIndex) OnReload(fn func()) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.onReload = append(idx.onReload, fn)
+}
+
+// Run reloads the index every interval until ctx is cancelled. A failed reload
+// keeps serving the previous snapshot.
+func (idx *SongGraphIndex) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := idx.Load(); err != nil {
+				zap.L().Error("Failed to refresh sample graph index", zap.Error(err))
+			}
+		}
+	}
+}
+
+// snapshot returns the loaded graph, or ctx's error once it is cancelled.
+// Methods scanning the whole catalog check ctx again as they go.
+func (idx *SongGraphIndex) snapshot(ctx context.Context) (*sampleGraph, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.graph == nil {
+		return nil, fmt.Errorf("sample graph index not loaded")
+	}
+	return idx.graph, nil
+}
+
+func (idx *SongGraphIndex) GetSongIDsByTitleAndArtist(ctx context.Context, title, artist string) ([]int, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var songIDs []int
+	songIDs = append(songIDs, graph.byTitleArtist[titleArtistKey(title, artist)]...)
+	return songIDs, nil
+}
+
+// GetSongIDsByArtist returns the canonical songs an artist is a main artist
+// on, the most sampled and sampling first, at most limit of them
+func (idx *SongGraphIndex) GetSongIDsByArtist(ctx context.Context, artist string, limit int) ([]int, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := foldText(artist)
+	var songIDs []int
+	for _, id := range graph.byMatchArtist[key] {
+		song, ok := graph.songs[id]
+		if !ok {
+			continue
+		}
+		for _, credit := range song.artists {
+			if credit.IsMain && foldText(credit.Name) == key {
+				songIDs = append(songIDs, id)
+				break
+			}
+		}
+	}
+
+	connections := func(id int) int {
+		return len(graph.samplesUsed[id]) + len(graph.sampledIn[id])
+	}
+	sort.SliceStable(songIDs, func(i, j int) bool {
+		if connections(songIDs[i]) != connections(songIDs[j]) {
+			return connections(songIDs[i]) > connections(songIDs[j])
+		}
+		return songIDs[i] < songIDs[j]
+	})
+	if limit > 0 && len(songIDs) > limit {
+		songIDs = songIDs[:limit]
+	}
+	return songIDs, nil
+}
+
+// MatchSongs reads the same candidates as the SQL repository, by title prefix
+// and by every alias of the queried artist, then scores them the same way.
+func (idx *SongGraphIndex) MatchSongs(ctx context.Context, query models.SongQuery) ([]models.SongMatch, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	title := foldText(stripTitleDecorations(query.Title))
+	if title == "" {
+		return nil, nil
+	}
+
+	seen := make(map[int]struct{})
+	var candidates []matchCandidate
+	add := func(ids []int) {
+		for _, id := range ids {
+			if _, exists := seen[id]; exists {
+				continue
+			}
+			seen[id] = struct{}{}
+			if song, ok := graph.songs[id]; ok && len(song.artists) > 0 {
+				candidates = append(candidates, matchCandidate{id: song.id, title: song.title, artists: graph.clusterArtistNames(id)})
+			}
+		}
+	}
+
+	first := sort.Search(len(graph.matchTitles), func(i int) bool { return graph.matchTitles[i].title >= title })
+	for i := first; i < len(graph.matchTitles) && strings.HasPrefix(graph.matchTitles[i].title, title); i++ {
+		add([]int{graph.matchTitles[i].id})
+	}
+	for _, alias := range artistAliases(query.Artist) {
+		add(graph.byMatchArtist[alias])
+	}
+
+	return rankSongMatches(query, topMatchCandidates(query, candidates)), nil
+}
+
+func (idx *SongGraphIndex) GetSongWithDetails(ctx context.Context, SongID int) (*models.SongNode, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	song, ok := graph.node(graph.canonicalID(SongID))
+	if !ok {
+		return nil, fmt.Errorf("error getting song: %w", sql.ErrNoRows)
+	}
+	return &song, nil
+}
+
+// GetSongsWithDetails hydrates ids as given, like the SQL repository, without
+// resolving duplicates to their canonical song
+func (idx *SongGraphIndex) GetSongsWithDetails(ctx context.Context, ids []int) (map[int]*models.SongNode, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	songs := make(map[int]*models.SongNode, len(ids))
+	for _, id := range ids {
+		if song, ok := graph.node(id); ok {
+			songs[id] = &song
+		}
+	}
+	return songs, nil
+}
+
+func (idx *SongGraphIndex) GetAllSampledSongs(ctx context.Context, songID int) ([]int, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := graph.songs[songID]; !ok {
+		return nil, nil
+	}
+
+	var songIDs []int
+	seen := make(map[int]struct{})
+	for _, id := range graph.sameSongs(songID) {
+		for _, neighbor := range graph.neighbors(id) {
+			if _, exists := seen[neighbor]; exists {
+				continue
+			}
+			seen[neighbor] = struct{}{}
+			songIDs = append(songIDs, neighbor)
+		}
+	}
+	return songIDs, nil
+}
+
+func (idx *SongGraphIndex) GetSampleLineage(ctx context.Context, songID int) (*models.SampleLineage, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := &models.SampleLineage{}
+	if _, ok := graph.songs[songID]; !ok {
+		return lineage, nil
+	}
+
+	var samplesUsed, sampledIn []int
+	for _, id := range graph.sameSongs(songID) {
+		samplesUsed = append(samplesUsed, graph.samplesUsed[id]...)
+		sampledIn = append(sampledIn, graph.sampledIn[id]...)
+	}
+	if len(samplesUsed) > 0 {
+		lineage.SamplesUsed = uniqueSongIDs(samplesUsed)
+	}
+	if len(sampledIn) > 0 {
+		lineage.SampledIn = uniqueSongIDs(sampledIn)
+	}
+	return lineage, nil
+}
+
+// CountSongsByGenreGroup counts, in one scan of the catalog, the distinct
+// canonical songs with any of their duplicates tagged with any of the genres of
+// each group of genreGroups
+func (idx *SongGraphIndex) CountSongsByGenreGroup(ctx context.Context, genreGroups map[string][]string) (map[string]int, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groupOf, _ := genreGroupIndex(genreGroups)
+	matched := make(map[string]map[int]struct{})
+	for id, song := range graph.songs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for genre := range song.genreSet {
+			group, ok := groupOf[genre]
+			if !ok {
+				continue
+			}
+			if matched[group] == nil {
+				matched[group] = make(map[int]struct{})
+			}
+			matched[group][graph.canonicalID(id)] = struct{}{}
+		}
+	}
+
+	counts := make(map[string]int, len(genreGroups))
+	for group := range genreGroups {
+		counts[group] = len(matched[group])
+	}
+	return counts, nil
+}
+
+// SearchSongs scans the catalog with the same ranking as the SQL repository:
+// title prefix, then artist prefix, then substring matches. Duplicates of a
+// song come back once, as their canonical song.
+func (idx *SongGraphIndex) SearchSongs(ctx context.Context, query models.SongSearchQuery) ([]models.SongNode, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	term := strings.ToLower(strings.TrimSpace(query.Query))
+	artistTerm := strings.ToLower(strings.TrimSpace(query.Artist))
+	if term == "" && artistTerm == "" {
+		return nil, nil
+	}
+
+	type rankedSong struct {
+		song *graphSong
+		rank int
+	}
+
+	var matches []rankedSong
+	// matchIndex finds the match of a canonical song, so duplicates keep the
+	// best rank of any of their rows
+	matchIndex := make(map[int]int)
+	for _, song := range graph.songs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		title := strings.ToLower(song.title)
+		rank := -1
+		for _, artist := range song.artists {
+			name := strings.ToLower(artist.Name)
+			if artistTerm != "" && !strings.Contains(name, artistTerm) {
+				continue
+			}
+
+			artistRank := -1
+			switch {
+			case strings.HasPrefix(title, term):
+				artistRank = 0
+			case strings.HasPrefix(name, term):
+				artistRank = 1
+			case strings.Contains(title, term) || strings.Contains(name, term):
+				artistRank = 2
+			}
+			if artistRank >= 0 && (rank < 0 || artistRank < rank) {
+				rank = artistRank
+			}
+		}
+		if rank < 0 {
+			continue
+		}
+
+		canonical, ok := graph.songs[graph.canonicalID(song.id)]
+		if !ok {
+			canonical = song
+		}
+		if i, exists := matchIndex[canonical.id]; exists {
+			matches[i].rank = min(matches[i].rank, rank)
+			continue
+		}
+		matchIndex[canonical.id] = len(matches)
+		matches = append(matches, rankedSong{song: canonical, rank: rank})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		titleI, titleJ := strings.ToLower(matches[i].song.title), strings.ToLower(matches[j].song.title)
+		if titleI != titleJ {
+			return titleI < titleJ
+		}
+		return matches[i].song.id < matches[j].song.id
+	})
+
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+
+	songs := make([]models.SongNode, 0, len(matches))
+	for _, match := range matches {
+		if song, ok := graph.node(match.song.id); ok {
+			songs = append(songs, song)
+		}
+	}
+	return songs, nil
+}
+
+// FindSongsByGenreBFS runs a breadth-first search from every seed. Each song is
+// expanded at most opts.PathsPerMatch() times per source and never twice on the
+// same path, so every (source, match) pair comes back at its shortest distance
+// followed by up to MaxPaths-1 alternative simple paths. It stops after
+// opts.MaxRows matched paths, nearest first.
+func (idx *SongGraphIndex) FindSongsByGenreBFS(ctx context.Context, songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) ([]models.SearchResult, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, excluded := filter.TargetGenres(), filter.ExcludedGenres()
+	excluder := newResultExcluder(opts.Exclude)
+	matches := func(_ context.Context, ids []int) (map[int]bool, error) {
+		matched := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			if !graph.matchesGenres(id, targets, excluded, filter.Match) || !opts.Years.Contains(graph.releaseYear(id)) {
+				continue
+			}
+			song, ok := graph.node(id)
+			matched[id] = ok && !excluder.excludes(song)
+		}
+		return matched, nil
+	}
+
+	walks, err := walkGenreSearch(ctx, graph.hopLoader(opts.Chronological), graph.seedSongs(songQueries), opts, matches)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.SearchResult
+	for _, w := range walks {
+		sourceSong, ok := graph.node(w.source)
+		if !ok {
+			continue
+		}
+		matchedSong, ok := graph.node(w.path[len(w.path)-1])
+		if !ok {
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			SourceSong:    sourceSong,
+			MatchedSong:   matchedSong,
+			Distance:      len(w.path) - 1,
+			Path:          graph.nodes(w.path),
+			HopDirections: w.hops,
+		})
+	}
+
+	sampledInCounts := make(map[int]int)
+	for _, result := range results {
+		sampledInCounts[result.MatchedSong.ID] = len(graph.sampledIn[result.MatchedSong.ID])
+	}
+	return rankSearchResults(results, sampledInCounts, filter, opts), nil
+}
+
+// GetGenreProfile counts the songs first reached at each depth of a walk from
+// the seeds, grouped by their genres. The walk stops after opts.MaxRows songs.
+func (idx *SongGraphIndex) GetGenreProfile(ctx context.Context, songQueries []models.SongQuery, opts models.SearchOptions) (*models.GenreProfile, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, truncated, err := walkGenreProfile(ctx, graph.hopLoader(opts.Chronological), graph.seedSongs(songQueries), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]map[string]int)
+	for i, ids := range levels {
+		for _, id := range ids {
+			if !opts.Years.Contains(graph.releaseYear(id)) {
+				continue
+			}
+			var genres []string
+			if song, ok := graph.songs[id]; ok {
+				genres = song.genres
+			}
+			if counts[i+1] == nil {
+				counts[i+1] = make(map[string]int)
+			}
+			counts[i+1][genreSetKey(genres)]++
+		}
+	}
+
+	return &models.GenreProfile{Levels: genreProfile(counts), Truncated: truncated}, nil
+}
+
+// releaseYear returns a song's release year, or 0 when it is unknown
+func (g *sampleGraph) releaseYear(id int) int {
+	if song, ok := g.songs[id]; ok {
+		return int(song.releaseYear.Int64)
+	}
+	return 0
+}
+
+// isChronological reports whether the sample behind a hop from songID has its
+// original released no later than the song sampling it
+func (g *sampleGraph) isChronological(songID int, hop sampleHop) bool {
+	if hop.direction == models.DirectionAncestors {
+		return models.IsChronological(g.releaseYear(hop.to), g.releaseYear(songID))
+	}
+	return models.IsChronological(g.releaseYear(songID), g.releaseYear(hop.to))
+}
+
+// FindSamplePaths returns the shortest sample chains from any of fromIDs to any
+// of toIDs, up to opts.PathsPerMatch() of them.
+func (idx *SongGraphIndex) FindSamplePaths(ctx context.Context, fromIDs, toIDs []int, opts models.SearchOptions) ([]models.SearchResult, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := findSamplePaths(ctx, graph.hopLoader(false), graph.canonicalIDs(fromIDs), graph.canonicalIDs(toIDs), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.SearchResult
+	for _, path := range paths {
+		sourceSong, ok := graph.node(path.ids[0])
+		if !ok {
+			continue
+		}
+		matchedSong, ok := graph.node(path.ids[len(path.ids)-1])
+		if !ok {
+			continue
+		}
+		results = append(results, models.SearchResult{
+			SourceSong:    sourceSong,
+			MatchedSong:   matchedSong,
+			Distance:      len(path.hops),
+			Path:          graph.nodes(path.ids),
+			HopDirections: path.hops,
+		})
+	}
+	return results, nil
+}
+
+// GetArtistNetwork aggregates the samples around an artist into artist edges.
+// It returns nil when the artist is not credited on any song.
+func (idx *SongGraphIndex) GetArtistNetwork(ctx context.Context, artistID int, opts models.ArtistNetworkOptions) (*models.ArtistNetwork, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start, ok := graph.artists[artistID]
+	if !ok {
+		return nil, nil
+	}
+
+	load := func(_ context.Context, artistIDs []int, mainArtistsOnly bool) ([]artistSample, error) {
+		frontier := make(map[int]struct{}, len(artistIDs))
+		for _, id := range artistIDs {
+			frontier[id] = struct{}{}
+		}
+
+		var samples []artistSample
+		for _, artistID := range artistIDs {
+			for _, songID := range graph.artistSongs[artistID] {
+				for _, originalID := range graph.samplesUsed[songID] {
+					samples = append(samples, graph.artistSamples(songID, originalID, frontier, mainArtistsOnly)...)
+				}
+				for _, sampledInID := range graph.sampledIn[songID] {
+					samples = append(samples, graph.artistSamples(sampledInID, songID, frontier, mainArtistsOnly)...)
+				}
+			}
+		}
+		return samples, nil
+	}
+
+	return buildArtistNetwork(ctx, load, start, opts)
+}
+
+// GetSongNeighborhood returns every song within opts.Radius sample hops of
+// songID. It returns nil when the song does not exist.
+func (idx *SongGraphIndex) GetSongNeighborhood(ctx context.Context, songID int, opts models.NeighborhoodOptions) (*models.SongNeighborhood, error) {
+	graph, err := idx.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	songID = graph.canonicalID(songID)
+	if _, ok := graph.node(songID); !ok {
+		return nil, nil
+	}
+
+	neighborhood, err := buildSongNeighborhood(ctx, graph.hopLoader(false), songID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return neighborhood.hydrate(graph.node), nil
+}
+
+func loadSampleGraph(db samplesDB) (*sampleGraph, error) {
+	graph := &sampleGraph{
+		songs:         make(map[int]*graphSong),
+		canonical:     make(map[int]int),
+		duplicates:    make(map[int][]int),
+		byTitleArtist: make(map[string][]int),
+		byMatchArtist: make(map[string][]int),
+		byTitleYear:   make(map[string][]int),
+		artists:       make(map[int]models.Artist),
+		artistSongs:   make(map[int][]int),
+		samplesUsed:   make(map[int][]int),
+		sampledIn:     make(map[int][]int),
+	}
+
+	if db.canonical {
+		if err := loadCanonicalSongs(db.DB, graph); err != nil {
+			return nil, err
+		}
+	}
+
+	songRows, err := db.Query(`SELECT s.id, s.title, s.releaseYear FROM Song s`)
+	if err != nil {
+		return nil, fmt.Errorf("error loading songs: %v", err)
+	}
+	defer songRows.Close()
+
+	for songRows.Next() {
+		song := &graphSong{genreSet: make(map[string]struct{})}
+		if err := songRows.Scan(&song.id, &song.title, &song.releaseYear); err != nil {
+			return nil, fmt.Errorf("error scanning song: %v", err)
+		}
+		graph.songs[song.id] = song
+		if !db.canonical {
+			key := titleYearKey(song.title, song.releaseYear)
+			graph.byTitleYear[key] = append(graph.byTitleYear[key], song.id)
+		}
+		graph.matchTitles = append(graph.matchTitles, matchTitle{title: foldText(song.title), id: graph.canonicalID(song.id)})
+	}
+	if err := songRows.Err(); err != nil {
+		return nil, fmt.Errorf("error loading songs: %v", err)
+	}
+	sort.Slice(graph.matchTitles, func(i, j int) bool {
+		if graph.matchTitles[i].title != graph.matchTitles[j].title {
+			return graph.matchTitles[i].title < graph.matchTitles[j].title
+		}
+		return graph.matchTitles[i].id < graph.matchTitles[j].id
+	})
+
+	artistRows, err := db.Query(`
+		SELECT sa.songId, a.id, a.name, sa.isMainArtist
+		FROM SongArtist sa
+		JOIN Artist a ON sa.artistId = a.id
+		ORDER BY sa.songId, sa.isMainArtist DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error loading song artists: %v", err)
+	}
+	defer artistRows.Close()
+
+	for artistRows.Next() {
+		var songID int
+		var artist models.Artist
+		if err := artistRows.Scan(&songID, &artist.ID, &artist.Name, &artist.IsMain); err != nil {
+			return nil, fmt.Errorf("error scanning song artist: %v", err)
+		}
+		song, ok := graph.songs[songID]
+		if !ok {
+			continue
+		}
+		song.artists = append(song.artists, artist)
+		graph.artists[artist.ID] = models.Artist{ID: artist.ID, Name: artist.Name}
+
+		// A duplicate's credits find its canonical song
+		canonicalID, duplicate := graph.canonicalID(songID), graph.isDuplicate(songID)
+		key := titleArtistKey(song.title, artist.Name)
+		graph.byTitleArtist[key] = appendSongID(graph.byTitleArtist[key], canonicalID, duplicate)
+		graph.artistSongs[artist.ID] = appendSongID(graph.artistSongs[artist.ID], canonicalID, duplicate)
+		artistKey := foldText(artist.Name)
+		graph.byMatchArtist[artistKey] = appendSongID(graph.byMatchArtist[artistKey], canonicalID, duplicate)
+	}
+	if err := artistRows.Err(); err != nil {
+		return nil, fmt.Errorf("error loading song artists: %v", err)
+	}
+
+	genreRows, err := db.Query(`
+		SELECT sg.B, g.name
+		FROM _SongToGenre sg
+		JOIN Genre g ON g.id = sg.A
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error loading song genres: %v", err)
+	}
+	defer genreRows.Close()
+
+	for genreRows.Next() {
+		var songID int
+		var genre string
+		if err := genreRows.Scan(&songID, &genre); err != nil {
+			return nil, fmt.Errorf("error scanning song genre: %v", err)
+		}
+		song, ok := graph.songs[songID]
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(genre)
+		if _, exists := song.genreSet[key]; exists {
+			continue
+		}
+		song.genreSet[key] = struct{}{}
+		song.genres = append(song.genres, genre)
+	}
+	if err := genreRows.Err(); err != nil {
+		return nil, fmt.Errorf("error loading song genres: %v", err)
+	}
+
+	sampleRows, err := db.Query(`SELECT original_song_id, sampled_in_song_id FROM Sample`)
+	if err != nil {
+		return nil, fmt.Errorf("error loading samples: %v", err)
+	}
+	defer sampleRows.Close()
+
+	seenSamples := make(map[[2]int]struct{})
+	for sampleRows.Next() {
+		var originalID, sampledInID int
+		if err := sampleRows.Scan(&originalID, &sampledInID); err != nil {
+			return nil, fmt.Errorf("error scanning sample: %v", err)
+		}
+
+		// Samples are kept between canonical songs, like CanonicalSample
+		originalID, sampledInID = graph.canonicalID(originalID), graph.canonicalID(sampledInID)
+		key := [2]int{originalID, sampledInID}
+		if _, exists := seenSamples[key]; exists || originalID == sampledInID {
+			continue
+		}
+		seenSamples[key] = struct{}{}
+
+		graph.samplesUsed[sampledInID] = append(graph.samplesUsed[sampledInID], originalID)
+		graph.sampledIn[originalID] = append(graph.sampledIn[originalID], sampledInID)
+		graph.sampleCount++
+	}
+	if err := sampleRows.Err(); err != nil {
+		return nil, fmt.Errorf("error loading samples: %v", err)
+	}
+
+	return graph, nil
+}
+
+// loadCanonicalSongs reads the SongCanonical mapping into graph
+func loadCanonicalSongs(db *sql.DB, graph *sampleGraph) error {
+	canonicalRows, err := db.Query(`SELECT songId, canonicalId FROM SongCanonical`)
+	if err != nil {
+		return fmt.Errorf("error loading canonical songs: %v", err)
+	}
+	defer canonicalRows.Close()
+
+	for canonicalRows.Next() {
+		var songID, canonicalID int
+		if err := canonicalRows.Scan(&songID, &canonicalID); err != nil {
+			return fmt.Errorf("error scanning canonical song: %v", err)
+		}
+		graph.canonical[songID] = canonicalID
+		graph.duplicates[canonicalID] = append(graph.duplicates[canonicalID], songID)
+	}
+	if err := canonicalRows.Err(); err != nil {
+		return fmt.Errorf("error loading canonical songs: %v", err)
+	}
+	return nil
+}
+
+// canonicalID returns the canonical song of id
+func (g *sampleGraph) canonicalID(id int) int {
+	if canonicalID, ok := g.canonical[id]; ok {
+		return canonicalID
+	}
+	return id
+}
+
+// canonicalIDs resolves ids to their distinct canonical songs
+func (g *sampleGraph) canonicalIDs(ids []int) []int {
+	return resolveSongIDs(ids, g.canonical)
+}
+
+// sameSongs returns the songs that stand for id, like sameSongSQL: its
+// canonical song or, without the SongCanonical table, every song with its
+// title and release year
+func (g *sampleGraph) sameSongs(id int) []int {
+	song, ok := g.songs[id]
+	if !ok {
+		return nil
+	}
+	if ids, ok := g.byTitleYear[titleYearKey(song.title, song.releaseYear)]; ok {
+		return ids
+	}
+	return []int{g.canonicalID(id)}
+}
+
+// isDuplicate reports whether id is mapped to another canonical song
+func (g *sampleGraph) isDuplicate(id int) bool {
+	_, ok := g.canonical[id]
+	return ok
+}
+
+// node returns the song the same way GetSongWithDetails would: songs without
+// any artist are treated as missing.
+func (g *sampleGraph) node(id int) (models.SongNode, bool) {
+	song, ok := g.songs[id]
+	if !ok || len(song.artists) == 0 {
+		return models.SongNode{}, false
+	}
+
+	return models.SongNode{
+		ID:          song.id,
+		Title:       song.title,
+		Artists:     append([]models.Artist(nil), song.artists...),
+		Genres:      append([]string(nil), song.genres...),
+		ReleaseYear: int(song.releaseYear.Int64),
+	}, true
+}
+
+func (g *sampleGraph) nodes(ids []int) []models.SongNode {
+	var nodes []models.SongNode
+	for _, id := range ids {
+		if node, ok := g.node(id); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+type sampleHop struct {
+	to        int
+	direction models.TraversalDirection
+}
+
+// hopLoader returns a sampleHopLoader over the graph, keeping only
+// chronological samples when chronological is set
+func (g *sampleGraph) hopLoader(chronological bool) sampleHopLoader {
+	return func(_ context.Context, ids []int, direction models.TraversalDirection) (map[int][]sampleHop, error) {
+		hops := make(map[int][]sampleHop, len(ids))
+		for _, id := range ids {
+			for _, hop := range g.hops(id, direction) {
+				if chronological && !g.isChronological(id, hop) {
+					continue
+				}
+				hops[id] = append(hops[id], hop)
+			}
+		}
+		return hops, nil
+	}
+}
+
+// hops returns the songs one sample hop away in the given direction. A song
+// reachable both ways is only reported once, as an ancestor.
+func (g *sampleGraph) hops(id int, direction models.TraversalDirection) []sampleHop {
+	var hops []sampleHop
+	seen := make(map[int]struct{})
+	add := func(ids []int, hopDirection models.TraversalDirection) {
+		for _, to := range ids {
+			if _, exists := seen[to]; exists {
+				continue
+			}
+			seen[to] = struct{}{}
+			hops = append(hops, sampleHop{to: to, direction: hopDirection})
+		}
+	}
+
+	if direction != models.DirectionDescendants {
+		add(g.samplesUsed[id], models.DirectionAncestors)
+	}
+	if direction != models.DirectionAncestors {
+		add(g.sampledIn[id], models.DirectionDescendants)
+	}
+	return hops
+}
+
+// neighbors returns every song one sample hop away, in either direction.
+func (g *sampleGraph) neighbors(id int) []int {
+	hops := g.hops(id, models.DirectionBoth)
+	ids := make([]int, len(hops))
+	for i, hop := range hops {
+		ids[i] = hop.to
+	}
+	return ids
+}
+
+// artistSamples pairs the artists of a sampling song with the artists of the
+// song it samples, keeping the pairs where one side is in frontier
+func (g *sampleGraph) artistSamples(sampledInID, originalID int, frontier map[int]struct{}, mainArtistsOnly bool) []artistSample {
+	sampledIn, ok := g.songs[sampledInID]
+	if !ok {
+		return nil
+	}
+	original, ok := g.songs[originalID]
+	if !ok {
+		return nil
+	}
+
+	pair := models.SamplePair{
+		SampledInSongID: sampledIn.id,
+		SampledInTitle:  sampledIn.title,
+		OriginalSongID:  original.id,
+		OriginalTitle:   original.title,
+	}
+
+	var samples []artistSample
+	for _, sampler := range sampledIn.artists {
+		if mainArtistsOnly && !sampler.IsMain {
+			continue
+		}
+		for _, sampled := range original.artists {
+			if mainArtistsOnly && !sampled.IsMain {
+				continue
+			}
+			_, samplerInFrontier := frontier[sampler.ID]
+			_, sampledInFrontier := frontier[sampled.ID]
+			if !samplerInFrontier && !sampledInFrontier {
+				continue
+			}
+			samples = append(samples, artistSample{sampler: sampler, sampled: sampled, pair: pair})
+		}
+	}
+	return samples
+}
+
+// matchesGenres reports whether a song has any (or, with GenreMatchAll, every)
+// target genre and none of the excluded ones. Both lists must be lowercased.
+func (g *sampleGraph) matchesGenres(id int, targets, excluded []string, match models.GenreMatch) bool {
+	song, ok := g.songs[id]
+	if !ok {
+		return false
+	}
+	for _, genre := range excluded {
+		if _, exists := song.genreSet[genre]; exists {
+			return false
+		}
+	}
+	for _, genre := range targets {
+		_, exists := song.genreSet[genre]
+		if exists && match != models.GenreMatchAll {
+			return true
+		}
+		if !exists && match == models.GenreMatchAll {
+			return false
+		}
+	}
+	return match == models.GenreMatchAll && len(targets) > 0
+}
+
+// seedSongs resolves the search seeds to distinct canonical song IDs, keeping
+// the order in which they were first matched. Seeds with SongIDs skip the
+// title lookup.
+func (g *sampleGraph) seedSongs(songQueries []models.SongQuery) []int {
+	seen := make(map[int]struct{})
+	var ids []int
+	for _, query := range songQueries {
+		matched := query.SongIDs
+		if len(matched) == 0 {
+			matched = g.byTitleArtist[titleArtistKey(query.Title, query.Artist)]
+		}
+		for _, id := range matched {
+			if _, known := g.songs[id]; !known {
+				continue
+			}
+			id = g.canonicalID(id)
+			if _, exists := seen[id]; exists {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// clusterArtistNames returns the names credited on a canonical song and its
+// duplicates, like the merged candidates of the SQL repository
+func (g *sampleGraph) clusterArtistNames(id int) []string {
+	var names []string
+	for _, songID := range append([]int{id}, g.duplicates[id]...) {
+		song, ok := g.songs[songID]
+		if !ok {
+			continue
+		}
+		for _, artist := range song.artists {
+			if !containsString(names, artist.Name) {
+				names = append(names, artist.Name)
+			}
+		}
+	}
+	return names
+}
+
+func containsSong(path []int, id int) bool {
+	for _, songID := range path {
+		if songID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// titleArtistKey folds case the same way the samples DB collation does for
+// `s.title = ? AND a.name = ?`.
+func titleArtistKey(title, artist string) string {
+	return strings.ToLower(title) + "\x00" + strings.ToLower(artist)
+}
+
+// appendSongID appends id to ids unless it is already there. Loaded rows are
+// ordered by song, so only a duplicate resolved to an earlier canonical song
+// can repeat an ID that is not the last one.
+func appendSongID(ids []int, id int, duplicate bool) []int {
+	if len(ids) > 0 && ids[len(ids)-1] == id {
+		return ids
+	}
+	if duplicate && containsSong(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,753 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectSampleGraphLoad mocks the samples DB with a small graph:
+// 1 samples 2 and 6, both of which sample 3, and 4 (a duplicate of 1) samples 5.
+// 4 resolves to its canonical song 1, so 1 also samples 5.
+// 6 is dated before 3, so its sample of 3 is not chronological.
+func expectSampleGraphLoad(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT songId, canonicalId FROM SongCanonical").
+		WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}).
+			AddRow(4, 1))
+	expectSampleGraphTables(mock)
+}
+
+// expectSampleGraphTables mocks the tables of the graph expectSampleGraphLoad
+// describes, without the SongCanonical mapping
+func expectSampleGraphTables(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear FROM Song s").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear"}).
+			AddRow(1, "Seed Song", 2000).
+			AddRow(2, "Middle Song", 1990).
+			AddRow(3, "Jazz Song", 1970).
+			AddRow(4, "Seed Song", 2000).
+			AddRow(5, "Other Song", nil).
+			AddRow(6, "Alt Song", 1965))
+
+	mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+		WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+			AddRow(1, 101, "Seed Artist", true).
+			AddRow(2, 102, "Middle Artist", true).
+			AddRow(3, 103, "Jazz Artist", true).
+			AddRow(3, 104, "Featured Artist", false).
+			AddRow(4, 101, "Seed Artist", true).
+			AddRow(5, 105, "Other Artist", true).
+			AddRow(6, 106, "Alt Artist", true))
+
+	mock.ExpectQuery("SELECT sg.B, g.name FROM _SongToGenre sg").
+		WillReturnRows(sqlmock.NewRows([]string{"B", "name"}).
+			AddRow(1, "hip-hop").
+			AddRow(2, "hip-hop").
+			AddRow(3, "jazz").
+			AddRow(5, "jazz").
+			AddRow(6, "soul"))
+
+	mock.ExpectQuery("SELECT original_song_id, sampled_in_song_id FROM Sample").
+		WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}).
+			AddRow(2, 1).
+			AddRow(3, 2).
+			AddRow(5, 4).
+			AddRow(6, 1).
+			AddRow(3, 6))
+}
+
+func setupSongGraphIndex(t *testing.T) *SongGraphIndex {
+	db, mock := setupSongTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	expectSampleGraphLoad(mock)
+	index := NewSongGraphIndex(db, true)
+	require.NoError(t, index.Load(), "Index should load from the samples DB")
+	require.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	return index
+}
+
+func TestSongGraphIndex_OnReload(t *testing.T) {
+	// Arrange
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	expectSampleGraphLoad(mock)
+	expectSampleGraphLoad(mock)
+	index := NewSongGraphIndex(db, true)
+	require.NoError(t, index.Load())
+
+	cache := NewCachedSongRepository(index, db, true, 10, time.Minute)
+	index.OnReload(cache.Invalidate)
+	seeds := []models.SongQuery{{Title: "Seed Song", Artist: "Seed Artist"}}
+	opts := models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth}
+	_, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, index.Load())
+
+	// Assert
+	stats := cache.Stats()
+	assert.Equal(t, 0, stats.Entries, "Reloading the index should drop searches cached from the old snapshot")
+	assert.Equal(t, int64(1), stats.Invalidations)
+	require.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestSongGraphIndex_NotLoaded(t *testing.T) {
+	db, _ := setupSongTestDB(t)
+	defer db.Close()
+	index := NewSongGraphIndex(db, true)
+
+	_, err := index.FindSongsByGenreBFS(context.Background(), []models.SongQuery{{Title: "Seed Song", Artist: "Seed Artist"}}, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 2})
+	assert.Error(t, err, "Should return error before the index is loaded")
+}
+
+func TestSongGraphIndex_Cancelled(t *testing.T) {
+	index := setupSongGraphIndex(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := index.MatchSongs(ctx, models.SongQuery{Title: "Seed Song", Artist: "Seed Artist"})
+	assert.ErrorIs(t, err, context.Canceled, "MatchSongs should stop once cancelled")
+
+	_, err = index.GetSongsWithDetails(ctx, []int{1})
+	assert.ErrorIs(t, err, context.Canceled, "GetSongsWithDetails should stop once cancelled")
+
+	_, err = index.GetAllSampledSongs(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled, "GetAllSampledSongs should stop once cancelled")
+
+	_, err = index.SearchSongs(ctx, models.SongSearchQuery{Query: "song"})
+	assert.ErrorIs(t, err, context.Canceled, "SearchSongs should stop once cancelled")
+
+	_, err = index.CountSongsByGenreGroup(ctx, map[string][]string{"jazz": {"jazz"}})
+	assert.ErrorIs(t, err, context.Canceled, "CountSongsByGenreGroup should stop once cancelled")
+}
+
+func TestSongGraphIndex_GetSongIDsByTitleAndArtist(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Found_Songs", func(t *testing.T) {
+		songIDs, err := index.GetSongIDsByTitleAndArtist(context.Background(), "seed song", "SEED ARTIST")
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, songIDs, "Should match case-insensitively and resolve duplicates to their canonical song")
+	})
+
+	t.Run("No_Songs_Found", func(t *testing.T) {
+		songIDs, err := index.GetSongIDsByTitleAndArtist(context.Background(), "Unknown Song", "Unknown Artist")
+
+		require.NoError(t, err)
+		assert.Empty(t, songIDs)
+	})
+}
+
+func TestSongGraphIndex_GetSongIDsByArtist(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Found_Songs", func(t *testing.T) {
+		songIDs, err := index.GetSongIDsByArtist(context.Background(), "seed artist", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, songIDs, "Should resolve duplicates to their canonical song")
+	})
+
+	t.Run("Main_Artists_Only", func(t *testing.T) {
+		songIDs, err := index.GetSongIDsByArtist(context.Background(), "Featured Artist", 5)
+
+		require.NoError(t, err)
+		assert.Empty(t, songIDs, "Featured artists should not seed their songs")
+	})
+
+	t.Run("Unknown_Artist", func(t *testing.T) {
+		songIDs, err := index.GetSongIDsByArtist(context.Background(), "Unknown Artist", 5)
+
+		require.NoError(t, err)
+		assert.Empty(t, songIDs)
+	})
+}
+
+func TestSongGraphIndex_MatchSongs(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Normalized_Matches", func(t *testing.T) {
+		matches, err := index.MatchSongs(context.Background(), models.SongQuery{Title: "Seed Song - 2011 Remaster", Artist: "SEED ARTIST"})
+
+		require.NoError(t, err)
+		require.Len(t, matches, 1, "Duplicates should collapse into their canonical song")
+		assert.Equal(t, 1, matches[0].SongID)
+		assert.Equal(t, 0.95, matches[0].Confidence)
+	})
+
+	t.Run("Featured_Artist", func(t *testing.T) {
+		matches, err := index.MatchSongs(context.Background(), models.SongQuery{Title: "Jazz Song (feat. Someone)", Artist: "Featured Artist & Friends"})
+
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, 3, matches[0].SongID)
+		assert.Equal(t, "Featured Artist", matches[0].Artist)
+	})
+
+	t.Run("No_Matches", func(t *testing.T) {
+		matches, err := index.MatchSongs(context.Background(), models.SongQuery{Title: "Seed Song", Artist: "Jazz Artist"})
+
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
+
+func TestSongGraphIndex_GetSongWithDetails(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Get_Song_With_Details", func(t *testing.T) {
+		song, err := index.GetSongWithDetails(context.Background(), 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Jazz Song", song.Title)
+		assert.Equal(t, []string{"jazz"}, song.Genres)
+		require.Len(t, song.Artists, 2)
+		assert.True(t, song.Artists[0].IsMain, "Main artist should come first")
+	})
+
+	t.Run("Resolves_Duplicates", func(t *testing.T) {
+		song, err := index.GetSongWithDetails(context.Background(), 4)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, song.ID, "Should return the canonical song")
+	})
+
+	t.Run("Song_Not_Found", func(t *testing.T) {
+		song, err := index.GetSongWithDetails(context.Background(), 999)
+
+		assert.Error(t, err)
+		assert.Nil(t, song)
+	})
+}
+
+func TestSongGraphIndex_GetSongsWithDetails(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	songs, err := index.GetSongsWithDetails(context.Background(), []int{1, 3, 999})
+
+	require.NoError(t, err)
+	assert.Len(t, songs, 2, "Unknown songs should be left out")
+	assert.Equal(t, "Seed Song", songs[1].Title)
+	assert.Len(t, songs[3].Artists, 2)
+}
+
+func TestSongGraphIndex_SearchSongs(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Ranks_Prefix_Matches_First", func(t *testing.T) {
+		songs, err := index.SearchSongs(context.Background(), models.SongSearchQuery{Query: "song", Limit: 10})
+
+		require.NoError(t, err)
+		require.Len(t, songs, 5, "Duplicates should collapse into their canonical song")
+		assert.Equal(t, "Alt Song", songs[0].Title, "Substring matches should be ordered by title")
+
+		songs, err = index.SearchSongs(context.Background(), models.SongSearchQuery{Query: "jazz", Limit: 10})
+
+		require.NoError(t, err)
+		require.Len(t, songs, 1)
+		assert.Equal(t, 1970, songs[0].ReleaseYear)
+	})
+
+	t.Run("Filters_By_Artist", func(t *testing.T) {
+		songs, err := index.SearchSongs(context.Background(), models.SongSearchQuery{Artist: "featured", Limit: 10})
+
+		require.NoError(t, err)
+		require.Len(t, songs, 1)
+		assert.Equal(t, 3, songs[0].ID)
+	})
+
+	t.Run("Applies_Limit", func(t *testing.T) {
+		songs, err := index.SearchSongs(context.Background(), models.SongSearchQuery{Query: "seed", Limit: 1})
+
+		require.NoError(t, err)
+		require.Len(t, songs, 1)
+		assert.Equal(t, 1, songs[0].ID)
+	})
+}
+
+func TestSongGraphIndex_CountSongsByGenreGroup(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	counts, err := index.CountSongsByGenreGroup(context.Background(), map[string][]string{
+		"jazz":    {"Jazz", "soul"},
+		"hip-hop": {"hip-hop"},
+		"rock":    {"rock"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"jazz": 3, "hip-hop": 2, "rock": 0}, counts,
+		"Should count songs 3, 5 and 6 as jazz and songs 1 and 2 as hip-hop")
+}
+
+func TestSongGraphIndex_GetAllSampledSongs(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	sampledSongs, err := index.GetAllSampledSongs(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{2, 5, 6}, sampledSongs, "Should include neighbours of duplicate songs")
+}
+
+func TestSongGraphIndex_WithoutSongCanonical(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	expectSampleGraphTables(mock)
+	index := NewSongGraphIndex(db, false)
+	require.NoError(t, index.Load(), "Index should load from the samples DB")
+
+	t.Run("Get_All_Sampled_Songs", func(t *testing.T) {
+		sampledSongs, err := index.GetAllSampledSongs(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 6, 5}, sampledSongs, "Should merge songs with the same title and release year")
+	})
+
+	t.Run("Get_Sample_Lineage", func(t *testing.T) {
+		lineage, err := index.GetSampleLineage(context.Background(), 4)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 5, 6}, lineage.SamplesUsed, "Should merge songs with the same title and release year")
+		assert.Empty(t, lineage.SampledIn)
+	})
+}
+
+func TestSongGraphIndex_GetSampleLineage(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	lineage, err := index.GetSampleLineage(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 5, 6}, lineage.SamplesUsed, "Should include samples of duplicate songs")
+	assert.Empty(t, lineage.SampledIn)
+
+	lineage, err = index.GetSampleLineage(context.Background(), 3)
+
+	require.NoError(t, err)
+	assert.Empty(t, lineage.SamplesUsed)
+	assert.Equal(t, []int{2, 6}, lineage.SampledIn)
+}
+
+func TestSongGraphIndex_FindSongsByGenreBFS(t *testing.T) {
+	index := setupSongGraphIndex(t)
+	seeds := []models.SongQuery{{Title: "Seed Song", Artist: "Seed Artist"}}
+
+	t.Run("Find_Songs_By_Genre", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		// Jazz Song is sampled twice, which outweighs its extra hop
+		assert.Equal(t, 2, results[0].Distance)
+		assert.Equal(t, 1, results[0].SourceSong.ID)
+		assert.Equal(t, "Jazz Song", results[0].MatchedSong.Title)
+		require.Len(t, results[0].Path, 3)
+		assert.Equal(t, 2, results[0].Path[1].ID)
+		assert.Equal(t, []models.TraversalDirection{models.DirectionAncestors, models.DirectionAncestors}, results[0].HopDirections)
+		assert.Equal(t, 0.733, results[0].Score)
+
+		assert.Equal(t, 1, results[1].Distance)
+		assert.Equal(t, 1, results[1].SourceSong.ID, "Samples of duplicates should belong to the canonical song")
+		assert.Equal(t, "Other Song", results[1].MatchedSong.Title)
+		assert.Equal(t, 0.726, results[1].Score)
+	})
+
+	t.Run("Ancestors_Only", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 4, Direction: models.DirectionAncestors})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2, "Walks should never turn back towards the seed")
+		assert.Equal(t, "Jazz Song", results[0].MatchedSong.Title)
+		assert.Equal(t, "Other Song", results[1].MatchedSong.Title)
+	})
+
+	t.Run("Descendants_Only", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), []models.SongQuery{{Title: "Jazz Song", Artist: "Jazz Artist"}}, models.NewGenreFilter("hip-hop"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionDescendants})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Middle Song", results[0].MatchedSong.Title)
+		assert.Equal(t, "Seed Song", results[1].MatchedSong.Title)
+		assert.Equal(t, []models.TraversalDirection{models.DirectionDescendants, models.DirectionDescendants}, results[1].HopDirections)
+	})
+
+	t.Run("Prunes_Revisited_Songs", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("Jazz"), models.SearchOptions{MaxDepth: 4, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		distances := make([]int, len(results))
+		for i, result := range results {
+			distances[i] = result.Distance
+		}
+		assert.ElementsMatch(t, []int{1, 2}, distances, "Should return each match once at its shortest distance")
+	})
+
+	t.Run("Alternative_Paths", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 4, Direction: models.DirectionAncestors, MaxPaths: 3})
+
+		require.NoError(t, err)
+		require.Len(t, results, 3, "Should return both simple paths to Jazz Song and the only one to Other Song")
+		assert.Equal(t, 3, results[0].MatchedSong.ID)
+		assert.Equal(t, 3, results[1].MatchedSong.ID)
+		assert.NotEqual(t, results[0].Path[1].ID, results[1].Path[1].ID, "Alternative paths should differ")
+	})
+
+	t.Run("Matched_Song_IDs", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), []models.SongQuery{{Title: "Seed Song - Live", Artist: "Seed Artist", SongIDs: []int{4}}}, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2, "Should search from the canonical song of the matched song")
+		assert.Equal(t, 1, results[0].SourceSong.ID)
+		assert.Equal(t, 1, results[1].SourceSong.ID)
+	})
+
+	t.Run("Any_Genre", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz", "Soul"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		matched := make([]int, len(results))
+		for i, result := range results {
+			matched[i] = result.MatchedSong.ID
+		}
+		assert.ElementsMatch(t, []int{5, 6, 3}, matched, "Should match songs with either genre")
+	})
+
+	t.Run("All_Genres", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.GenreFilter{Genres: []string{"jazz", "soul"}, Match: models.GenreMatchAll}, models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Empty(t, results, "No song is tagged with both genres")
+	})
+
+	t.Run("Excluded_Genres", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.GenreFilter{Genres: []string{"jazz", "soul"}, Exclude: []string{"SOUL"}}, models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Jazz Song", results[0].MatchedSong.Title)
+		assert.Equal(t, "Other Song", results[1].MatchedSong.Title)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, Limit: 1})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Should keep only the best result")
+		assert.Equal(t, "Jazz Song", results[0].MatchedSong.Title)
+	})
+
+	t.Run("Excluded_Matches", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{
+			MaxDepth:  2,
+			Direction: models.DirectionBoth,
+			Exclude:   models.SearchExclusions{Artists: []string{"Other Artist"}},
+			MaxRows:   1,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Songs by excluded artists should not take up the row cap")
+		assert.Equal(t, "Jazz Song", results[0].MatchedSong.Title)
+	})
+
+	t.Run("Year_Range", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{
+			MaxDepth:  2,
+			Direction: models.DirectionBoth,
+			Years:     models.YearRange{From: 1970, To: 1979},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Songs without a release year should not match a year range")
+		assert.Equal(t, "Jazz Song", results[0].MatchedSong.Title)
+		assert.Equal(t, 1970, results[0].MatchedSong.ReleaseYear)
+	})
+
+	t.Run("Chronological_Paths", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{
+			MaxDepth:      4,
+			Direction:     models.DirectionAncestors,
+			MaxPaths:      3,
+			Chronological: true,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2, "Should skip the path through the inverted sample")
+		assert.Equal(t, 3, results[0].MatchedSong.ID)
+		assert.Equal(t, 2, results[0].Path[1].ID)
+		assert.Equal(t, 5, results[1].MatchedSong.ID, "Samples with an unknown year should still be followed")
+	})
+
+	t.Run("Row_Cap", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), models.SearchOptions{
+			MaxDepth:  2,
+			Direction: models.DirectionBoth,
+			MaxRows:   1,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Should stop at the row cap")
+		assert.Equal(t, "Other Song", results[0].MatchedSong.Title, "Should keep the nearest match")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := index.FindSongsByGenreBFS(ctx, seeds, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, results)
+	})
+
+	t.Run("No_Matching_Songs", func(t *testing.T) {
+		results, err := index.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("classical"), models.SearchOptions{MaxDepth: 3, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestSongGraphIndex_FindSamplePaths(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Shortest_Paths", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{1}, []int{3}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionBoth, MaxPaths: 3})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2, "Should return both chains of the shortest length")
+		for _, result := range results {
+			assert.Equal(t, 2, result.Distance)
+			assert.Equal(t, 1, result.SourceSong.ID)
+			assert.Equal(t, 3, result.MatchedSong.ID)
+			assert.Equal(t, []models.TraversalDirection{models.DirectionAncestors, models.DirectionAncestors}, result.HopDirections)
+		}
+		assert.NotEqual(t, results[0].Path[1].ID, results[1].Path[1].ID)
+	})
+
+	t.Run("Respects_Direction", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{3}, []int{1}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionAncestors})
+
+		require.NoError(t, err)
+		assert.Empty(t, results, "Jazz Song samples nothing")
+
+		results, err = index.FindSamplePaths(context.Background(), []int{3}, []int{1}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionDescendants})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, []models.TraversalDirection{models.DirectionDescendants, models.DirectionDescendants}, results[0].HopDirections)
+	})
+
+	t.Run("Respects_Max_Depth", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{1}, []int{3}, models.SearchOptions{MaxDepth: 1, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Caps_Rows", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{1}, []int{3}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionBoth, MaxRows: 2})
+
+		assert.ErrorIs(t, err, models.ErrSearchOverBudget, "Should stop once the search reached MaxRows songs")
+		assert.Nil(t, results)
+	})
+
+	t.Run("Resolves_Duplicates", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{4}, []int{5}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, 1, results[0].SourceSong.ID, "Should start from the canonical song")
+		assert.Equal(t, 1, results[0].Distance)
+	})
+
+	t.Run("Unknown_Song", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{1}, []int{999}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Same_Song", func(t *testing.T) {
+		results, err := index.FindSamplePaths(context.Background(), []int{2}, []int{2}, models.SearchOptions{MaxDepth: 6, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Zero(t, results[0].Distance)
+	})
+}
+
+func TestSongGraphIndex_GetArtistNetwork(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Direct_Edges", func(t *testing.T) {
+		network, err := index.GetArtistNetwork(context.Background(), 101, models.ArtistNetworkOptions{Depth: 1})
+
+		require.NoError(t, err)
+		require.Len(t, network.Edges, 3)
+		for _, edge := range network.Edges {
+			assert.Equal(t, 101, edge.FromArtistID, "Seed Artist samples everyone else")
+			assert.Equal(t, 1, edge.Count)
+			require.Len(t, edge.Examples, 1)
+		}
+		assert.Len(t, network.Artists, 4)
+		assert.Equal(t, "Seed Artist", network.Artists[101].Name)
+	})
+
+	t.Run("Expands_Depth", func(t *testing.T) {
+		network, err := index.GetArtistNetwork(context.Background(), 101, models.ArtistNetworkOptions{Depth: 2})
+
+		require.NoError(t, err)
+		assert.Len(t, network.Edges, 7, "Should add the artists sampled by Middle Artist and Alt Artist")
+	})
+
+	t.Run("Main_Artists_Only", func(t *testing.T) {
+		network, err := index.GetArtistNetwork(context.Background(), 101, models.ArtistNetworkOptions{Depth: 2, MainArtistsOnly: true})
+
+		require.NoError(t, err)
+		assert.Len(t, network.Edges, 5, "Should skip the featured artist on Jazz Song")
+		assert.NotContains(t, network.Artists, 104)
+	})
+
+	t.Run("Caps_Nodes", func(t *testing.T) {
+		network, err := index.GetArtistNetwork(context.Background(), 101, models.ArtistNetworkOptions{Depth: 2, MaxNodes: 3})
+
+		require.NoError(t, err)
+		assert.Len(t, network.Artists, 3)
+		for _, edge := range network.Edges {
+			assert.Contains(t, network.Artists, edge.FromArtistID, "Should only keep edges between kept artists")
+			assert.Contains(t, network.Artists, edge.ToArtistID, "Should only keep edges between kept artists")
+		}
+		assert.True(t, network.Truncated)
+	})
+
+	t.Run("Unknown_Artist", func(t *testing.T) {
+		network, err := index.GetArtistNetwork(context.Background(), 999, models.ArtistNetworkOptions{Depth: 1})
+
+		require.NoError(t, err)
+		assert.Nil(t, network)
+	})
+}
+
+func TestSongGraphIndex_GetSongNeighborhood(t *testing.T) {
+	index := setupSongGraphIndex(t)
+
+	t.Run("Radius_One", func(t *testing.T) {
+		neighborhood, err := index.GetSongNeighborhood(context.Background(), 1, models.NeighborhoodOptions{Radius: 1, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		require.Len(t, neighborhood.Songs, 4)
+		assert.Equal(t, 1, neighborhood.Songs[0].ID, "The song itself should come first")
+		assert.ElementsMatch(t, []models.SampleEdge{
+			{SampledInID: 1, OriginalID: 2},
+			{SampledInID: 1, OriginalID: 5},
+			{SampledInID: 1, OriginalID: 6},
+		}, neighborhood.Edges)
+		assert.False(t, neighborhood.Truncated)
+	})
+
+	t.Run("Expands_Radius", func(t *testing.T) {
+		neighborhood, err := index.GetSongNeighborhood(context.Background(), 1, models.NeighborhoodOptions{Radius: 2, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Len(t, neighborhood.Songs, 5, "Should reach Jazz Song through Middle Song and Alt Song")
+		assert.Len(t, neighborhood.Edges, 5)
+	})
+
+	t.Run("Respects_Direction", func(t *testing.T) {
+		neighborhood, err := index.GetSongNeighborhood(context.Background(), 3, models.NeighborhoodOptions{Radius: 1, Direction: models.DirectionAncestors})
+
+		require.NoError(t, err)
+		assert.Len(t, neighborhood.Songs, 1, "Jazz Song samples nothing")
+
+		neighborhood, err = index.GetSongNeighborhood(context.Background(), 3, models.NeighborhoodOptions{Radius: 1, Direction: models.DirectionDescendants})
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []models.SampleEdge{
+			{SampledInID: 2, OriginalID: 3},
+			{SampledInID: 6, OriginalID: 3},
+		}, neighborhood.Edges, "Edges should point from the sampling song")
+	})
+
+	t.Run("Caps_Nodes", func(t *testing.T) {
+		neighborhood, err := index.GetSongNeighborhood(context.Background(), 4, models.NeighborhoodOptions{Radius: 2, Direction: models.DirectionBoth, MaxNodes: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, neighborhood.SongID, "Should expand from the canonical song")
+		assert.Len(t, neighborhood.Songs, 2)
+		assert.Len(t, neighborhood.Edges, 1, "Should only keep edges between kept songs")
+		assert.True(t, neighborhood.Truncated)
+	})
+
+	t.Run("Unknown_Song", func(t *testing.T) {
+		neighborhood, err := index.GetSongNeighborhood(context.Background(), 999, models.NeighborhoodOptions{Radius: 1, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Nil(t, neighborhood)
+	})
+}
+
+func TestSongGraphIndex_GetGenreProfile(t *testing.T) {
+	index := setupSongGraphIndex(t)
+	seeds := []models.SongQuery{{Title: "Seed Song", Artist: "Seed Artist"}}
+
+	t.Run("Counts_First_Reach", func(t *testing.T) {
+		profile, err := index.GetGenreProfile(context.Background(), seeds, models.SearchOptions{MaxDepth: 3, Direction: models.DirectionBoth})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.GenreProfileLevel{
+			{Depth: 1, GenreSets: []models.GenreSetCount{
+				{Genres: []string{"hip-hop"}, Songs: 1},
+				{Genres: []string{"jazz"}, Songs: 1},
+				{Genres: []string{"soul"}, Songs: 1},
+			}},
+			{Depth: 2, GenreSets: []models.GenreSetCount{
+				{Genres: []string{"jazz"}, Songs: 1},
+			}},
+		}, profile.Levels, "Jazz Song should count once although two paths reach it")
+		assert.False(t, profile.Truncated)
+	})
+
+	t.Run("Respects_Direction", func(t *testing.T) {
+		profile, err := index.GetGenreProfile(context.Background(), []models.SongQuery{{SongIDs: []int{3}}}, models.SearchOptions{MaxDepth: 1, Direction: models.DirectionAncestors})
+
+		require.NoError(t, err)
+		assert.Empty(t, profile.Levels, "Jazz Song samples nothing")
+	})
+
+	t.Run("Filters_Years", func(t *testing.T) {
+		profile, err := index.GetGenreProfile(context.Background(), seeds, models.SearchOptions{
+			MaxDepth:  2,
+			Direction: models.DirectionBoth,
+			Years:     models.YearRange{From: 1960, To: 1969},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, profile.Levels, 1, "Only Alt Song was released in the 1960s")
+		assert.Equal(t, 1, profile.Levels[0].Songs())
+		assert.Equal(t, []string{"soul"}, profile.Levels[0].GenreSets[0].Genres)
+	})
+
+	t.Run("Caps_Rows", func(t *testing.T) {
+		profile, err := index.GetGenreProfile(context.Background(), seeds, models.SearchOptions{MaxDepth: 3, Direction: models.DirectionBoth, MaxRows: 2})
+
+		require.NoError(t, err)
+		require.Len(t, profile.Levels, 1)
+		assert.Equal(t, 2, profile.Levels[0].Songs(), "Should stop after MaxRows songs")
+		assert.True(t, profile.Truncated)
+	})
+
+	t.Run("Unknown_Seeds", func(t *testing.T) {
+		profile, err := index.GetGenreProfile(context.Background(), []models.SongQuery{{SongIDs: []int{999}}}, models.SearchOptions{MaxDepth: 2})
+
+		require.NoError(t, err)
+		assert.Empty(t, profile.Levels)
+	})
+}
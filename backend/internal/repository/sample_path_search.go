@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// sampleHopLoader returns the hops out of every song in ids, in the given
+// direction. Songs without hops may be left out of the map.
+type sampleHopLoader func(ctx context.Context, ids []int, direction models.TraversalDirection) (map[int][]sampleHop, error)
+
+// samplePath is a chain of songs joined by Sample edges
+type samplePath struct {
+	ids  []int
+	hops []models.TraversalDirection
+}
+
+// reverseDirection returns the direction that walks the same Sample edges
+// backwards
+func reverseDirection(direction models.TraversalDirection) models.TraversalDirection {
+	switch direction {
+	case models.DirectionAncestors:
+		return models.DirectionDescendants
+	case models.DirectionDescendants:
+		return models.DirectionAncestors
+	}
+	return models.DirectionBoth
+}
+
+// flipHop turns the direction of a hop walked backwards into the direction of
+// the same hop walked forwards
+func flipHop(direction models.TraversalDirection) models.TraversalDirection {
+	if direction == models.DirectionAncestors {
+		return models.DirectionDescendants
+	}
+	return models.DirectionAncestors
+}
+
+// findSamplePaths runs a bidirectional BFS between two sets of songs and
+// returns up to opts.PathsPerMatch() shortest chains no longer than
+// opts.MaxDepth. The search grows whichever side has the smaller frontier, one
+// level at a time, and stops at the first level where the two sides meet, or
+// with ctx's error once ctx is cancelled. It fails with a
+// models.ErrSearchOverBudget error once the two sides reached more than
+// opts.MaxRows songs without meeting.
+func findSamplePaths(ctx context.Context, load sampleHopLoader, fromIDs, toIDs []int, opts models.SearchOptions) ([]samplePath, error) {
+	maxPaths := opts.PathsPerMatch()
+
+	distFrom := make(map[int]int)
+	distTo := make(map[int]int)
+	for _, id := range fromIDs {
+		distFrom[id] = 0
+	}
+	for _, id := range toIDs {
+		distTo[id] = 0
+	}
+
+	// A song in both sets is trivially connected to itself
+	var paths []samplePath
+	for _, id := range uniqueSongIDs(fromIDs) {
+		if _, ok := distTo[id]; ok && len(paths) < maxPaths {
+			paths = append(paths, samplePath{ids: []int{id}})
+		}
+	}
+	if len(paths) > 0 {
+		return paths, nil
+	}
+
+	// edges holds every Sample edge seen so far, oriented from the "from" side
+	// towards the "to" side
+	edges := make(map[int][]sampleHop)
+	seenEdges := make(map[[2]int]struct{})
+	addEdge := func(from, to int, direction models.TraversalDirection) {
+		key := [2]int{from, to}
+		if _, exists := seenEdges[key]; exists {
+			return
+		}
+		seenEdges[key] = struct{}{}
+		edges[from] = append(edges[from], sampleHop{to: to, direction: direction})
+	}
+
+	forward := uniqueSongIDs(fromIDs)
+	backward := uniqueSongIDs(toIDs)
+	depthFrom, depthTo := 0, 0
+	met := false
+
+	for !met && depthFrom+depthTo < opts.MaxDepth && len(forward) > 0 && len(backward) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.MaxRows > 0 && len(distFrom)+len(distTo) > opts.MaxRows {
+			return nil, fmt.Errorf("%w: the path search reached more than %d songs, lower maxDepth", models.ErrSearchOverBudget, opts.MaxRows)
+		}
+		if len(forward) <= len(backward) {
+			hops, err := load(ctx, forward, opts.Direction)
+			if err != nil {
+				return nil, err
+			}
+			depthFrom++
+			var next []int
+			for _, id := range forward {
+				for _, hop := range hops[id] {
+					addEdge(id, hop.to, hop.direction)
+					if _, visited := distFrom[hop.to]; visited {
+						continue
+					}
+					distFrom[hop.to] = depthFrom
+					next = append(next, hop.to)
+					if _, reached := distTo[hop.to]; reached {
+						met = true
+					}
+				}
+			}
+			forward = next
+		} else {
+			hops, err := load(ctx, backward, reverseDirection(opts.Direction))
+			if err != nil {
+				return nil, err
+			}
+			depthTo++
+			var next []int
+			for _, id := range backward {
+				for _, hop := range hops[id] {
+					addEdge(hop.to, id, flipHop(hop.direction))
+					if _, visited := distTo[hop.to]; visited {
+						continue
+					}
+					distTo[hop.to] = depthTo
+					next = append(next, hop.to)
+					if _, reached := distFrom[hop.to]; reached {
+						met = true
+					}
+				}
+			}
+			backward = next
+		}
+	}
+
+	if !met {
+		return nil, nil
+	}
+
+	// Every song at position i of a shortest chain is exactly i hops from the
+	// "from" side (if that side got that far) and length-i hops from the "to"
+	// side (if that side got that far).
+	length := depthFrom + depthTo
+	onShortestPath := func(id, position int) bool {
+		if position <= depthFrom {
+			if dist, ok := distFrom[id]; !ok || dist != position {
+				return false
+			}
+		}
+		if length-position <= depthTo {
+			if dist, ok := distTo[id]; !ok || dist != length-position {
+				return false
+			}
+		}
+		return true
+	}
+
+	var walk func(path []int, hops []models.TraversalDirection)
+	walk = func(path []int, hops []models.TraversalDirection) {
+		if len(paths) >= maxPaths {
+			return
+		}
+		if len(path) == length+1 {
+			paths = append(paths, samplePath{
+				ids:  append([]int(nil), path...),
+				hops: append([]models.TraversalDirection(nil), hops...),
+			})
+			return
+		}
+		for _, hop := range edges[path[len(path)-1]] {
+			if !onShortestPath(hop.to, len(path)) {
+				continue
+			}
+			walk(append(path, hop.to), append(hops, hop.direction))
+		}
+	}
+
+	for _, id := range uniqueSongIDs(fromIDs) {
+		walk([]int{id}, nil)
+	}
+
+	return paths, nil
+}
@@ -1,32 +1,42 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
 )
 
 type SongRepository struct {
-	db *sql.DB
+	db samplesDB
 }
 
-func NewSongRepository(db *sql.DB) *SongRepository {
-	return &SongRepository{db: db}
+// songDetailsBatchSize bounds the IN (...) list of a single hydration query
+const songDetailsBatchSize = 1000
+
+// NewSongRepository reads songs from the samples DB. canonical says whether it
+// has the SongCanonical table; without it, duplicate songs are not merged.
+func NewSongRepository(db *sql.DB, canonical bool) *SongRepository {
+	return &SongRepository{db: samplesDB{DB: db, canonical: canonical}}
 }
 
-func (r *SongRepository) GetSongIDsByTitleAndArtist(title, artist string) ([]int, error) {
+// GetSongIDsByTitleAndArtist returns the canonical songs of every song with
+// the title and artist
+func (r *SongRepository) GetSongIDsByTitleAndArtist(ctx context.Context, title, artist string) ([]int, error) {
+	canonicalJoin, canonicalID := r.db.canonicalSongSQL("s.id")
 	query := `
-        SELECT DISTINCT s.id
+        SELECT DISTINCT ` + canonicalID + `
         FROM Song s
         JOIN SongArtist sa ON s.id = sa.songId
         JOIN Artist a ON sa.artistId = a.id
+        ` + canonicalJoin + `
         WHERE s.title = ? AND a.name = ?
     `
 
-	rows, err := r.db.Query(query, title, artist)
+	rows, err := r.db.QueryContext(ctx, query, title, artist)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting song ids by title and artist: %v", err)
@@ -47,12 +57,112 @@ func (r *SongRepository) GetSongIDsByTitleAndArtist(title, artist string) ([]int
 	return songIDs, nil
 }
 
-func (r *SongRepository) GetSongWithDetails(SongID int) (*models.SongNode, error) {
+// GetSongIDsByArtist returns the canonical songs an artist is a main artist
+// on, the most sampled and sampling first, at most limit of them
+func (r *SongRepository) GetSongIDsByArtist(ctx context.Context, artist string, limit int) ([]int, error) {
+	canonicalJoin, canonicalID := r.db.canonicalSongSQL("s.id")
+	query := `
+        WITH ` + r.db.canonicalSampleCTE() + `,
+        ArtistSong AS (
+            SELECT DISTINCT ` + canonicalID + ` as id
+            FROM Song s
+            JOIN SongArtist sa ON s.id = sa.songId
+            JOIN Artist a ON sa.artistId = a.id
+            ` + canonicalJoin + `
+            WHERE a.name = ? AND sa.isMainArtist = 1
+        )
+        SELECT ars.id
+        FROM ArtistSong ars
+        LEFT JOIN CanonicalSample cs ON cs.original_song_id = ars.id OR cs.sampled_in_song_id = ars.id
+        GROUP BY ars.id
+        ORDER BY COUNT(cs.original_song_id) DESC, ars.id
+        LIMIT ?
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, artist, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting song ids by artist: %v", err)
+	}
+	defer rows.Close()
+
+	var songIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning song id: %v", err)
+		}
+		songIDs = append(songIDs, id)
+	}
+
+	return songIDs, nil
+}
+
+// MatchSongs finds songs that may be what the query refers to even when the
+// title carries remaster/feat./live decorations or the artist is spelled
+// differently. Candidates are read by matchCandidatesSQL and scored in Go.
+// Duplicates of a song are merged into one candidate for their canonical song.
+func (r *SongRepository) MatchSongs(ctx context.Context, query models.SongQuery) ([]models.SongMatch, error) {
+	title := stripTitleDecorations(query.Title)
+	if title == "" {
+		return nil, nil
+	}
+
+	candidateSQL, params := matchCandidatesSQL(title, artistSpellings(query.Artist))
+	canonicalJoin, canonicalID := r.db.canonicalSongSQL("s.id")
+	candidateQuery := `
+		SELECT
+			` + canonicalID + ` as canonical_id,
+			s.title,
+			a.name
+		FROM (
+			` + candidateSQL + `
+		) m
+		JOIN Song s ON s.id = m.id
+		JOIN SongArtist sa ON s.id = sa.songId
+		JOIN Artist a ON sa.artistId = a.id
+		` + canonicalJoin + `
+		ORDER BY canonical_id, s.id, sa.isMainArtist DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, candidateQuery, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error matching songs: %v", err)
+	}
+	defer rows.Close()
+
+	var candidates []matchCandidate
+	for rows.Next() {
+		var id int
+		var title, artist string
+		if err := rows.Scan(&id, &title, &artist); err != nil {
+			return nil, fmt.Errorf("error scanning song match: %v", err)
+		}
+		if n := len(candidates); n > 0 && candidates[n-1].id == id {
+			if !containsString(candidates[n-1].artists, artist) {
+				candidates[n-1].artists = append(candidates[n-1].artists, artist)
+			}
+			continue
+		}
+		candidates = append(candidates, matchCandidate{id: id, title: title, artists: []string{artist}})
+	}
+
+	return rankSongMatches(query, candidates), nil
+}
+
+// GetSongWithDetails returns the canonical song of SongID along with its
+// artists and genres
+func (r *SongRepository) GetSongWithDetails(ctx context.Context, SongID int) (*models.SongNode, error) {
+	canonical, err := r.canonicalSongIDs(ctx, []int{SongID})
+	if err != nil {
+		return nil, err
+	}
+	SongID = resolveSongIDs([]int{SongID}, canonical)[0]
 
 	query := `
 		SELECT 
 			s.id,
 			s.title,
+			s.releaseYear,
 			a.name as artist_name,
 			a.id as artist_id,
 			GROUP_CONCAT(DISTINCT g.name) as genres
@@ -66,27 +176,30 @@ func (r *SongRepository) GetSongWithDetails(SongID int) (*models.SongNode, error
 	`
 
 	var genres sql.NullString
+	var releaseYear sql.NullInt64
 	var artistName string
 	var artistID int
 	song := &models.SongNode{
 		ID: SongID,
 	}
 
-	err := r.db.QueryRow(query, SongID).Scan(
+	err = r.db.QueryRowContext(ctx, query, SongID).Scan(
 		&song.ID,
 		&song.Title,
+		&releaseYear,
 		&artistName,
 		&artistID,
 		&genres,
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("error getting song: %v", err)
+		return nil, fmt.Errorf("error getting song: %w", err)
 	}
 
 	if genres.Valid {
 		song.Genres = strings.Split(genres.String, ",")
 	}
+	song.ReleaseYear = int(releaseYear.Int64)
 
 	artistQuery := `
         SELECT 
@@ -99,7 +212,7 @@ func (r *SongRepository) GetSongWithDetails(SongID int) (*models.SongNode, error
         ORDER BY sa.isMainArtist DESC
     `
 
-	artistRows, err := r.db.Query(artistQuery, SongID)
+	artistRows, err := r.db.QueryContext(ctx, artistQuery, SongID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting artists: %v", err)
 	}
@@ -116,36 +229,291 @@ func (r *SongRepository) GetSongWithDetails(SongID int) (*models.SongNode, error
 	return song, nil
 }
 
-func (r *SongRepository) GetAllSampledSongs(songID int) ([]int, error) {
+// GetSongsWithDetails hydrates a whole set of songs with two queries per
+// songDetailsBatchSize IDs. Songs without artists are left out, matching
+// GetSongWithDetails which fails for them. Unlike GetSongWithDetails, IDs are
+// hydrated as given, without resolving duplicates to their canonical song, so
+// callers pass the canonical IDs searches and lineages return.
+func (r *SongRepository) GetSongsWithDetails(ctx context.Context, ids []int) (map[int]*models.SongNode, error) {
+	uniqueIDs := uniqueSongIDs(ids)
+	songs := make(map[int]*models.SongNode, len(uniqueIDs))
+
+	for start := 0; start < len(uniqueIDs); start += songDetailsBatchSize {
+		end := min(start+songDetailsBatchSize, len(uniqueIDs))
+		if err := r.hydrateSongs(ctx, uniqueIDs[start:end], songs); err != nil {
+			return nil, err
+		}
+	}
+
+	return songs, nil
+}
+
+func (r *SongRepository) hydrateSongs(ctx context.Context, ids []int, songs map[int]*models.SongNode) error {
+	placeholders := inPlaceholders(len(ids))
+	args := intArgs(ids)
+
+	songQuery := `
+		SELECT
+			s.id,
+			s.title,
+			s.releaseYear,
+			GROUP_CONCAT(DISTINCT g.name) as genres
+		FROM Song s
+		LEFT JOIN _SongToGenre sg ON sg.B = s.id
+		LEFT JOIN Genre g ON g.id = sg.A
+		WHERE s.id IN (` + placeholders + `)
+		GROUP BY s.id, s.title, s.releaseYear
+	`
+
+	songRows, err := r.db.QueryContext(ctx, songQuery, args...)
+	if err != nil {
+		return fmt.Errorf("error getting songs: %v", err)
+	}
+	defer songRows.Close()
+
+	batch := make(map[int]*models.SongNode, len(ids))
+	for songRows.Next() {
+		var genres sql.NullString
+		var releaseYear sql.NullInt64
+		song := &models.SongNode{}
+		if err := songRows.Scan(&song.ID, &song.Title, &releaseYear, &genres); err != nil {
+			return fmt.Errorf("error scanning song: %v", err)
+		}
+		if genres.Valid {
+			song.Genres = strings.Split(genres.String, ",")
+		}
+		song.ReleaseYear = int(releaseYear.Int64)
+		batch[song.ID] = song
+	}
+
+	artistQuery := `
+		SELECT
+			sa.songId,
+			a.id,
+			a.name,
+			sa.isMainArtist
+		FROM SongArtist sa
+		JOIN Artist a ON sa.artistId = a.id
+		WHERE sa.songId IN (` + placeholders + `)
+		ORDER BY sa.songId, sa.isMainArtist DESC
+	`
+
+	artistRows, err := r.db.QueryContext(ctx, artistQuery, args...)
+	if err != nil {
+		return fmt.Errorf("error getting artists: %v", err)
+	}
+	defer artistRows.Close()
+
+	for artistRows.Next() {
+		var songID int
+		var artist models.Artist
+		if err := artistRows.Scan(&songID, &artist.ID, &artist.Name, &artist.IsMain); err != nil {
+			return fmt.Errorf("error scanning artist: %v", err)
+		}
+		if song, ok := batch[songID]; ok {
+			song.Artists = append(song.Artists, artist)
+		}
+	}
+
+	for id, song := range batch {
+		if len(song.Artists) > 0 {
+			songs[id] = song
+		}
+	}
+
+	return nil
+}
+
+// CountSongsByGenreGroup counts, in one query, the distinct canonical songs
+// with any of their duplicates tagged with any of the genres of each group of
+// genreGroups. Groups without songs count 0.
+func (r *SongRepository) CountSongsByGenreGroup(ctx context.Context, genreGroups map[string][]string) (map[string]int, error) {
+	counts := make(map[string]int, len(genreGroups))
+	for group := range genreGroups {
+		counts[group] = 0
+	}
+
+	groupOf, genres := genreGroupIndex(genreGroups)
+	if len(genres) == 0 {
+		return counts, nil
+	}
+
+	cases := make([]string, len(genres))
+	params := make([]interface{}, 0, 3*len(genres))
+	for i, genre := range genres {
+		cases[i] = "WHEN ? THEN ?"
+		params = append(params, genre, groupOf[genre])
+	}
+	for _, genre := range genres {
+		params = append(params, genre)
+	}
+
+	canonicalJoin, canonicalID := r.db.canonicalSongSQL("sg.B")
+	query := `
+        SELECT
+            CASE LOWER(g.name) ` + strings.Join(cases, " ") + ` END as group_id,
+            COUNT(DISTINCT ` + canonicalID + `)
+        FROM _SongToGenre sg
+        JOIN Genre g ON g.id = sg.A
+        ` + canonicalJoin + `
+        WHERE g.name IN (` + inPlaceholders(len(genres)) + `)
+        GROUP BY group_id
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error counting songs by genre group: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group sql.NullString
+		var count int
+		if err := rows.Scan(&group, &count); err != nil {
+			return nil, fmt.Errorf("error scanning genre group count: %v", err)
+		}
+		if group.Valid {
+			counts[group.String] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting songs by genre group: %v", err)
+	}
+	return counts, nil
+}
+
+// genreGroupIndex maps every lowercased genre of genreGroups to its group and
+// returns the genres sorted. The taxonomy gives every genre one group; a genre
+// listed in several counts for the first of them by ID.
+func genreGroupIndex(genreGroups map[string][]string) (map[string]string, []string) {
+	groups := make([]string, 0, len(genreGroups))
+	for group := range genreGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	groupOf := make(map[string]string)
+	var genres []string
+	for _, group := range groups {
+		for _, genre := range genreGroups[group] {
+			genre = strings.ToLower(strings.TrimSpace(genre))
+			if _, exists := groupOf[genre]; exists || genre == "" {
+				continue
+			}
+			groupOf[genre] = group
+			genres = append(genres, genre)
+		}
+	}
+	sort.Strings(genres)
+	return groupOf, genres
+}
+
+// SearchSongs autocompletes over the samples catalog. Songs whose title starts
+// with the query rank first, then songs by an artist whose name starts with it,
+// then substring matches. Duplicates of a song come back once, as their
+// canonical song.
+func (r *SongRepository) SearchSongs(ctx context.Context, query models.SongSearchQuery) ([]models.SongNode, error) {
+	term := strings.TrimSpace(query.Query)
+	artist := strings.TrimSpace(query.Artist)
+
+	var conditions []string
+	params := []interface{}{
+		escapeLike(term) + "%",
+		escapeLike(term) + "%",
+	}
+	if term != "" {
+		conditions = append(conditions, "(s.title LIKE ? OR a.name LIKE ?)")
+		params = append(params, "%"+escapeLike(term)+"%", "%"+escapeLike(term)+"%")
+	}
+	if artist != "" {
+		conditions = append(conditions, "a.name LIKE ?")
+		params = append(params, "%"+escapeLike(artist)+"%")
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+	params = append(params, query.Limit)
+
+	canonicalJoin, canonicalID := r.db.canonicalSongSQL("s.id")
+	searchQuery := `
+		SELECT
+			` + canonicalID + ` as canonical_id,
+			MIN(CASE
+				WHEN s.title LIKE ? THEN 0
+				WHEN a.name LIKE ? THEN 1
+				ELSE 2
+			END) as match_rank
+		FROM Song s
+		JOIN SongArtist sa ON s.id = sa.songId
+		JOIN Artist a ON sa.artistId = a.id
+		` + canonicalJoin + `
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY canonical_id
+		ORDER BY match_rank, MIN(s.title), canonical_id
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, searchQuery, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching songs: %v", err)
+	}
+	defer rows.Close()
+
+	var songIDs []int
+	for rows.Next() {
+		var id, rank int
+		if err := rows.Scan(&id, &rank); err != nil {
+			return nil, fmt.Errorf("error scanning song search result: %v", err)
+		}
+		songIDs = append(songIDs, id)
+	}
+
+	if len(songIDs) == 0 {
+		return nil, nil
+	}
+
+	songs, err := r.GetSongsWithDetails(ctx, songIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error hydrating song search results: %v", err)
+	}
+
+	results := make([]models.SongNode, 0, len(songIDs))
+	for _, id := range songIDs {
+		if song, ok := songs[id]; ok {
+			results = append(results, *song)
+		}
+	}
+	return results, nil
+}
+
+// GetAllSampledSongs returns the canonical songs one sample away from the
+// song or any of its duplicates, in either direction. Without the
+// SongCanonical table, songs with the same title and release year count as
+// duplicates.
+func (r *SongRepository) GetAllSampledSongs(ctx context.Context, songID int) ([]int, error) {
 	query := `
-        WITH RECURSIVE SameSongs AS (
-            -- Base case: Find the original song and its duplicates
-            SELECT id
-            FROM Song s1
-            WHERE EXISTS (
-                SELECT 1 FROM Song s2
-                WHERE s2.id = ?
-                AND s1.title = s2.title
-				AND ((s1.releaseYear IS NULL AND s2.releaseYear IS NULL) OR s1.releaseYear = s2.releaseYear)
-            )
+        WITH ` + r.db.canonicalSampleCTE() + `,
+        SameSong AS (
+            -- The requested song and its duplicates
+            ` + r.db.sameSongSQL() + `
         )
         SELECT DISTINCT sampled_song_id
         FROM (
             -- Songs that sample our song
             SELECT sampled_in_song_id as sampled_song_id
-            FROM SameSongs ss
-            JOIN Sample s ON ss.id = s.original_song_id
+            FROM SameSong ss
+            JOIN CanonicalSample s ON ss.id = s.original_song_id
             
             UNION
             
             -- Songs that our song samples
             SELECT original_song_id as sampled_song_id
-            FROM SameSongs ss
-            JOIN Sample s ON ss.id = s.sampled_in_song_id
+            FROM SameSong ss
+            JOIN CanonicalSample s ON ss.id = s.sampled_in_song_id
         ) all_samples
     `
 
-	rows, err := r.db.Query(query, songID)
+	rows, err := r.db.QueryContext(ctx, query, songID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting sampled songs: %v", err)
 	}
@@ -163,128 +531,777 @@ func (r *SongRepository) GetAllSampledSongs(songID int) ([]int, error) {
 	return sampledSongs, nil
 }
 
-func (r *SongRepository) FindSongsByGenreBFS(songQueries []models.SongQuery, targetGenre string, maxDepth int) ([]models.SearchResult, error) {
+// GetSampleLineage is GetAllSampledSongs with the two sides of the Sample
+// table kept apart.
+func (r *SongRepository) GetSampleLineage(ctx context.Context, songID int) (*models.SampleLineage, error) {
+	query := `
+        WITH ` + r.db.canonicalSampleCTE() + `,
+        SameSong AS (
+            ` + r.db.sameSongSQL() + `
+        )
+        SELECT DISTINCT s.original_song_id as song_id, 'A' as hop
+        FROM SameSong ss
+        JOIN CanonicalSample s ON ss.id = s.sampled_in_song_id
+
+        UNION
+
+        SELECT DISTINCT s.sampled_in_song_id as song_id, 'D' as hop
+        FROM SameSong ss
+        JOIN CanonicalSample s ON ss.id = s.original_song_id
+
+        ORDER BY song_id
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, songID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sample lineage: %v", err)
+	}
+	defer rows.Close()
+
+	lineage := &models.SampleLineage{}
+	for rows.Next() {
+		var id int
+		var hop string
+		if err := rows.Scan(&id, &hop); err != nil {
+			return nil, fmt.Errorf("error scanning sample lineage: %v", err)
+		}
+		if hop == "A" {
+			lineage.SamplesUsed = append(lineage.SamplesUsed, id)
+		} else {
+			lineage.SampledIn = append(lineage.SampledIn, id)
+		}
+	}
+
+	return lineage, nil
+}
+
+// sampleHopSQL returns the Sample join condition and the next song expression
+// the SongReach CTE uses for a traversal direction.
+func sampleHopSQL(direction models.TraversalDirection) (join, next string) {
+	switch direction {
+	case models.DirectionAncestors:
+		return "sp.id = sam.sampled_in_song_id", "sam.original_song_id"
+	case models.DirectionDescendants:
+		return "sp.id = sam.original_song_id", "sam.sampled_in_song_id"
+	}
+	return "sp.id = sam.original_song_id OR sp.id = sam.sampled_in_song_id",
+		"CASE WHEN sam.sampled_in_song_id = sp.id THEN sam.original_song_id ELSE sam.sampled_in_song_id END"
+}
+
+// chronologicalSQL returns the joins and condition that keep a traversal on
+// samples (aliased sam) whose original is not newer than the song sampling it, or empty
+// strings when the search is not chronological.
+func chronologicalSQL(chronological bool) (joins, condition string) {
+	if !chronological {
+		return "", ""
+	}
+	return `
+            JOIN Song so ON so.id = sam.original_song_id
+            JOIN Song ss ON ss.id = sam.sampled_in_song_id`,
+		`
+            AND (COALESCE(so.releaseYear, 0) = 0 OR COALESCE(ss.releaseYear, 0) = 0 OR so.releaseYear <= ss.releaseYear)`
+}
+
+// yearFilterSQL returns the condition that keeps songs (aliased sp) released in
+// a year range, along with its parameters.
+func yearFilterSQL(years models.YearRange) (string, []interface{}) {
+	if years.IsZero() {
+		return "", nil
+	}
+
+	var bounds []string
+	var params []interface{}
+	if years.From != 0 {
+		bounds = append(bounds, "ys.releaseYear >= ?")
+		params = append(params, years.From)
+	}
+	if years.To != 0 {
+		bounds = append(bounds, "ys.releaseYear <= ?")
+		params = append(params, years.To)
+	}
+	return `
+            AND EXISTS (
+                SELECT 1
+                FROM Song ys
+                WHERE ys.id = sp.id AND ` + strings.Join(bounds, " AND ") + `
+            )`, params
+}
+
+// genreFilterSQL returns the condition that keeps the songs (aliased sp) a
+// genre filter is looking for, along with its parameters.
+func genreFilterSQL(filter models.GenreFilter) (string, []interface{}) {
+	targets := filter.TargetGenres()
+	var params []interface{}
+
+	condition := `EXISTS (
+                SELECT 1
+                FROM _SongToGenre sg2
+                JOIN Genre g2 ON g2.id = sg2.A
+                WHERE sg2.B = sp.id AND g2.name IN (` + inPlaceholders(len(targets)) + `)
+            )`
+	if filter.Match == models.GenreMatchAll {
+		condition = `(
+                SELECT COUNT(DISTINCT LOWER(g2.name))
+                FROM _SongToGenre sg2
+                JOIN Genre g2 ON g2.id = sg2.A
+                WHERE sg2.B = sp.id AND g2.name IN (` + inPlaceholders(len(targets)) + `)
+            ) = ?`
+	}
+	for _, genre := range targets {
+		params = append(params, genre)
+	}
+	if filter.Match == models.GenreMatchAll {
+		params = append(params, len(targets))
+	}
+
+	if excluded := filter.ExcludedGenres(); len(excluded) > 0 {
+		condition += `
+            AND NOT EXISTS (
+                SELECT 1
+                FROM _SongToGenre sg3
+                JOIN Genre g3 ON g3.id = sg3.A
+                WHERE sg3.B = sp.id AND g3.name IN (` + inPlaceholders(len(excluded)) + `)
+            )`
+		for _, genre := range excluded {
+			params = append(params, genre)
+		}
+	}
+
+	return condition, params
+}
+
+// seedSongsSQL returns the query selecting the canonical songs of the search
+// seeds, along with its parameters. The query is empty without seeds.
+func (db samplesDB) seedSongsSQL(songQueries []models.SongQuery) (string, []interface{}) {
 	var startConditions []string
 	var params []interface{}
 
 	for _, query := range songQueries {
+		if len(query.SongIDs) > 0 {
+			startConditions = append(startConditions, "s.id IN ("+inPlaceholders(len(query.SongIDs))+")")
+			params = append(params, intArgs(query.SongIDs)...)
+			continue
+		}
 		startConditions = append(startConditions, "(s.title = ? AND a.name = ?)")
 		params = append(params, query.Title, query.Artist)
 	}
 
-	query := `
-        WITH RECURSIVE SongPath AS (
-            -- Base case: start with input songs
-            SELECT 
-                s.id,
-                s.id as source_id,
-                0 as distance,
-                CAST(CONCAT('[', s.id, ']') AS CHAR(1000)) as path
-            FROM Song s
-            JOIN SongArtist sa ON s.id = sa.songId
-            JOIN Artist a ON sa.artistId = a.id
-            WHERE ` + strings.Join(startConditions, " OR ") + `
-            GROUP BY s.id
-
-            UNION ALL
-
-            -- Recursive case: follow sampling relationships
-            SELECT 
-                CASE 
-                    WHEN sam.sampled_in_song_id = sp.id THEN sam.original_song_id
-                    ELSE sam.sampled_in_song_id
-                END,
-                sp.source_id,
-                sp.distance + 1,
-                CONCAT(sp.path, ',', CASE 
-                    WHEN sam.sampled_in_song_id = sp.id THEN sam.original_song_id
-                    ELSE sam.sampled_in_song_id
-                END)
-            FROM SongPath sp
-            JOIN Sample sam ON sp.id = sam.original_song_id OR sp.id = sam.sampled_in_song_id
-            WHERE sp.distance < ?
-        )
-        SELECT DISTINCT
-            sp.id as song_id,
-            sp.source_id,
-            sp.distance,
-            sp.path,
-            s.title,
-            GROUP_CONCAT(DISTINCT g.name) as genres
-        FROM SongPath sp
-        JOIN Song s ON s.id = sp.id
-        LEFT JOIN _SongToGenre sg ON sg.B = s.id
-        LEFT JOIN Genre g ON g.id = sg.A
-        WHERE EXISTS (
-            SELECT 1
-            FROM _SongToGenre sg2
-            JOIN Genre g2 ON g2.id = sg2.A
-            WHERE sg2.B = sp.id AND g2.name = ?
-        )
-        GROUP BY sp.id, sp.source_id, sp.distance, sp.path, s.title
-        ORDER BY sp.distance;
-    `
+	if len(startConditions) == 0 {
+		return "", nil
+	}
+	canonicalJoin, canonicalID := db.canonicalSongSQL("s.id")
+	return `SELECT DISTINCT ` + canonicalID + ` as id
+                FROM Song s
+                JOIN SongArtist sa ON s.id = sa.songId
+                JOIN Artist a ON sa.artistId = a.id
+                ` + canonicalJoin + `
+                WHERE ` + strings.Join(startConditions, " OR "), params
+}
 
-	params = append(params, maxDepth, targetGenre)
+func (r *SongRepository) FindSongsByGenreBFS(ctx context.Context, songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) ([]models.SearchResult, error) {
+	seedQuery, params := r.db.seedSongsSQL(songQueries)
+	if seedQuery == "" || len(filter.TargetGenres()) == 0 {
+		return nil, nil
+	}
 
-	rows, err := r.db.Query(query, params...)
+	seeds, err := r.querySongIDs(ctx, seedQuery, params)
+	if err != nil {
+		return nil, fmt.Errorf("error getting seed songs: %v", err)
+	}
+
+	genreCondition, genreParams := genreFilterSQL(filter)
+	yearCondition, yearParams := yearFilterSQL(opts.Years)
+	excluder := newResultExcluder(opts.Exclude)
+	matches := func(ctx context.Context, ids []int) (map[int]bool, error) {
+		matched, err := r.matchSearchSongs(ctx, ids, genreCondition+yearCondition, append(genreParams, yearParams...))
+		if err != nil || opts.Exclude.IsEmpty() {
+			return matched, err
+		}
+		return r.excludeSearchSongs(ctx, matched, excluder)
+	}
+
+	walks, err := walkGenreSearch(ctx, r.sampleHops(opts.Chronological), seeds, opts, matches)
 	if err != nil {
 		return nil, fmt.Errorf("error executing search query: %v", err)
 	}
-	defer rows.Close()
+	if len(walks) == 0 {
+		return nil, nil
+	}
+
+	var songIDs []int
+	for _, w := range walks {
+		songIDs = append(songIDs, w.path...)
+	}
+	songs, err := r.GetSongsWithDetails(ctx, songIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error hydrating search results: %v", err)
+	}
 
 	var results []models.SearchResult
+	for _, w := range walks {
+		sourceSong, ok := songs[w.source]
+		if !ok {
+			continue
+		}
+		matchedSong, ok := songs[w.path[len(w.path)-1]]
+		if !ok {
+			continue
+		}
+
+		var path []models.SongNode
+		for _, id := range w.path {
+			if song, ok := songs[id]; ok {
+				path = append(path, *song)
+			}
+		}
+
+		results = append(results, models.SearchResult{
+			SourceSong:    *sourceSong,
+			MatchedSong:   *matchedSong,
+			Distance:      len(w.path) - 1,
+			Path:          path,
+			HopDirections: w.hops,
+		})
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	matchedIDs := make([]int, 0, len(results))
+	for _, result := range results {
+		matchedIDs = append(matchedIDs, result.MatchedSong.ID)
+	}
+	sampledInCounts, err := r.countSampledIn(ctx, matchedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankSearchResults(results, sampledInCounts, filter, opts), nil
+}
+
+// querySongIDs runs a query selecting song IDs
+func (r *SongRepository) querySongIDs(ctx context.Context, query string, params []interface{}) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songIDs []int
 	for rows.Next() {
-		var (
-			songID, sourceID int
-			title, genreStr  string
-			distance         int
-			pathStr          string
-		)
-
-		err := rows.Scan(
-			&songID,
-			&sourceID,
-			&distance,
-			&pathStr,
-			&title,
-			&genreStr,
-		)
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		songIDs = append(songIDs, id)
+	}
+	return songIDs, nil
+}
+
+// matchSearchSongs returns which of ids pass the genre and year conditions of
+// a genre search, songDetailsBatchSize IDs per query
+func (r *SongRepository) matchSearchSongs(ctx context.Context, ids []int, condition string, params []interface{}) (map[int]bool, error) {
+	matched := make(map[int]bool)
+	for start := 0; start < len(ids); start += songDetailsBatchSize {
+		batch := ids[start:min(start+songDetailsBatchSize, len(ids))]
+
+		query := `
+			SELECT sp.id
+			FROM Song sp
+			WHERE sp.id IN (` + inPlaceholders(len(batch)) + `)
+			AND ` + condition + `
+		`
+
+		args := append(intArgs(batch), params...)
+		songIDs, err := r.querySongIDs(ctx, query, args)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning results: %v", err)
+			return nil, fmt.Errorf("error matching search songs: %v", err)
+		}
+		for _, id := range songIDs {
+			matched[id] = true
 		}
+	}
+	return matched, nil
+}
 
-		// Build source and matched song nodes
-		sourceSong, err := r.GetSongWithDetails(sourceID)
+// excludeSearchSongs unmarks the matched songs excluder drops, so excluded
+// songs never take up a genre search's rows
+func (r *SongRepository) excludeSearchSongs(ctx context.Context, matched map[int]bool, excluder *resultExcluder) (map[int]bool, error) {
+	var ids []int
+	for id, ok := range matched {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return matched, nil
+	}
+
+	songs, err := r.GetSongsWithDetails(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error hydrating search matches: %v", err)
+	}
+	for _, id := range ids {
+		song, ok := songs[id]
+		matched[id] = ok && !excluder.excludes(*song)
+	}
+	return matched, nil
+}
+
+// GetGenreProfile counts the songs first reached at each depth of a walk from
+// the seeds, grouped by their genres. The walk expands one level at a time
+// like FindSongsByGenreBFS, stops after opts.MaxRows songs and never hydrates
+// songs.
+func (r *SongRepository) GetGenreProfile(ctx context.Context, songQueries []models.SongQuery, opts models.SearchOptions) (*models.GenreProfile, error) {
+	seedQuery, params := r.db.seedSongsSQL(songQueries)
+	if seedQuery == "" || opts.MaxDepth <= 0 {
+		return &models.GenreProfile{}, nil
+	}
+
+	seeds, err := r.querySongIDs(ctx, seedQuery, params)
+	if err != nil {
+		return nil, fmt.Errorf("error getting seed songs: %v", err)
+	}
+
+	levels, truncated, err := walkGenreProfile(ctx, r.sampleHops(opts.Chronological), seeds, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error executing genre profile walk: %v", err)
+	}
+
+	counts := make(map[int]map[string]int)
+	for i, ids := range levels {
+		genreSets, err := r.songGenreSets(ctx, ids, opts.Years)
 		if err != nil {
-			continue
+			return nil, err
+		}
+		for _, key := range genreSets {
+			if counts[i+1] == nil {
+				counts[i+1] = make(map[string]int)
+			}
+			counts[i+1][key]++
 		}
+	}
+
+	return &models.GenreProfile{Levels: genreProfile(counts), Truncated: truncated}, nil
+}
+
+// songGenreSets returns the genreSetKey of each of ids released within years,
+// songDetailsBatchSize IDs per query
+func (r *SongRepository) songGenreSets(ctx context.Context, ids []int, years models.YearRange) ([]string, error) {
+	yearCondition, yearParams := yearFilterSQL(years)
+
+	var keys []string
+	for start := 0; start < len(ids); start += songDetailsBatchSize {
+		batch := ids[start:min(start+songDetailsBatchSize, len(ids))]
+
+		query := `
+			SELECT
+				sp.id,
+				COALESCE(GROUP_CONCAT(DISTINCT LOWER(g.name) ORDER BY LOWER(g.name)), '') as genres
+			FROM Song sp
+			LEFT JOIN _SongToGenre sg ON sg.B = sp.id
+			LEFT JOIN Genre g ON g.id = sg.A
+			WHERE sp.id IN (` + inPlaceholders(len(batch)) + `)` + yearCondition + `
+			GROUP BY sp.id
+		`
 
-		matchedSong, err := r.GetSongWithDetails(songID)
+		rows, err := r.db.QueryContext(ctx, query, append(intArgs(batch), yearParams...)...)
 		if err != nil {
+			return nil, fmt.Errorf("error executing genre profile query: %v", err)
+		}
+		for rows.Next() {
+			var id int
+			var genres string
+			if err := rows.Scan(&id, &genres); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning genre profile: %v", err)
+			}
+			keys = append(keys, genreSetKey(strings.Split(genres, ",")))
+		}
+		rows.Close()
+	}
+	return keys, nil
+}
+
+// countSampledIn counts how many songs sample each of ids
+func (r *SongRepository) countSampledIn(ctx context.Context, ids []int) (map[int]int, error) {
+	counts := make(map[int]int, len(ids))
+	uniqueIDs := uniqueSongIDs(ids)
+	for start := 0; start < len(uniqueIDs); start += songDetailsBatchSize {
+		batch := uniqueIDs[start:min(start+songDetailsBatchSize, len(uniqueIDs))]
+
+		query := `
+			WITH ` + r.db.canonicalSampleCTE() + `
+			SELECT original_song_id, COUNT(DISTINCT sampled_in_song_id)
+			FROM CanonicalSample
+			WHERE original_song_id IN (` + inPlaceholders(len(batch)) + `)
+			GROUP BY original_song_id
+		`
+
+		rows, err := r.db.QueryContext(ctx, query, intArgs(batch)...)
+		if err != nil {
+			return nil, fmt.Errorf("error counting samples: %v", err)
+		}
+
+		for rows.Next() {
+			var songID, count int
+			if err := rows.Scan(&songID, &count); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning sample count: %v", err)
+			}
+			counts[songID] = count
+		}
+		rows.Close()
+	}
+	return counts, nil
+}
+
+// FindSamplePaths returns the shortest sample chains from any of fromIDs to any
+// of toIDs, up to opts.PathsPerMatch() of them. The bidirectional BFS runs in Go
+// and reads one level of the Sample table per query. Both sets are resolved to
+// their canonical songs first.
+func (r *SongRepository) FindSamplePaths(ctx context.Context, fromIDs, toIDs []int, opts models.SearchOptions) ([]models.SearchResult, error) {
+	canonical, err := r.canonicalSongIDs(ctx, append(append([]int(nil), fromIDs...), toIDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := findSamplePaths(ctx, r.sampleHops(false), resolveSongIDs(fromIDs, canonical), resolveSongIDs(toIDs, canonical), opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var songIDs []int
+	for _, path := range paths {
+		songIDs = append(songIDs, path.ids...)
+	}
+
+	songs, err := r.GetSongsWithDetails(ctx, songIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error hydrating sample paths: %v", err)
+	}
+
+	var results []models.SearchResult
+	for _, path := range paths {
+		sourceSong, ok := songs[path.ids[0]]
+		if !ok {
+			continue
+		}
+		matchedSong, ok := songs[path.ids[len(path.ids)-1]]
+		if !ok {
 			continue
 		}
 
-		// Convert path string to array of SongNodes
-		pathStr = strings.Trim(pathStr, "[]")
-		pathIDs := strings.Split(pathStr, ",")
-		var path []models.SongNode
-		for _, idStr := range pathIDs {
-			id, _ := strconv.Atoi(idStr)
-			songDetails, err := r.GetSongWithDetails(id)
-			if err != nil {
-				continue
+		var nodes []models.SongNode
+		for _, id := range path.ids {
+			if song, ok := songs[id]; ok {
+				nodes = append(nodes, *song)
 			}
-			path = append(path, *songDetails)
 		}
 
 		results = append(results, models.SearchResult{
-			SourceSong:  *sourceSong,
-			MatchedSong: *matchedSong,
-			Distance:    distance,
-			Path:        path,
+			SourceSong:    *sourceSong,
+			MatchedSong:   *matchedSong,
+			Distance:      len(path.hops),
+			Path:          nodes,
+			HopDirections: path.hops,
 		})
 	}
 
 	return results, nil
 }
+
+// canonicalSongIDs maps the duplicates among ids to their canonical song. IDs
+// that are canonical themselves are left out.
+func (r *SongRepository) canonicalSongIDs(ctx context.Context, ids []int) (map[int]int, error) {
+	canonical := make(map[int]int)
+	if !r.db.canonical {
+		return canonical, nil
+	}
+	uniqueIDs := uniqueSongIDs(ids)
+	for start := 0; start < len(uniqueIDs); start += songDetailsBatchSize {
+		batch := uniqueIDs[start:min(start+songDetailsBatchSize, len(uniqueIDs))]
+
+		query := `
+			SELECT sc.songId, sc.canonicalId
+			FROM SongCanonical sc
+			WHERE sc.songId IN (` + inPlaceholders(len(batch)) + `)
+		`
+
+		rows, err := r.db.QueryContext(ctx, query, intArgs(batch)...)
+		if err != nil {
+			return nil, fmt.Errorf("error getting canonical songs: %v", err)
+		}
+
+		for rows.Next() {
+			var songID, canonicalID int
+			if err := rows.Scan(&songID, &canonicalID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning canonical song: %v", err)
+			}
+			canonical[songID] = canonicalID
+		}
+		rows.Close()
+	}
+	return canonical, nil
+}
+
+// resolveSongIDs replaces the duplicates among ids with their canonical song,
+// keeping the first occurrence of each song
+func resolveSongIDs(ids []int, canonical map[int]int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	resolved := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if canonicalID, ok := canonical[id]; ok {
+			id = canonicalID
+		}
+		if _, exists := seen[id]; exists {
+			continue
+		}
+		seen[id] = struct{}{}
+		resolved = append(resolved, id)
+	}
+	return resolved
+}
+
+// sampleHops returns a sampleHopLoader over the samples DB, keeping only
+// chronological samples when chronological is set
+func (r *SongRepository) sampleHops(chronological bool) sampleHopLoader {
+	return func(ctx context.Context, ids []int, direction models.TraversalDirection) (map[int][]sampleHop, error) {
+		return r.loadSampleHops(ctx, ids, direction, chronological)
+	}
+}
+
+// loadSampleHops reads the Sample edges leaving ids in the given direction,
+// between canonical songs. A song reachable both ways is only reported once, as
+// an ancestor.
+func (r *SongRepository) loadSampleHops(ctx context.Context, ids []int, direction models.TraversalDirection, chronological bool) (map[int][]sampleHop, error) {
+	hops := make(map[int][]sampleHop, len(ids))
+	seen := make(map[[2]int]struct{})
+	add := func(from, to int, hopDirection models.TraversalDirection) {
+		key := [2]int{from, to}
+		if _, exists := seen[key]; exists {
+			return
+		}
+		seen[key] = struct{}{}
+		hops[from] = append(hops[from], sampleHop{to: to, direction: hopDirection})
+	}
+
+	uniqueIDs := uniqueSongIDs(ids)
+	for start := 0; start < len(uniqueIDs); start += songDetailsBatchSize {
+		batch := uniqueIDs[start:min(start+songDetailsBatchSize, len(uniqueIDs))]
+		placeholders := inPlaceholders(len(batch))
+
+		var conditions []string
+		var args []interface{}
+		if direction != models.DirectionDescendants {
+			conditions = append(conditions, "sam.sampled_in_song_id IN ("+placeholders+")")
+			args = append(args, intArgs(batch)...)
+		}
+		if direction != models.DirectionAncestors {
+			conditions = append(conditions, "sam.original_song_id IN ("+placeholders+")")
+			args = append(args, intArgs(batch)...)
+		}
+
+		chronoJoins, chronoCondition := chronologicalSQL(chronological)
+		query := `
+			WITH ` + r.db.canonicalSampleCTE() + `
+			SELECT sam.original_song_id, sam.sampled_in_song_id
+			FROM CanonicalSample sam` + chronoJoins + `
+			WHERE (` + strings.Join(conditions, " OR ") + `)` + chronoCondition + `
+			ORDER BY sam.original_song_id, sam.sampled_in_song_id
+		`
+
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error getting sample edges: %v", err)
+		}
+
+		inBatch := make(map[int]struct{}, len(batch))
+		for _, id := range batch {
+			inBatch[id] = struct{}{}
+		}
+
+		var ancestors, descendants [][2]int
+		for rows.Next() {
+			var originalID, sampledInID int
+			if err := rows.Scan(&originalID, &sampledInID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning sample edge: %v", err)
+			}
+			if _, ok := inBatch[sampledInID]; ok && direction != models.DirectionDescendants {
+				ancestors = append(ancestors, [2]int{sampledInID, originalID})
+			}
+			if _, ok := inBatch[originalID]; ok && direction != models.DirectionAncestors {
+				descendants = append(descendants, [2]int{originalID, sampledInID})
+			}
+		}
+		rows.Close()
+
+		for _, edge := range ancestors {
+			add(edge[0], edge[1], models.DirectionAncestors)
+		}
+		for _, edge := range descendants {
+			add(edge[0], edge[1], models.DirectionDescendants)
+		}
+	}
+
+	return hops, nil
+}
+
+// GetArtistNetwork aggregates the samples around an artist into artist edges.
+// It returns nil when the artist does not exist.
+func (r *SongRepository) GetArtistNetwork(ctx context.Context, artistID int, opts models.ArtistNetworkOptions) (*models.ArtistNetwork, error) {
+	var start models.Artist
+	err := r.db.QueryRowContext(ctx, `SELECT id, name FROM Artist WHERE id = ?`, artistID).Scan(&start.ID, &start.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting artist: %v", err)
+	}
+
+	return buildArtistNetwork(ctx, r.loadArtistSamples, start, opts)
+}
+
+// GetSongNeighborhood returns every song within opts.Radius sample hops of
+// songID. It returns nil when the song does not exist.
+func (r *SongRepository) GetSongNeighborhood(ctx context.Context, songID int, opts models.NeighborhoodOptions) (*models.SongNeighborhood, error) {
+	canonical, err := r.canonicalSongIDs(ctx, []int{songID})
+	if err != nil {
+		return nil, err
+	}
+
+	neighborhood, err := buildSongNeighborhood(ctx, r.sampleHops(false), resolveSongIDs([]int{songID}, canonical)[0], opts)
+	if err != nil {
+		return nil, err
+	}
+
+	songs, err := r.GetSongsWithDetails(ctx, neighborhood.ids)
+	if err != nil {
+		return nil, fmt.Errorf("error hydrating song neighborhood: %v", err)
+	}
+	if _, ok := songs[neighborhood.ids[0]]; !ok {
+		return nil, nil
+	}
+
+	return neighborhood.hydrate(func(id int) (models.SongNode, bool) {
+		song, ok := songs[id]
+		if !ok {
+			return models.SongNode{}, false
+		}
+		return *song, true
+	}), nil
+}
+
+// loadArtistSamples reads the samples between canonical songs crediting any of
+// artistIDs on either song, one row per (sampling artist, sampled artist) pair
+func (r *SongRepository) loadArtistSamples(ctx context.Context, artistIDs []int, mainArtistsOnly bool) ([]artistSample, error) {
+	var samples []artistSample
+
+	uniqueIDs := uniqueSongIDs(artistIDs)
+	for start := 0; start < len(uniqueIDs); start += songDetailsBatchSize {
+		batch := uniqueIDs[start:min(start+songDetailsBatchSize, len(uniqueIDs))]
+		placeholders := inPlaceholders(len(batch))
+		args := append(intArgs(batch), intArgs(batch)...)
+
+		mainArtistFilter := ""
+		if mainArtistsOnly {
+			mainArtistFilter = "AND sa_in.isMainArtist = 1 AND sa_orig.isMainArtist = 1"
+		}
+
+		query := `
+			WITH ` + r.db.canonicalSampleCTE() + `
+			SELECT
+				a_in.id,
+				a_in.name,
+				sa_in.isMainArtist,
+				a_orig.id,
+				a_orig.name,
+				sa_orig.isMainArtist,
+				s.sampled_in_song_id,
+				s_in.title,
+				s.original_song_id,
+				s_orig.title
+			FROM CanonicalSample s
+			JOIN Song s_in ON s_in.id = s.sampled_in_song_id
+			JOIN Song s_orig ON s_orig.id = s.original_song_id
+			JOIN SongArtist sa_in ON sa_in.songId = s.sampled_in_song_id
+			JOIN Artist a_in ON a_in.id = sa_in.artistId
+			JOIN SongArtist sa_orig ON sa_orig.songId = s.original_song_id
+			JOIN Artist a_orig ON a_orig.id = sa_orig.artistId
+			WHERE (sa_in.artistId IN (` + placeholders + `) OR sa_orig.artistId IN (` + placeholders + `))
+			` + mainArtistFilter + `
+			ORDER BY s.sampled_in_song_id, s.original_song_id, a_in.id, a_orig.id
+		`
+
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error getting artist samples: %v", err)
+		}
+
+		for rows.Next() {
+			var sample artistSample
+			err := rows.Scan(
+				&sample.sampler.ID,
+				&sample.sampler.Name,
+				&sample.sampler.IsMain,
+				&sample.sampled.ID,
+				&sample.sampled.Name,
+				&sample.sampled.IsMain,
+				&sample.pair.SampledInSongID,
+				&sample.pair.SampledInTitle,
+				&sample.pair.OriginalSongID,
+				&sample.pair.OriginalTitle,
+			)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning artist sample: %v", err)
+			}
+			samples = append(samples, sample)
+		}
+		rows.Close()
+	}
+
+	return samples, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueSongIDs returns the distinct IDs in ascending order
+func uniqueSongIDs(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, exists := seen[id]; exists {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	sort.Ints(unique)
+	return unique
+}
+
+func inPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
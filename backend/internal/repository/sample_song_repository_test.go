@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -19,7 +21,7 @@ func setupSongTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 func TestSongRepository_GetSongIDsByTitleAndArtist(t *testing.T) {
 	db, mock := setupSongTestDB(t)
 	defer db.Close()
-	repo := NewSongRepository(db)
+	repo := NewSongRepository(db, true)
 
 	t.Run("Found_Songs", func(t *testing.T) {
 		// Arrange
@@ -29,12 +31,12 @@ func TestSongRepository_GetSongIDsByTitleAndArtist(t *testing.T) {
 			AddRow(1).
 			AddRow(2)
 
-		mock.ExpectQuery("SELECT DISTINCT s.id FROM Song").
+		mock.ExpectQuery("SELECT DISTINCT COALESCE\\(sc.canonicalId, s.id\\) FROM Song").
 			WithArgs(title, artist).
 			WillReturnRows(expectedRows)
 
 		// Act
-		songIDs, err := repo.GetSongIDsByTitleAndArtist(title, artist)
+		songIDs, err := repo.GetSongIDsByTitleAndArtist(context.Background(), title, artist)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when songs are found")
@@ -48,12 +50,12 @@ func TestSongRepository_GetSongIDsByTitleAndArtist(t *testing.T) {
 		artist := "Unknown Artist"
 		expectedRows := sqlmock.NewRows([]string{"id"})
 
-		mock.ExpectQuery("SELECT DISTINCT s.id FROM Song").
+		mock.ExpectQuery("SELECT DISTINCT COALESCE\\(sc.canonicalId, s.id\\) FROM Song").
 			WithArgs(title, artist).
 			WillReturnRows(expectedRows)
 
 		// Act
-		songIDs, err := repo.GetSongIDsByTitleAndArtist(title, artist)
+		songIDs, err := repo.GetSongIDsByTitleAndArtist(context.Background(), title, artist)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when no songs are found")
@@ -66,12 +68,12 @@ func TestSongRepository_GetSongIDsByTitleAndArtist(t *testing.T) {
 		title := "Error Song"
 		artist := "Error Artist"
 
-		mock.ExpectQuery("SELECT DISTINCT s.id FROM Song").
+		mock.ExpectQuery("SELECT DISTINCT COALESCE\\(sc.canonicalId, s.id\\) FROM Song").
 			WithArgs(title, artist).
 			WillReturnError(sql.ErrConnDone)
 
 		// Act
-		songIDs, err := repo.GetSongIDsByTitleAndArtist(title, artist)
+		songIDs, err := repo.GetSongIDsByTitleAndArtist(context.Background(), title, artist)
 
 		// Assert
 		assert.Error(t, err, "Should return error when database fails")
@@ -80,10 +82,135 @@ func TestSongRepository_GetSongIDsByTitleAndArtist(t *testing.T) {
 	})
 }
 
+func TestSongRepository_GetSongIDsByArtist(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Found_Songs", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("ArtistSong AS \\(.*WHERE a.name = \\? AND sa.isMainArtist = 1.*ORDER BY COUNT\\(cs.original_song_id\\) DESC, ars.id\\s+LIMIT \\?").
+			WithArgs("Test Artist", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(1))
+
+		// Act
+		songIDs, err := repo.GetSongIDsByArtist(context.Background(), "Test Artist", 5)
+
+		// Assert
+		require.NoError(t, err, "Should not return error when songs are found")
+		assert.Equal(t, []int{3, 1}, songIDs, "Should keep the order of the query")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("ArtistSong AS").
+			WithArgs("Error Artist", 5).
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		songIDs, err := repo.GetSongIDsByArtist(context.Background(), "Error Artist", 5)
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, songIDs, "Should return nil when database error occurs")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_MatchSongs(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Normalized_Matches", func(t *testing.T) {
+		// Arrange
+		query := models.SongQuery{Title: "Song - 2011 Remaster", Artist: "Beyoncé"}
+		candidateRows := sqlmock.NewRows([]string{"id", "title", "name"}).
+			AddRow(1, "Song", "Beyonce").
+			AddRow(1, "Song", "Featured Artist").
+			AddRow(2, "Song (Live)", "Beyoncé").
+			AddRow(3, "Songbird", "Beyoncé").
+			AddRow(4, "Song", "Someone Else")
+
+		mock.ExpectQuery(`WHERE s2.title LIKE \? OR a2.name IN \(\?,\?\)\s+GROUP BY s2.id\s+ORDER BY relevance DESC, s2.id\s+LIMIT \?`).
+			WithArgs("Song", "Song%", "Beyoncé", "beyonce", "Song%", "Beyoncé", "beyonce", maxMatchCandidates).
+			WillReturnRows(candidateRows)
+
+		// Act
+		matches, err := repo.MatchSongs(context.Background(), query)
+
+		// Assert
+		require.NoError(t, err, "Should not return error when candidates are found")
+		require.Len(t, matches, 2, "Should drop other titles and other artists")
+		assert.Equal(t, models.SongMatch{SongID: 1, Title: "Song", Artist: "Beyonce", Confidence: 0.95}, matches[0])
+		assert.Equal(t, 2, matches[1].SongID)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Exact_Match_Ranks_First", func(t *testing.T) {
+		// Arrange
+		query := models.SongQuery{Title: "Song", Artist: "Artist"}
+		candidateRows := sqlmock.NewRows([]string{"id", "title", "name"}).
+			AddRow(1, "Song - Remastered", "Artist").
+			AddRow(2, "Song", "Artist")
+
+		mock.ExpectQuery("SELECT COALESCE\\(sc.canonicalId, s.id\\) as canonical_id, s.title, a.name FROM \\(").
+			WithArgs("Song", "Song%", "Artist", "Song%", "Artist", maxMatchCandidates).
+			WillReturnRows(candidateRows)
+
+		// Act
+		matches, err := repo.MatchSongs(context.Background(), query)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, 2, matches[0].SongID, "Exact match should rank first")
+		assert.Equal(t, 1.0, matches[0].Confidence)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Raw_Artist_Spellings", func(t *testing.T) {
+		// Arrange
+		query := models.SongQuery{Title: "Song", Artist: "The Jay-Z & AC/DC"}
+		spellings := []driver.Value{"The Jay-Z & AC/DC", "Jay-Z & AC/DC", "The Jay-Z", "Jay-Z", "AC/DC", "the jay z and ac dc", "jay z and ac dc", "the jay z", "jay z", "ac dc"}
+		args := append([]driver.Value{"Song", "Song%"}, spellings...)
+		args = append(append(args, "Song%"), spellings...)
+		args = append(args, maxMatchCandidates)
+
+		mock.ExpectQuery("SELECT COALESCE\\(sc.canonicalId, s.id\\) as canonical_id, s.title, a.name FROM \\(").
+			WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "name"}).AddRow(1, "Song", "AC/DC"))
+
+		// Act
+		matches, err := repo.MatchSongs(context.Background(), query)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "AC/DC", matches[0].Artist)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Artists should be looked up as written and folded")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT COALESCE\\(sc.canonicalId, s.id\\) as canonical_id, s.title, a.name FROM \\(").
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		matches, err := repo.MatchSongs(context.Background(), models.SongQuery{Title: "Song", Artist: "Artist"})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, matches)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
 func TestSongRepository_GetSongWithDetails(t *testing.T) {
 	db, mock := setupSongTestDB(t)
 	defer db.Close()
-	repo := NewSongRepository(db)
+	repo := NewSongRepository(db, true)
 
 	t.Run("Get_Song_With_Details", func(t *testing.T) {
 		// Arrange
@@ -93,11 +220,16 @@ func TestSongRepository_GetSongWithDetails(t *testing.T) {
 		artistID := 101
 		genres := "Rock,Pop"
 
+		// The requested song is a duplicate of songID
+		mock.ExpectQuery(`SELECT sc.songId, sc.canonicalId\s+FROM SongCanonical sc`).
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}).AddRow(4, songID))
+
 		// Mock for main song query
-		songRows := sqlmock.NewRows([]string{"id", "title", "artist_name", "artist_id", "genres"}).
-			AddRow(songID, songTitle, artistName, artistID, genres)
+		songRows := sqlmock.NewRows([]string{"id", "title", "releaseYear", "artist_name", "artist_id", "genres"}).
+			AddRow(songID, songTitle, 1995, artistName, artistID, genres)
 
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
 			WithArgs(songID).
 			WillReturnRows(songRows)
 
@@ -111,14 +243,15 @@ func TestSongRepository_GetSongWithDetails(t *testing.T) {
 			WillReturnRows(artistRows)
 
 		// Act
-		song, err := repo.GetSongWithDetails(songID)
+		song, err := repo.GetSongWithDetails(context.Background(), 4)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when song is found")
 		assert.NotNil(t, song, "Should return a song")
-		assert.Equal(t, songID, song.ID, "Song ID should match")
+		assert.Equal(t, songID, song.ID, "Should return the canonical song")
 		assert.Equal(t, songTitle, song.Title, "Song title should match")
 		assert.Equal(t, []string{"Rock", "Pop"}, song.Genres, "Genres should match")
+		assert.Equal(t, 1995, song.ReleaseYear, "Release year should match")
 		assert.Len(t, song.Artists, 2, "Should have two artists")
 		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
 	})
@@ -127,24 +260,247 @@ func TestSongRepository_GetSongWithDetails(t *testing.T) {
 		// Arrange
 		songID := 999
 
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
+		mock.ExpectQuery(`SELECT sc.songId, sc.canonicalId\s+FROM SongCanonical sc`).
+			WithArgs(songID).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}))
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
 			WithArgs(songID).
 			WillReturnError(sql.ErrNoRows)
 
 		// Act
-		song, err := repo.GetSongWithDetails(songID)
+		song, err := repo.GetSongWithDetails(context.Background(), songID)
 
 		// Assert
-		assert.Error(t, err, "Should return error when song is not found")
+		assert.ErrorIs(t, err, sql.ErrNoRows, "Should return error when song is not found")
 		assert.Nil(t, song, "Should return nil when song is not found")
 		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
 	})
 }
 
+func TestSongRepository_GetSongsWithDetails(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Hydrates_Songs_In_One_Batch", func(t *testing.T) {
+		// Arrange
+		songRows := sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+			AddRow(1, "Song 1", 1999, "Rock,Pop").
+			AddRow(2, "Song 2", nil, nil).
+			AddRow(3, "Song Without Artist", 1965, "Jazz")
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT\\(DISTINCT g.name\\) as genres FROM Song s").
+			WithArgs(1, 2, 3).
+			WillReturnRows(songRows)
+
+		artistRows := sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+			AddRow(1, 101, "Main Artist", true).
+			AddRow(1, 102, "Featured Artist", false).
+			AddRow(2, 103, "Other Artist", true)
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 2, 3).
+			WillReturnRows(artistRows)
+
+		// Act
+		songs, err := repo.GetSongsWithDetails(context.Background(), []int{3, 1, 2, 1})
+
+		// Assert
+		require.NoError(t, err, "Should not return error when songs are found")
+		assert.Len(t, songs, 2, "Songs without artists should be left out")
+		assert.Equal(t, []string{"Rock", "Pop"}, songs[1].Genres, "Genres should match")
+		assert.Equal(t, 1999, songs[1].ReleaseYear, "Release year should match")
+		assert.Zero(t, songs[2].ReleaseYear, "Unknown release years should be 0")
+		assert.Len(t, songs[1].Artists, 2, "Should have two artists")
+		assert.Empty(t, songs[2].Genres, "Songs without genres should have none")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("No_IDs", func(t *testing.T) {
+		// Act
+		songs, err := repo.GetSongsWithDetails(context.Background(), nil)
+
+		// Assert
+		require.NoError(t, err, "Should not query the database for an empty set")
+		assert.Empty(t, songs, "Should return an empty map")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT\\(DISTINCT g.name\\) as genres FROM Song s").
+			WithArgs(1).
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		songs, err := repo.GetSongsWithDetails(context.Background(), []int{1})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, songs, "Should return nil when database error occurs")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_GetSampleLineage(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Get_Sample_Lineage", func(t *testing.T) {
+		// Arrange
+		songID := 1
+		lineageRows := sqlmock.NewRows([]string{"song_id", "hop"}).
+			AddRow(2, "A").
+			AddRow(3, "D").
+			AddRow(4, "A")
+
+		mock.ExpectQuery("SameSong AS(.|\\s)+JOIN CanonicalSample s ON ss.id = s.sampled_in_song_id").
+			WithArgs(songID).
+			WillReturnRows(lineageRows)
+
+		// Act
+		lineage, err := repo.GetSampleLineage(context.Background(), songID)
+
+		// Assert
+		require.NoError(t, err, "Should not return error when lineage is found")
+		assert.Equal(t, []int{2, 4}, lineage.SamplesUsed, "Should split songs it samples")
+		assert.Equal(t, []int{3}, lineage.SampledIn, "Should split songs sampling it")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SameSong AS(.|\\s)+JOIN CanonicalSample s ON ss.id = s.sampled_in_song_id").
+			WithArgs(1).
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		lineage, err := repo.GetSampleLineage(context.Background(), 1)
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, lineage)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_SearchSongs(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Found_Songs", func(t *testing.T) {
+		// Arrange
+		query := models.SongSearchQuery{Query: "amen", Artist: "winstons", Limit: 5}
+
+		mock.ExpectQuery(`WHERE \(s.title LIKE \? OR a.name LIKE \?\) AND a.name LIKE \?(.|\s)+ORDER BY match_rank`).
+			WithArgs("amen%", "amen%", "%amen%", "%amen%", "%winstons%", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "match_rank"}).
+				AddRow(2, 0).
+				AddRow(1, 2))
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+				AddRow(1, "Brother Amen", 1970, nil).
+				AddRow(2, "Amen, Brother", 1969, "soul,funk"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+				AddRow(1, 10, "The Winstons", true).
+				AddRow(2, 10, "The Winstons", true))
+
+		// Act
+		songs, err := repo.SearchSongs(context.Background(), query)
+
+		// Assert
+		require.NoError(t, err, "Should not return error when songs are found")
+		require.Len(t, songs, 2)
+		assert.Equal(t, "Amen, Brother", songs[0].Title, "Prefix matches should come first")
+		assert.Equal(t, 1969, songs[0].ReleaseYear)
+		assert.Equal(t, "Brother Amen", songs[1].Title)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Escapes_Wildcards", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("ORDER BY match_rank").
+			WithArgs(`100\%%`, `100\%%`, `%100\%%`, `%100\%%`, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "match_rank"}))
+
+		// Act
+		songs, err := repo.SearchSongs(context.Background(), models.SongSearchQuery{Query: "100%", Limit: 10})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, songs)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("ORDER BY match_rank").
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		songs, err := repo.SearchSongs(context.Background(), models.SongSearchQuery{Query: "amen", Limit: 10})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, songs)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_CountSongsByGenreGroup(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Counts_Distinct_Songs", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery(`SELECT CASE LOWER\(g.name\) WHEN \? THEN \? WHEN \? THEN \? WHEN \? THEN \? END as group_id, COUNT\(DISTINCT COALESCE\(sc.canonicalId, sg.B\)\)(.|\s)+WHERE g.name IN \(\?,\?,\?\)\s+GROUP BY group_id`).
+			WithArgs("jazz", "jazz", "pop", "rock", "rock", "rock", "jazz", "pop", "rock").
+			WillReturnRows(sqlmock.NewRows([]string{"group_id", "count"}).AddRow("rock", 42))
+
+		// Act
+		counts, err := repo.CountSongsByGenreGroup(context.Background(), map[string][]string{
+			"rock": {"pop", "Rock"},
+			"jazz": {"jazz"},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"rock": 42, "jazz": 0}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("No_Genres", func(t *testing.T) {
+		// Act
+		counts, err := repo.CountSongsByGenreGroup(context.Background(), map[string][]string{"empty": nil})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"empty": 0}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Should not query without genres")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT CASE").WillReturnError(sql.ErrConnDone)
+
+		// Act
+		_, err := repo.CountSongsByGenreGroup(context.Background(), map[string][]string{"jazz": {"jazz"}})
+
+		// Assert
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
 func TestSongRepository_GetAllSampledSongs(t *testing.T) {
 	db, mock := setupSongTestDB(t)
 	defer db.Close()
-	repo := NewSongRepository(db)
+	repo := NewSongRepository(db, true)
 
 	t.Run("Get_Sampled_Songs", func(t *testing.T) {
 		// Arrange
@@ -156,12 +512,12 @@ func TestSongRepository_GetAllSampledSongs(t *testing.T) {
 			AddRow(3).
 			AddRow(4)
 
-		mock.ExpectQuery("WITH RECURSIVE SameSongs AS").
+		mock.ExpectQuery("SameSong AS(.|\\s)+SELECT DISTINCT sampled_song_id").
 			WithArgs(songID).
 			WillReturnRows(rows)
 
 		// Act
-		sampledSongs, err := repo.GetAllSampledSongs(songID)
+		sampledSongs, err := repo.GetAllSampledSongs(context.Background(), songID)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when sampled songs are found")
@@ -174,12 +530,12 @@ func TestSongRepository_GetAllSampledSongs(t *testing.T) {
 		songID := 5
 		rows := sqlmock.NewRows([]string{"sampled_song_id"})
 
-		mock.ExpectQuery("WITH RECURSIVE SameSongs AS").
+		mock.ExpectQuery("SameSong AS(.|\\s)+SELECT DISTINCT sampled_song_id").
 			WithArgs(songID).
 			WillReturnRows(rows)
 
 		// Act
-		sampledSongs, err := repo.GetAllSampledSongs(songID)
+		sampledSongs, err := repo.GetAllSampledSongs(context.Background(), songID)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when no sampled songs are found")
@@ -191,12 +547,12 @@ func TestSongRepository_GetAllSampledSongs(t *testing.T) {
 		// Arrange
 		songID := 6
 
-		mock.ExpectQuery("WITH RECURSIVE SameSongs AS").
+		mock.ExpectQuery("SameSong AS(.|\\s)+SELECT DISTINCT sampled_song_id").
 			WithArgs(songID).
 			WillReturnError(sql.ErrConnDone)
 
 		// Act
-		sampledSongs, err := repo.GetAllSampledSongs(songID)
+		sampledSongs, err := repo.GetAllSampledSongs(context.Background(), songID)
 
 		// Assert
 		assert.Error(t, err, "Should return error when database fails")
@@ -205,10 +561,35 @@ func TestSongRepository_GetAllSampledSongs(t *testing.T) {
 	})
 }
 
+// Genre search query patterns: the seed songs, the songs of a level matching
+// the genre and year filters, and the sample hops out of a level
+const (
+	searchSeedsQuery = `SELECT DISTINCT COALESCE\(sc.canonicalId, s.id\) as id\s+FROM Song s`
+	searchMatchQuery = `SELECT sp.id\s+FROM Song sp\s+WHERE sp.id IN`
+	searchHopsQuery  = `SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam`
+)
+
+func songIDRows(ids ...int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		rows.AddRow(id)
+	}
+	return rows
+}
+
+// sampleEdgeRows returns Sample rows from (original, sampled in) pairs
+func sampleEdgeRows(edges ...[2]int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"})
+	for _, edge := range edges {
+		rows.AddRow(edge[0], edge[1])
+	}
+	return rows
+}
+
 func TestSongRepository_FindSongsByGenreBFS(t *testing.T) {
 	db, mock := setupSongTestDB(t)
 	defer db.Close()
-	repo := NewSongRepository(db)
+	repo := NewSongRepository(db, true)
 
 	t.Run("Find_Songs_By_Genre", func(t *testing.T) {
 		// Arrange
@@ -216,100 +597,65 @@ func TestSongRepository_FindSongsByGenreBFS(t *testing.T) {
 			{Title: "Song 1", Artist: "Artist 1"},
 			{Title: "Song 2", Artist: "Artist 2"},
 		}
-		targetGenre := "Rock"
-		maxDepth := 3
-
-		// Mock for the recursive query results
-		searchRows := sqlmock.NewRows([]string{"song_id", "source_id", "distance", "path", "title", "genres"}).
-			AddRow(101, 1, 1, "[1,101]", "Found Song 1", "Rock,Alternative").
-			AddRow(102, 2, 2, "[2,103,102]", "Found Song 2", "Rock,Pop")
-
-		// We need to use ExpectQuery with a regex pattern because the query has multiple placeholders
-		mock.ExpectQuery("WITH RECURSIVE SongPath AS").
-			WillReturnRows(searchRows)
-
-		// For each result, we'll need to mock GetSongWithDetails calls for source and matched songs
-		// First result source song (ID: 1)
-		sourceSong1Rows := sqlmock.NewRows([]string{"id", "title", "artist_name", "artist_id", "genres"}).
-			AddRow(1, "Song 1", "Artist 1", 201, "Pop")
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
-			WithArgs(1).
-			WillReturnRows(sourceSong1Rows)
-
-		sourceSong1ArtistRows := sqlmock.NewRows([]string{"id", "name", "isMainArtist"}).
-			AddRow(201, "Artist 1", true)
-		mock.ExpectQuery("SELECT a.id, a.name, sa.isMainArtist FROM SongArtist sa").
-			WithArgs(1).
-			WillReturnRows(sourceSong1ArtistRows)
-
-		// First result matched song (ID: 101)
-		matchedSong1Rows := sqlmock.NewRows([]string{"id", "title", "artist_name", "artist_id", "genres"}).
-			AddRow(101, "Found Song 1", "Rock Artist", 301, "Rock,Alternative")
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
-			WithArgs(101).
-			WillReturnRows(matchedSong1Rows)
-
-		matchedSong1ArtistRows := sqlmock.NewRows([]string{"id", "name", "isMainArtist"}).
-			AddRow(301, "Rock Artist", true)
-		mock.ExpectQuery("SELECT a.id, a.name, sa.isMainArtist FROM SongArtist sa").
-			WithArgs(101).
-			WillReturnRows(matchedSong1ArtistRows)
-
-		// Path songs for result 1
-		// Path node 1 (id: 1) - already mocked above for source song
-
-		// Second result source song (ID: 2)
-		sourceSong2Rows := sqlmock.NewRows([]string{"id", "title", "artist_name", "artist_id", "genres"}).
-			AddRow(2, "Song 2", "Artist 2", 202, "Electronic")
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
-			WithArgs(2).
-			WillReturnRows(sourceSong2Rows)
-
-		sourceSong2ArtistRows := sqlmock.NewRows([]string{"id", "name", "isMainArtist"}).
-			AddRow(202, "Artist 2", true)
-		mock.ExpectQuery("SELECT a.id, a.name, sa.isMainArtist FROM SongArtist sa").
-			WithArgs(2).
-			WillReturnRows(sourceSong2ArtistRows)
-
-		// Second result matched song (ID: 102)
-		matchedSong2Rows := sqlmock.NewRows([]string{"id", "title", "artist_name", "artist_id", "genres"}).
-			AddRow(102, "Found Song 2", "Rock Pop Artist", 302, "Rock,Pop")
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
-			WithArgs(102).
-			WillReturnRows(matchedSong2Rows)
-
-		matchedSong2ArtistRows := sqlmock.NewRows([]string{"id", "name", "isMainArtist"}).
-			AddRow(302, "Rock Pop Artist", true)
-		mock.ExpectQuery("SELECT a.id, a.name, sa.isMainArtist FROM SongArtist sa").
-			WithArgs(102).
-			WillReturnRows(matchedSong2ArtistRows)
-
-		// Path songs for result 2
-		// Path node 1 (id: 2) - already mocked above for source song
+		genreFilter := models.NewGenreFilter("Rock")
+		opts := models.SearchOptions{MaxDepth: 3, Direction: models.DirectionBoth}
+
+		// 101 samples 1, 2 samples 103 and 102 samples 103
+		mock.ExpectQuery(searchSeedsQuery).
+			WithArgs("Song 1", "Artist 1", "Song 2", "Artist 2").
+			WillReturnRows(songIDRows(1, 2))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(1, 2, "rock").WillReturnRows(songIDRows())
+		mock.ExpectQuery(searchHopsQuery).WithArgs(1, 2, 1, 2).
+			WillReturnRows(sampleEdgeRows([2]int{1, 101}, [2]int{103, 2}))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(101, 103, "rock").WillReturnRows(songIDRows(101))
+		mock.ExpectQuery(searchHopsQuery).WithArgs(101, 103, 101, 103).
+			WillReturnRows(sampleEdgeRows([2]int{1, 101}, [2]int{103, 2}, [2]int{103, 102}))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(102, "rock").WillReturnRows(songIDRows(102))
+		mock.ExpectQuery(searchHopsQuery).WithArgs(102, 102).WillReturnRows(sampleEdgeRows())
+
+		// All source, matched and path songs are hydrated with one batch
+		songRows := sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+			AddRow(1, "Song 1", 2001, "Pop").
+			AddRow(2, "Song 2", nil, "Electronic").
+			AddRow(101, "Found Song 1", 1994, "Rock,Alternative").
+			AddRow(102, "Found Song 2", 1988, "Rock,Pop").
+			AddRow(103, "Intermediate Song", 1980, "Electronic,Rock")
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT\\(DISTINCT g.name\\) as genres FROM Song s").
+			WithArgs(1, 2, 101, 102, 103).
+			WillReturnRows(songRows)
 
-		// Path node 2 (id: 103)
-		pathNode2Rows := sqlmock.NewRows([]string{"id", "title", "artist_name", "artist_id", "genres"}).
-			AddRow(103, "Intermediate Song", "Intermediate Artist", 203, "Electronic,Rock")
-		mock.ExpectQuery("SELECT s.id, s.title, a.name as artist_name, a.id as artist_id, GROUP_CONCAT").
-			WithArgs(103).
-			WillReturnRows(pathNode2Rows)
+		artistRows := sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+			AddRow(1, 201, "Artist 1", true).
+			AddRow(2, 202, "Artist 2", true).
+			AddRow(101, 301, "Rock Artist", true).
+			AddRow(102, 302, "Rock Pop Artist", true).
+			AddRow(103, 203, "Intermediate Artist", true)
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 2, 101, 102, 103).
+			WillReturnRows(artistRows)
 
-		pathNode2ArtistRows := sqlmock.NewRows([]string{"id", "name", "isMainArtist"}).
-			AddRow(203, "Intermediate Artist", true)
-		mock.ExpectQuery("SELECT a.id, a.name, sa.isMainArtist FROM SongArtist sa").
-			WithArgs(103).
-			WillReturnRows(pathNode2ArtistRows)
+		// Matched songs' sample counts feed the ranking
+		mock.ExpectQuery("SELECT original_song_id, COUNT\\(DISTINCT sampled_in_song_id\\)\\s+FROM CanonicalSample").
+			WithArgs(101, 102).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "count"}).
+				AddRow(101, 3).
+				AddRow(102, 1))
 
 		// Act
-		results, err := repo.FindSongsByGenreBFS(songQueries, targetGenre, maxDepth)
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, genreFilter, opts)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when songs are found")
 		assert.Len(t, results, 2, "Should return 2 results")
+		assert.Equal(t, []models.TraversalDirection{models.DirectionDescendants}, results[0].HopDirections)
+		assert.Equal(t, []models.TraversalDirection{models.DirectionAncestors, models.DirectionDescendants}, results[1].HopDirections)
 		assert.Equal(t, 1, results[0].Distance, "First result should have distance 1")
 		assert.Equal(t, 2, results[1].Distance, "Second result should have distance 2")
 		assert.Equal(t, "Found Song 1", results[0].MatchedSong.Title, "First result should match correct song")
 		assert.Equal(t, "Found Song 2", results[1].MatchedSong.Title, "Second result should match correct song")
+		assert.Len(t, results[1].Path, 3, "Path should include every hydrated node")
+		assert.Equal(t, 0.725, results[0].Score, "Closer, more sampled matches should score higher")
+		assert.Equal(t, 0.558, results[1].Score)
 		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
 	})
 
@@ -318,15 +664,15 @@ func TestSongRepository_FindSongsByGenreBFS(t *testing.T) {
 		songQueries := []models.SongQuery{
 			{Title: "Rare Song", Artist: "Rare Artist"},
 		}
-		targetGenre := "Experimental Jazz"
-		maxDepth := 2
+		genreFilter := models.NewGenreFilter("Experimental Jazz")
+		opts := models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth}
 
-		// Mock empty result set
-		mock.ExpectQuery("WITH RECURSIVE SongPath AS").
-			WillReturnRows(sqlmock.NewRows([]string{"song_id", "source_id", "distance", "path", "title", "genres"}))
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Rare Song", "Rare Artist").WillReturnRows(songIDRows(5))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(5, "experimental jazz").WillReturnRows(songIDRows())
+		mock.ExpectQuery(searchHopsQuery).WithArgs(5, 5).WillReturnRows(sampleEdgeRows())
 
 		// Act
-		results, err := repo.FindSongsByGenreBFS(songQueries, targetGenre, maxDepth)
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, genreFilter, opts)
 
 		// Assert
 		require.NoError(t, err, "Should not return error when no songs are found")
@@ -339,18 +685,671 @@ func TestSongRepository_FindSongsByGenreBFS(t *testing.T) {
 		songQueries := []models.SongQuery{
 			{Title: "Error Song", Artist: "Error Artist"},
 		}
-		targetGenre := "Rock"
-		maxDepth := 3
+		genreFilter := models.NewGenreFilter("Rock")
+		opts := models.SearchOptions{MaxDepth: 3, Direction: models.DirectionBoth}
 
-		mock.ExpectQuery("WITH RECURSIVE SongPath AS").
-			WillReturnError(sql.ErrConnDone)
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Error Song", "Error Artist").WillReturnRows(songIDRows(5))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(5, "rock").WillReturnRows(songIDRows())
+		mock.ExpectQuery(searchHopsQuery).WithArgs(5, 5).WillReturnError(sql.ErrConnDone)
 
 		// Act
-		results, err := repo.FindSongsByGenreBFS(songQueries, targetGenre, maxDepth)
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, genreFilter, opts)
 
 		// Assert
 		assert.Error(t, err, "Should return error when database fails")
 		assert.Nil(t, results, "Should return nil when database error occurs")
 		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
 	})
+
+	t.Run("Ancestors_Direction", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		opts := models.SearchOptions{MaxDepth: 2, Direction: models.DirectionAncestors}
+
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(1, "jazz").WillReturnRows(songIDRows())
+		mock.ExpectQuery(`FROM CanonicalSample sam\s+WHERE \(sam.sampled_in_song_id IN \(\?\)\)\s+ORDER BY`).
+			WithArgs(1).
+			WillReturnRows(sampleEdgeRows())
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("Jazz"), opts)
+
+		// Assert
+		require.NoError(t, err, "Should not return error for a directional search")
+		assert.Empty(t, results, "Should return empty results when no songs match")
+		assert.NoError(t, mock.ExpectationsWereMet(), "Query should only follow original_song_id")
+	})
+
+	t.Run("Alternative_Paths", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		opts := models.SearchOptions{MaxDepth: 3, Direction: models.DirectionAncestors, MaxPaths: 1}
+
+		// 1 samples 2 and 3, which both sample 4, which samples 1 back
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(1, "jazz").WillReturnRows(songIDRows())
+		mock.ExpectQuery(searchHopsQuery).WithArgs(1).
+			WillReturnRows(sampleEdgeRows([2]int{2, 1}, [2]int{3, 1}))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(2, 3, "jazz").WillReturnRows(songIDRows())
+		mock.ExpectQuery(searchHopsQuery).WithArgs(2, 3).
+			WillReturnRows(sampleEdgeRows([2]int{4, 2}, [2]int{4, 3}))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(4, "jazz").WillReturnRows(songIDRows(4))
+		mock.ExpectQuery(searchHopsQuery).WithArgs(4).
+			WillReturnRows(sampleEdgeRows([2]int{1, 4}))
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(1, 2, 4).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+				AddRow(1, "Song 1", 2001, "Hip-Hop").
+				AddRow(2, "Song 2", 1990, "Soul").
+				AddRow(4, "Song 4", 1970, "Jazz"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 2, 4).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+				AddRow(1, 201, "Artist 1", true).
+				AddRow(2, 202, "Artist 2", true).
+				AddRow(4, 204, "Artist 4", true))
+		mock.ExpectQuery("SELECT original_song_id, COUNT\\(DISTINCT sampled_in_song_id\\)").
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "count"}).AddRow(4, 2))
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("Jazz"), opts)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Only MaxPaths walks should reach a song, and none should loop back")
+		assert.Equal(t, []int{1, 2, 4}, []int{results[0].Path[0].ID, results[0].Path[1].ID, results[0].Path[2].ID})
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Matched_Song_IDs", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1 - Remastered", Artist: "Artist 1", SongIDs: []int{1, 5}},
+			{Title: "Song 2", Artist: "Artist 2"},
+		}
+		opts := models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth}
+
+		mock.ExpectQuery(`WHERE s.id IN \(\?,\?\) OR \(s.title = \? AND a.name = \?\)`).
+			WithArgs(1, 5, "Song 2", "Artist 2").
+			WillReturnRows(songIDRows())
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("Jazz"), opts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Matched seeds should start from their song IDs")
+	})
+
+	t.Run("Any_Genre_With_Exclusions", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		genreFilter := models.GenreFilter{
+			Genres:  []string{"Jazz", "blues", "jazz"},
+			Match:   models.GenreMatchAny,
+			Exclude: []string{"Smooth Jazz"},
+		}
+		opts := models.SearchOptions{Direction: models.DirectionBoth}
+
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(`WHERE sg2.B = sp.id AND g2.name IN \(\?,\?\)\s+\)\s+AND NOT EXISTS \((.|\s)+g3.name IN \(\?\)`).
+			WithArgs(1, "jazz", "blues", "smooth jazz").
+			WillReturnRows(songIDRows())
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, genreFilter, opts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Query should match any target genre and none of the excluded ones")
+	})
+
+	t.Run("All_Genres", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		genreFilter := models.GenreFilter{Genres: []string{"Jazz", "Funk"}, Match: models.GenreMatchAll}
+		opts := models.SearchOptions{Direction: models.DirectionBoth}
+
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(`SELECT COUNT\(DISTINCT LOWER\(g2.name\)\)(.|\s)+g2.name IN \(\?,\?\)\s+\) = \?`).
+			WithArgs(1, "jazz", "funk", 2).
+			WillReturnRows(songIDRows())
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, genreFilter, opts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Query should require every target genre")
+	})
+
+	t.Run("Year_Range_And_Chronological", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		opts := models.SearchOptions{
+			MaxDepth:      1,
+			Direction:     models.DirectionAncestors,
+			Years:         models.YearRange{From: 1970, To: 1979},
+			Chronological: true,
+		}
+
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(`ys.releaseYear >= \? AND ys.releaseYear <= \?`).
+			WithArgs(1, "soul", 1970, 1979).
+			WillReturnRows(songIDRows())
+		mock.ExpectQuery(`JOIN Song so ON so.id = sam.original_song_id\s+JOIN Song ss ON ss.id = sam.sampled_in_song_id(.|\s)+so.releaseYear <= ss.releaseYear`).
+			WithArgs(1).
+			WillReturnRows(sampleEdgeRows())
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("Soul"), opts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Query should follow chronological samples to matches released in range")
+	})
+
+	t.Run("Open_Year_Range", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		opts := models.SearchOptions{Direction: models.DirectionBoth, Years: models.YearRange{To: 1979}}
+
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(`WHERE ys.id = sp.id AND ys.releaseYear <= \?\s+\)`).
+			WithArgs(1, "soul", 1979).
+			WillReturnRows(songIDRows())
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("Soul"), opts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Query should only bound the years that are set")
+	})
+
+	t.Run("Row_Cap", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		opts := models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth, MaxRows: 1}
+
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(2, 1))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(1, 2, "soul").WillReturnRows(songIDRows(1, 2))
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).AddRow(2, "Song 2", 1975, "Soul"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).AddRow(2, 202, "Artist 2", true))
+		mock.ExpectQuery("SELECT original_song_id, COUNT\\(DISTINCT sampled_in_song_id\\)").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "count"}))
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("soul"), opts)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Search should stop at the row cap")
+		assert.Equal(t, 2, results[0].MatchedSong.ID, "Seeds should be walked in the order they were matched")
+		assert.NoError(t, mock.ExpectationsWereMet(), "Search should not expand past the row cap")
+	})
+
+	t.Run("Excluded_Matches_Before_Row_Cap", func(t *testing.T) {
+		// Arrange
+		songQueries := []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+		}
+		opts := models.SearchOptions{
+			MaxDepth:  1,
+			Direction: models.DirectionDescendants,
+			MaxRows:   1,
+			Exclude:   models.SearchExclusions{Artists: []string{"Excluded Artist"}},
+		}
+
+		// 2 and 3 sample 1, and both match
+		mock.ExpectQuery(searchSeedsQuery).WithArgs("Song 1", "Artist 1").WillReturnRows(songIDRows(1))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(1, "soul").WillReturnRows(songIDRows())
+		mock.ExpectQuery(searchHopsQuery).WithArgs(1).
+			WillReturnRows(sampleEdgeRows([2]int{1, 2}, [2]int{1, 3}))
+		mock.ExpectQuery(searchMatchQuery).WithArgs(2, 3, "soul").WillReturnRows(songIDRows(2, 3))
+
+		// The matches are hydrated to apply the exclusions
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(2, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+				AddRow(2, "Song 2", 1970, "Soul").
+				AddRow(3, "Song 3", 1971, "Soul"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(2, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+				AddRow(2, 202, "Excluded Artist", true).
+				AddRow(3, 203, "Artist 3", true))
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+				AddRow(1, "Song 1", 2001, "Hip-Hop").
+				AddRow(3, "Song 3", 1971, "Soul"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+				AddRow(1, 201, "Artist 1", true).
+				AddRow(3, 203, "Artist 3", true))
+		mock.ExpectQuery("SELECT original_song_id, COUNT\\(DISTINCT sampled_in_song_id\\)").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "count"}))
+
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), songQueries, models.NewGenreFilter("Soul"), opts)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, 1, "Excluded songs should not take up the row cap")
+		assert.Equal(t, 3, results[0].MatchedSong.ID)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("No_Target_Genres", func(t *testing.T) {
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), []models.SongQuery{{Title: "Song 1", Artist: "Artist 1"}}, models.GenreFilter{Exclude: []string{"jazz"}}, models.SearchOptions{MaxDepth: 2})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Should not query without target genres")
+	})
+
+	t.Run("No_Seeds", func(t *testing.T) {
+		// Act
+		results, err := repo.FindSongsByGenreBFS(context.Background(), nil, models.NewGenreFilter("Jazz"), models.SearchOptions{MaxDepth: 2})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "Should not query without seeds")
+	})
+}
+
+func TestSongRepository_FindSamplePaths(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Find_Sample_Path", func(t *testing.T) {
+		// Arrange
+		opts := models.SearchOptions{MaxDepth: 4, Direction: models.DirectionAncestors}
+
+		// Song 7 is a duplicate of song 1, so the search starts from song 1
+		mock.ExpectQuery(`SELECT sc.songId, sc.canonicalId\s+FROM SongCanonical sc\s+WHERE sc.songId IN \(\?,\?\)`).
+			WithArgs(3, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}).
+				AddRow(7, 1))
+
+		// The "from" side is expanded first, then the smaller "to" side
+		mock.ExpectQuery(`SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam WHERE \(sam.sampled_in_song_id IN \(\?\)\)`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}).
+				AddRow(2, 1).
+				AddRow(6, 1))
+		mock.ExpectQuery(`SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam WHERE \(sam.original_song_id IN \(\?\)\)`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}).
+				AddRow(3, 2).
+				AddRow(3, 6))
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(1, 2, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+				AddRow(1, "Song 1", 2000, "hip-hop").
+				AddRow(2, "Song 2", 1990, "hip-hop").
+				AddRow(3, "Song 3", 1970, "jazz"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 2, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+				AddRow(1, 101, "Artist 1", true).
+				AddRow(2, 102, "Artist 2", true).
+				AddRow(3, 103, "Artist 3", true))
+
+		// Act
+		results, err := repo.FindSamplePaths(context.Background(), []int{7}, []int{3}, opts)
+
+		// Assert
+		require.NoError(t, err, "Should not return error when a path exists")
+		require.Len(t, results, 1, "Should return a single path by default")
+		assert.Equal(t, 2, results[0].Distance)
+		assert.Equal(t, "Song 1", results[0].SourceSong.Title)
+		assert.Equal(t, "Song 3", results[0].MatchedSong.Title)
+		require.Len(t, results[0].Path, 3)
+		assert.Equal(t, []models.TraversalDirection{models.DirectionAncestors, models.DirectionAncestors}, results[0].HopDirections)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("No_Path", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT sc.songId, sc.canonicalId").
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}))
+		mock.ExpectQuery("SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam").
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}))
+
+		// Act
+		results, err := repo.FindSamplePaths(context.Background(), []int{1}, []int{3}, models.SearchOptions{MaxDepth: 4, Direction: models.DirectionBoth})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, results, "Should stop once a side runs out of songs")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT sc.songId, sc.canonicalId").
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}))
+		mock.ExpectQuery("SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam").
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		results, err := repo.FindSamplePaths(context.Background(), []int{1}, []int{3}, models.SearchOptions{MaxDepth: 4, Direction: models.DirectionBoth})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_GetArtistNetwork(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	artistSampleColumns := []string{"id", "name", "isMainArtist", "id", "name", "isMainArtist", "sampled_in_song_id", "title", "original_song_id", "title"}
+
+	t.Run("Aggregates_Artist_Edges", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT id, name FROM Artist WHERE id = \\?").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Producer"))
+
+		mock.ExpectQuery(`WHERE \(sa_in.artistId IN \(\?\) OR sa_orig.artistId IN \(\?\)\)\s+AND sa_in.isMainArtist = 1`).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows(artistSampleColumns).
+				AddRow(1, "Producer", true, 2, "Band", true, 10, "Beat", 20, "Break").
+				AddRow(1, "Producer", true, 2, "Band", true, 11, "Other Beat", 21, "Other Break").
+				AddRow(3, "Rapper", true, 1, "Producer", true, 30, "Verse", 10, "Beat").
+				AddRow(1, "Producer", true, 1, "Producer", true, 12, "Remix", 10, "Beat"))
+
+		// Act
+		network, err := repo.GetArtistNetwork(context.Background(), 1, models.ArtistNetworkOptions{Depth: 1, MainArtistsOnly: true})
+
+		// Assert
+		require.NoError(t, err, "Should not return error when the artist exists")
+		require.Len(t, network.Edges, 2, "Self samples should be left out")
+		assert.Equal(t, models.ArtistEdge{
+			FromArtistID: 1,
+			ToArtistID:   2,
+			Count:        2,
+			Examples: []models.SamplePair{
+				{SampledInSongID: 10, SampledInTitle: "Beat", OriginalSongID: 20, OriginalTitle: "Break"},
+				{SampledInSongID: 11, SampledInTitle: "Other Beat", OriginalSongID: 21, OriginalTitle: "Other Break"},
+			},
+		}, network.Edges[0], "Heaviest edge should come first")
+		assert.Equal(t, 3, network.Edges[1].FromArtistID)
+		assert.Len(t, network.Artists, 3)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Artist_Not_Found", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT id, name FROM Artist WHERE id = \\?").
+			WithArgs(999).
+			WillReturnError(sql.ErrNoRows)
+
+		// Act
+		network, err := repo.GetArtistNetwork(context.Background(), 999, models.ArtistNetworkOptions{Depth: 1})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, network)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT id, name FROM Artist WHERE id = \\?").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Producer"))
+		mock.ExpectQuery("FROM CanonicalSample s").
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		network, err := repo.GetArtistNetwork(context.Background(), 1, models.ArtistNetworkOptions{Depth: 1})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, network)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_GetSongNeighborhood(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+
+	t.Run("Caps_Nodes", func(t *testing.T) {
+		// Arrange
+		// Song 7 is a duplicate of song 1, so the graph is expanded from song 1
+		mock.ExpectQuery(`SELECT sc.songId, sc.canonicalId\s+FROM SongCanonical sc`).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}).
+				AddRow(7, 1))
+		mock.ExpectQuery(`SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam WHERE \(sam.sampled_in_song_id IN \(\?\)\)`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}).
+				AddRow(2, 1).
+				AddRow(6, 1))
+		mock.ExpectQuery(`SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam WHERE \(sam.sampled_in_song_id IN \(\?,\?\)\)`).
+			WithArgs(2, 6).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}).
+				AddRow(3, 2).
+				AddRow(3, 6))
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(1, 2, 6).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}).
+				AddRow(1, "Song 1", 2000, "hip-hop").
+				AddRow(2, "Song 2", 1990, "hip-hop").
+				AddRow(6, "Song 6", 1965, "soul"))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(1, 2, 6).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}).
+				AddRow(1, 101, "Artist 1", true).
+				AddRow(2, 102, "Artist 2", true).
+				AddRow(6, 106, "Artist 6", true))
+
+		// Act
+		neighborhood, err := repo.GetSongNeighborhood(context.Background(), 7, models.NeighborhoodOptions{Radius: 2, Direction: models.DirectionAncestors, MaxNodes: 3})
+
+		// Assert
+		require.NoError(t, err, "Should not return error when the song exists")
+		assert.Equal(t, 1, neighborhood.SongID)
+		require.Len(t, neighborhood.Songs, 3)
+		assert.Equal(t, "Song 1", neighborhood.Songs[0].Title, "The song itself should come first")
+		assert.Equal(t, []models.SampleEdge{{SampledInID: 1, OriginalID: 2}, {SampledInID: 1, OriginalID: 6}}, neighborhood.Edges)
+		assert.True(t, neighborhood.Truncated, "Song 3 should be left out by the node cap")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Song_Not_Found", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT sc.songId, sc.canonicalId").
+			WithArgs(999).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}))
+		mock.ExpectQuery("SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam").
+			WithArgs(999, 999).
+			WillReturnRows(sqlmock.NewRows([]string{"original_song_id", "sampled_in_song_id"}))
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, GROUP_CONCAT").
+			WithArgs(999).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "genres"}))
+		mock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").
+			WithArgs(999).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"}))
+
+		// Act
+		neighborhood, err := repo.GetSongNeighborhood(context.Background(), 999, models.NeighborhoodOptions{Radius: 1, Direction: models.DirectionBoth})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, neighborhood)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery("SELECT sc.songId, sc.canonicalId").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}))
+		mock.ExpectQuery("SELECT sam.original_song_id, sam.sampled_in_song_id FROM CanonicalSample sam").
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		neighborhood, err := repo.GetSongNeighborhood(context.Background(), 1, models.NeighborhoodOptions{Radius: 1, Direction: models.DirectionBoth})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, neighborhood)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestSongRepository_GetGenreProfile(t *testing.T) {
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, true)
+	seeds := []models.SongQuery{{SongIDs: []int{1}}, {Title: "Seed Song", Artist: "Seed Artist"}}
+	genresQuery := `GROUP_CONCAT\(DISTINCT LOWER\(g.name\) ORDER BY LOWER\(g.name\)\), ''\) as genres\s+FROM Song sp(.|\s)+WHERE sp.id IN`
+	genreRows := func(rows ...[2]interface{}) *sqlmock.Rows {
+		genres := sqlmock.NewRows([]string{"id", "genres"})
+		for _, row := range rows {
+			genres.AddRow(row[0], row[1])
+		}
+		return genres
+	}
+
+	t.Run("Groups_By_Depth_And_Genres", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery(searchSeedsQuery).
+			WithArgs(1, "Seed Song", "Seed Artist").
+			WillReturnRows(songIDRows(1, 2))
+		// 1 samples 10 and 11, 2 samples 10 and 12, 10 samples 20 and 11 samples 1
+		mock.ExpectQuery(searchHopsQuery).WithArgs(1, 2).
+			WillReturnRows(sampleEdgeRows([2]int{10, 1}, [2]int{11, 1}, [2]int{10, 2}, [2]int{12, 2}))
+		mock.ExpectQuery(searchHopsQuery).WithArgs(10, 11, 12).
+			WillReturnRows(sampleEdgeRows([2]int{20, 10}, [2]int{1, 11}))
+		mock.ExpectQuery(genresQuery).WithArgs(10, 11, 12).
+			WillReturnRows(genreRows([2]interface{}{10, "hip-hop"}, [2]interface{}{11, "jazz,soul"}, [2]interface{}{12, ""}))
+		mock.ExpectQuery(genresQuery).WithArgs(20).
+			WillReturnRows(genreRows([2]interface{}{20, "Jazz"}))
+
+		// Act
+		profile, err := repo.GetGenreProfile(context.Background(), seeds, models.SearchOptions{MaxDepth: 2, Direction: models.DirectionAncestors})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, profile.Levels, 2)
+		assert.Equal(t, 1, profile.Levels[0].Depth)
+		assert.Equal(t, []models.GenreSetCount{
+			{Genres: nil, Songs: 1},
+			{Genres: []string{"hip-hop"}, Songs: 1},
+			{Genres: []string{"jazz", "soul"}, Songs: 1},
+		}, profile.Levels[0].GenreSets, "Song 10 should count once although both seeds reach it")
+		assert.Equal(t, []models.GenreSetCount{{Genres: []string{"jazz"}, Songs: 1}}, profile.Levels[1].GenreSets, "Seeds reached again should not count")
+		assert.False(t, profile.Truncated)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Year_Range", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery(searchSeedsQuery).
+			WithArgs(1, "Seed Song", "Seed Artist").
+			WillReturnRows(songIDRows(1, 2))
+		mock.ExpectQuery(searchHopsQuery).WithArgs(1, 2, 1, 2).
+			WillReturnRows(sampleEdgeRows([2]int{10, 1}, [2]int{2, 30}))
+		mock.ExpectQuery(genresQuery+`(.|\s)+ys.releaseYear >= \? AND ys.releaseYear <= \?`).
+			WithArgs(10, 30, 1970, 1979).
+			WillReturnRows(genreRows([2]interface{}{30, "soul"}))
+
+		// Act
+		profile, err := repo.GetGenreProfile(context.Background(), seeds, models.SearchOptions{
+			MaxDepth:  1,
+			Direction: models.DirectionBoth,
+			Years:     models.YearRange{From: 1970, To: 1979},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, profile.Levels, 1)
+		assert.Equal(t, []models.GenreSetCount{{Genres: []string{"soul"}, Songs: 1}}, profile.Levels[0].GenreSets)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Caps_Rows", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery(searchSeedsQuery).
+			WithArgs(1, "Seed Song", "Seed Artist").
+			WillReturnRows(songIDRows(1, 2))
+		mock.ExpectQuery(searchHopsQuery).WithArgs(1, 2).
+			WillReturnRows(sampleEdgeRows([2]int{10, 1}, [2]int{11, 1}, [2]int{12, 2}))
+		mock.ExpectQuery(genresQuery).WithArgs(10, 11).
+			WillReturnRows(genreRows([2]interface{}{10, "hip-hop"}, [2]interface{}{11, "jazz"}))
+
+		// Act
+		profile, err := repo.GetGenreProfile(context.Background(), seeds, models.SearchOptions{MaxDepth: 3, Direction: models.DirectionAncestors, MaxRows: 2})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, profile.Levels, 1)
+		assert.Equal(t, 2, profile.Levels[0].Songs(), "Should stop after MaxRows songs")
+		assert.True(t, profile.Truncated)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Database_Error", func(t *testing.T) {
+		// Arrange
+		mock.ExpectQuery(searchSeedsQuery).
+			WillReturnError(sql.ErrConnDone)
+
+		// Act
+		profile, err := repo.GetGenreProfile(context.Background(), seeds, models.SearchOptions{MaxDepth: 2})
+
+		// Assert
+		assert.Error(t, err, "Should return error when database fails")
+		assert.Nil(t, profile)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
 }
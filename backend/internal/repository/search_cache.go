@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"go.uber.org/zap"
+)
+
+// sampleDBFingerprintSQL summarizes the tables genre searches read. A change in
+// any of them means cached results may be stale. Only row counts and ID sums
+// are compared, so updates in place that keep them, such as a retitled song or
+// a retagged genre, go unnoticed and their cached results live out the TTL.
+// The SongCanonical sum is only read when the samples DB has the table.
+func sampleDBFingerprintSQL(canonical bool) string {
+	canonicalSum := "0"
+	if canonical {
+		canonicalSum = "(SELECT COALESCE(SUM(sc.canonicalId), 0) FROM SongCanonical sc)"
+	}
+	return `
+	SELECT
+		(SELECT COUNT(*) FROM Song),
+		(SELECT COALESCE(MAX(id), 0) FROM Song),
+		(SELECT COUNT(*) FROM Sample),
+		(SELECT COUNT(*) FROM _SongToGenre),
+		` + canonicalSum + `
+`
+}
+
+// CachedSongRepository wraps a SongRepositoryInterface and caches the results
+// of FindSongsByGenreBFS in a size-bounded LRU with a TTL. Every other method
+// goes straight to the wrapped repository. Run drops the cache whenever the
+// samples DB changes. When the wrapped repository is a SongGraphIndex, the
+// cache must also be dropped on every index reload, see SongGraphIndex.OnReload,
+// or it refills from the snapshot read before the change.
+type CachedSongRepository struct {
+	SongRepositoryInterface
+	db      samplesDB
+	maxSize int
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation counts invalidations, so searches that started before the
+	// latest one can tell their results are stale
+	generation uint64
+	stats      models.SearchCacheStats
+	dbVersion  string
+}
+
+// searchCacheEntry is one cached search, the front of the LRU list being the
+// most recently used
+type searchCacheEntry struct {
+	key        string
+	results    []models.SearchResult
+	expiresAt  time.Time
+	generation uint64
+}
+
+// NewCachedSongRepository caches up to maxSize genre searches of repo for ttl.
+// db is the samples DB Run watches for changes, canonical whether it has the
+// SongCanonical table.
+func NewCachedSongRepository(repo SongRepositoryInterface, db *sql.DB, canonical bool, maxSize int, ttl time.Duration) *CachedSongRepository {
+	return &CachedSongRepository{
+		SongRepositoryInterface: repo,
+		db:                      samplesDB{DB: db, canonical: canonical},
+		maxSize:                 maxSize,
+		ttl:                     ttl,
+		entries:                 make(map[string]*list.Element),
+		lru:                     list.New(),
+	}
+}
+
+// FindSongsByGenreBFS returns cached results for searches seen within the TTL
+// and runs and caches the others. Failed searches are not cached, nor are
+// searches the cache was invalidated during.
+func (c *CachedSongRepository) FindSongsByGenreBFS(ctx context.Context, songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) ([]models.SearchResult, error) {
+	key := searchCacheKey(songQueries, filter, opts)
+	results, generation, ok := c.get(key)
+	if ok {
+		return results, nil
+	}
+
+	results, err := c.SongRepositoryInterface.FindSongsByGenreBFS(ctx, songQueries, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(key, generation, results)
+	return copyResults(results), nil
+}
+
+// get returns a copy of the cached results for key, or the current generation
+// to put the search's results under on a miss
+func (c *CachedSongRepository) get(key string) ([]models.SearchResult, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+
+	entry := element.Value.(*searchCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(element)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+
+	c.lru.MoveToFront(element)
+	c.stats.Hits++
+	return copyResults(entry.results), c.generation, true
+}
+
+// put caches the results of a search that started in generation. Results from
+// before the latest Invalidate are dropped, as they may predate the change.
+func (c *CachedSongRepository) put(key string, generation uint64, results []models.SearchResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+
+	c.entries[key] = c.lru.PushFront(&searchCacheEntry{
+		key:        key,
+		results:    results,
+		expiresAt:  time.Now().Add(c.ttl),
+		generation: generation,
+	})
+
+	for c.lru.Len() > c.maxSize {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *CachedSongRepository) removeElement(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*searchCacheEntry).key)
+}
+
+// Invalidate drops every cached search, including the ones still running
+func (c *CachedSongRepository) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.stats.Invalidations++
+}
+
+// Stats returns the cache's hit/miss counters and current size
+func (c *CachedSongRepository) Stats() models.SearchCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.MaxEntries = c.maxSize
+	return stats
+}
+
+// Run checks the samples DB for changes every interval until ctx is cancelled,
+// dropping the cache when it changed, and logs the cache stats.
+func (c *CachedSongRepository) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	if err := c.checkSamplesDB(ctx); err != nil {
+		zap.L().Error("Failed to check samples DB for changes", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.checkSamplesDB(ctx); err != nil {
+				zap.L().Error("Failed to check samples DB for changes", zap.Error(err))
+			}
+			stats := c.Stats()
+			zap.L().Info("Search cache stats",
+				zap.Int64("hits", stats.Hits),
+				zap.Int64("misses", stats.Misses),
+				zap.Int64("evictions", stats.Evictions),
+				zap.Int("entries", stats.Entries))
+		}
+	}
+}
+
+// checkSamplesDB invalidates the cache when the samples DB fingerprint differs
+// from the last one seen. The first check only records the fingerprint.
+func (c *CachedSongRepository) checkSamplesDB(ctx context.Context) error {
+	var songs, maxSongID, samples, genres, canonical int64
+	err := c.db.QueryRowContext(ctx, sampleDBFingerprintSQL(c.db.canonical)).Scan(&songs, &maxSongID, &samples, &genres, &canonical)
+	if err != nil {
+		return fmt.Errorf("error reading samples DB fingerprint: %v", err)
+	}
+	version := fmt.Sprintf("%d/%d/%d/%d/%d", songs, maxSongID, samples, genres, canonical)
+
+	c.mu.Lock()
+	previous := c.dbVersion
+	c.dbVersion = version
+	c.mu.Unlock()
+
+	if previous != "" && previous != version {
+		zap.L().Info("Samples DB changed, dropping search cache",
+			zap.String("previous", previous),
+			zap.String("current", version))
+		c.Invalidate()
+	}
+	return nil
+}
+
+// searchCacheKey identifies a genre search independently of seed order, case
+// and whitespace, and of the order of genres and exclusions
+func searchCacheKey(songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) string {
+	seeds := make([]string, len(songQueries))
+	for i, query := range songQueries {
+		seeds[i] = songQueryKey(query)
+	}
+	sort.Strings(seeds)
+
+	match := filter.Match
+	if match == "" {
+		match = models.GenreMatchAny
+	}
+	direction := opts.Direction
+	if direction == "" {
+		direction = models.DirectionBoth
+	}
+
+	excludedArtists := make([]string, len(opts.Exclude.Artists))
+	for i, artist := range opts.Exclude.Artists {
+		excludedArtists[i] = strings.ToLower(strings.TrimSpace(artist))
+	}
+	sort.Strings(excludedArtists)
+	excludedSongs := make([]string, len(opts.Exclude.Songs))
+	for i, song := range opts.Exclude.Songs {
+		excludedSongs[i] = songQueryKey(song)
+	}
+	sort.Strings(excludedSongs)
+
+	targets := filter.TargetGenres()
+	sort.Strings(targets)
+	excludedGenres := filter.ExcludedGenres()
+	sort.Strings(excludedGenres)
+
+	fields := []string{
+		strings.Join(seeds, "\x1e"),
+		strings.Join(targets, ","),
+		strings.Join(excludedGenres, ","),
+		string(match),
+		strconv.Itoa(opts.MaxDepth),
+		string(direction),
+		strconv.Itoa(opts.PathsPerMatch()),
+		strconv.Itoa(opts.Limit),
+		strconv.Itoa(opts.MaxRows),
+		fmt.Sprintf("%d-%d", opts.Years.From, opts.Years.To),
+		strconv.FormatBool(opts.Chronological),
+		strings.Join(excludedArtists, "\x1e"),
+		strings.Join(excludedSongs, "\x1e"),
+	}
+	return strings.Join(fields, "\x1f")
+}
+
+// songQueryKey normalizes a seed or excluded song, preferring its song IDs
+func songQueryKey(query models.SongQuery) string {
+	if len(query.SongIDs) > 0 {
+		ids := uniqueSongIDs(query.SongIDs)
+		parts := make([]string, len(ids))
+		for i, id := range ids {
+			parts[i] = strconv.Itoa(id)
+		}
+		return "ids:" + strings.Join(parts, ",")
+	}
+	return strings.ToLower(strings.TrimSpace(query.Title)) + "\x00" + strings.ToLower(strings.TrimSpace(query.Artist))
+}
+
+// copyResults deep copies results so callers editing them, down to a path
+// song's genres, leave the cached entry alone
+func copyResults(results []models.SearchResult) []models.SearchResult {
+	if results == nil {
+		return nil
+	}
+	copied := make([]models.SearchResult, len(results))
+	for i, result := range results {
+		copied[i] = result
+		copied[i].SourceSong = copySongNode(result.SourceSong)
+		copied[i].MatchedSong = copySongNode(result.MatchedSong)
+		if result.Path != nil {
+			copied[i].Path = make([]models.SongNode, len(result.Path))
+			for j, song := range result.Path {
+				copied[i].Path[j] = copySongNode(song)
+			}
+		}
+		if result.HopDirections != nil {
+			copied[i].HopDirections = append([]models.TraversalDirection(nil), result.HopDirections...)
+		}
+	}
+	return copied
+}
+
+func copySongNode(song models.SongNode) models.SongNode {
+	if song.Artists != nil {
+		song.Artists = append([]models.Artist(nil), song.Artists...)
+	}
+	if song.Genres != nil {
+		song.Genres = append([]string(nil), song.Genres...)
+	}
+	return song
+}
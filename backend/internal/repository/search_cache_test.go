@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSongRepository answers every genre search with one result and
+// counts the searches that reach it. during runs inside each search.
+type countingSongRepository struct {
+	SongRepositoryInterface
+	searches int
+	err      error
+	during   func()
+}
+
+func (r *countingSongRepository) FindSongsByGenreBFS(ctx context.Context, songQueries []models.SongQuery, filter models.GenreFilter, opts models.SearchOptions) ([]models.SearchResult, error) {
+	r.searches++
+	if r.during != nil {
+		r.during()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return []models.SearchResult{{
+		MatchedSong: models.SongNode{ID: r.searches, Genres: []string{"jazz"}},
+		Path:        []models.SongNode{{ID: 1, Artists: []models.Artist{{ID: 1, Name: "Seed Artist"}}}, {ID: r.searches}},
+	}}, nil
+}
+
+func TestCachedSongRepository_FindSongsByGenreBFS(t *testing.T) {
+	seeds := []models.SongQuery{{Title: "Seed Song", Artist: "Seed Artist"}, {SongIDs: []int{4, 1}}}
+	opts := models.SearchOptions{MaxDepth: 2, Direction: models.DirectionBoth}
+
+	t.Run("Caches_Normalized_Searches", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{}
+		cache := NewCachedSongRepository(repo, nil, true, 10, time.Minute)
+
+		// Act
+		first, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz", "Soul"), opts)
+		require.NoError(t, err)
+		second, err := cache.FindSongsByGenreBFS(context.Background(),
+			[]models.SongQuery{{SongIDs: []int{1, 4}}, {Title: " seed song", Artist: "SEED ARTIST"}},
+			models.NewGenreFilter("soul", "jazz"),
+			models.SearchOptions{MaxDepth: 2})
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 1, repo.searches, "Same search in another order and case should hit the cache")
+		assert.Equal(t, first, second)
+		stats := cache.Stats()
+		assert.Equal(t, int64(1), stats.Hits)
+		assert.Equal(t, int64(1), stats.Misses)
+		assert.Equal(t, 1, stats.Entries)
+	})
+
+	t.Run("Different_Options_Miss", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{}
+		cache := NewCachedSongRepository(repo, nil, true, 10, time.Minute)
+		filter := models.NewGenreFilter("jazz")
+
+		// Act
+		for _, searchOpts := range []models.SearchOptions{
+			opts,
+			{MaxDepth: 3, Direction: models.DirectionBoth},
+			{MaxDepth: 2, Direction: models.DirectionAncestors},
+			{MaxDepth: 2, Direction: models.DirectionBoth, Years: models.YearRange{From: 1970, To: 1979}},
+			{MaxDepth: 2, Direction: models.DirectionBoth, Exclude: models.SearchExclusions{Artists: []string{"Some Band"}}},
+		} {
+			_, err := cache.FindSongsByGenreBFS(context.Background(), seeds, filter, searchOpts)
+			require.NoError(t, err)
+		}
+
+		// Assert
+		assert.Equal(t, 5, repo.searches, "Every option should be part of the key")
+	})
+
+	t.Run("Evicts_Least_Recently_Used", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{}
+		cache := NewCachedSongRepository(repo, nil, true, 2, time.Minute)
+		search := func(genre string) {
+			_, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter(genre), opts)
+			require.NoError(t, err)
+		}
+
+		// Act
+		search("jazz")
+		search("soul")
+		search("jazz")
+		search("funk")
+		search("jazz")
+		search("soul")
+
+		// Assert
+		assert.Equal(t, 4, repo.searches, "Soul should have been evicted as the least recently used search")
+		stats := cache.Stats()
+		assert.Equal(t, int64(2), stats.Evictions)
+		assert.Equal(t, 2, stats.Entries)
+	})
+
+	t.Run("Expires_After_TTL", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{}
+		cache := NewCachedSongRepository(repo, nil, true, 10, time.Millisecond)
+
+		// Act
+		_, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+		_, err = cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 2, repo.searches)
+		assert.Equal(t, int64(1), cache.Stats().Expirations)
+	})
+
+	t.Run("Drops_Results_From_Before_Invalidate", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{}
+		cache := NewCachedSongRepository(repo, nil, true, 10, time.Minute)
+		repo.during = func() {
+			repo.during = nil
+			cache.Invalidate()
+		}
+
+		// Act
+		_, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+		_, err = cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+		_, err = cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 2, repo.searches, "The search invalidated midway should not be cached, the next one should")
+		assert.Equal(t, 1, cache.Stats().Entries)
+	})
+
+	t.Run("Returns_Copies", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{}
+		cache := NewCachedSongRepository(repo, nil, true, 10, time.Minute)
+		first, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+
+		// Act
+		first[0].MatchedSong.Genres[0] = "edited"
+		first[0].Path[0].Artists[0].Name = "edited"
+		second, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"jazz"}, second[0].MatchedSong.Genres, "Editing a result should leave the cache alone")
+		assert.Equal(t, "Seed Artist", second[0].Path[0].Artists[0].Name)
+	})
+
+	t.Run("Errors_Are_Not_Cached", func(t *testing.T) {
+		// Arrange
+		repo := &countingSongRepository{err: sql.ErrConnDone}
+		cache := NewCachedSongRepository(repo, nil, true, 10, time.Minute)
+
+		// Act
+		_, err := cache.FindSongsByGenreBFS(context.Background(), seeds, models.NewGenreFilter("jazz"), opts)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Zero(t, cache.Stats().Entries)
+	})
+}
+
+func TestCachedSongRepository_CheckSamplesDB(t *testing.T) {
+	// Arrange
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := &countingSongRepository{}
+	cache := NewCachedSongRepository(repo, db, true, 10, time.Minute)
+	fingerprintColumns := []string{"songs", "maxSongId", "samples", "genres", "canonical"}
+
+	for _, samples := range []int{50, 50, 51} {
+		mock.ExpectQuery(`SELECT\s+\(SELECT COUNT\(\*\) FROM Song\)`).
+			WillReturnRows(sqlmock.NewRows(fingerprintColumns).AddRow(10, 12, samples, 30, 4))
+	}
+	search := func() {
+		_, err := cache.FindSongsByGenreBFS(context.Background(), []models.SongQuery{{Title: "Seed Song", Artist: "Seed Artist"}}, models.NewGenreFilter("jazz"), models.SearchOptions{MaxDepth: 2})
+		require.NoError(t, err)
+	}
+
+	// Act
+	require.NoError(t, cache.checkSamplesDB(context.Background()))
+	search()
+	require.NoError(t, cache.checkSamplesDB(context.Background()))
+	search()
+	require.NoError(t, cache.checkSamplesDB(context.Background()))
+	search()
+
+	// Assert
+	assert.Equal(t, 2, repo.searches, "Only a changed samples DB should drop the cache")
+	assert.Equal(t, int64(1), cache.Stats().Invalidations)
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
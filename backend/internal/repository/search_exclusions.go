@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// resultExcluder decides which matched songs a genre search drops. Names are
+// compared folded, so "The Beatles" excludes "beatles" and "Song - Remastered"
+// excludes "Song".
+type resultExcluder struct {
+	songIDs map[int]struct{}
+	artists map[string]struct{}
+	// songs maps a folded artist to the normalized titles excluded for it
+	songs map[string]map[string]struct{}
+}
+
+func newResultExcluder(exclusions models.SearchExclusions) *resultExcluder {
+	excluder := &resultExcluder{
+		songIDs: make(map[int]struct{}),
+		artists: make(map[string]struct{}),
+		songs:   make(map[string]map[string]struct{}),
+	}
+	for _, artist := range exclusions.Artists {
+		if key := excludedArtistKey(artist); key != "" {
+			excluder.artists[key] = struct{}{}
+		}
+	}
+	for _, song := range exclusions.Songs {
+		for _, id := range song.SongIDs {
+			excluder.songIDs[id] = struct{}{}
+		}
+		if len(song.SongIDs) > 0 {
+			continue
+		}
+
+		artist, title := excludedArtistKey(song.Artist), normalizeTitle(song.Title)
+		if artist == "" || title == "" {
+			continue
+		}
+		if excluder.songs[artist] == nil {
+			excluder.songs[artist] = make(map[string]struct{})
+		}
+		excluder.songs[artist][title] = struct{}{}
+	}
+	return excluder
+}
+
+func excludedArtistKey(artist string) string {
+	return strings.TrimPrefix(foldText(artist), "the ")
+}
+
+// excludes reports whether song is excluded by ID, by one of its artists or by
+// its title and one of its artists
+func (e *resultExcluder) excludes(song models.SongNode) bool {
+	if _, excluded := e.songIDs[song.ID]; excluded {
+		return true
+	}
+
+	title := ""
+	for _, artist := range song.Artists {
+		key := excludedArtistKey(artist.Name)
+		if _, excluded := e.artists[key]; excluded {
+			return true
+		}
+		if titles, ok := e.songs[key]; ok {
+			if title == "" {
+				title = normalizeTitle(song.Title)
+			}
+			if _, excluded := titles[title]; excluded {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// SongCanonical maps every duplicate Song row to the canonical song of its
+// cluster. Canonical songs have no row, so queries resolve a song with
+// COALESCE(sc.canonicalId, s.id) and songs added since the last run stay their
+// own canonical song.
+const songCanonicalTableSQL = `
+	CREATE TABLE IF NOT EXISTS SongCanonical (
+		songId INT NOT NULL PRIMARY KEY,
+		canonicalId INT NOT NULL,
+		INDEX idx_song_canonical_canonical (canonicalId)
+	)
+`
+
+// samplesDB is the samples DB the repositories query. canonical says whether it
+// has the SongCanonical table; queries resolve songs through canonicalSongSQL
+// and canonicalSampleCTE, which leave songs as they are without it, so
+// searches run on the raw Sample table.
+type samplesDB struct {
+	*sql.DB
+	canonical bool
+}
+
+// canonicalSongSQL returns the join resolving the song column songID to its
+// canonical song, and the expression of that song. The join aliases
+// SongCanonical as sc and is empty without the table.
+func (db samplesDB) canonicalSongSQL(songID string) (join, id string) {
+	if !db.canonical {
+		return "", songID
+	}
+	return "LEFT JOIN SongCanonical sc ON sc.songId = " + songID, "COALESCE(sc.canonicalId, " + songID + ")"
+}
+
+// canonicalSampleCTE returns the CanonicalSample CTE: the Sample table with
+// both songs resolved to their canonical song. Samples between duplicates of
+// the same song, or of a song and itself, are dropped.
+func (db samplesDB) canonicalSampleCTE() string {
+	if !db.canonical {
+		return `CanonicalSample AS (
+            SELECT DISTINCT cs.original_song_id, cs.sampled_in_song_id
+            FROM Sample cs
+            WHERE cs.original_song_id <> cs.sampled_in_song_id
+        )`
+	}
+	return `CanonicalSample AS (
+            SELECT DISTINCT
+                COALESCE(sco.canonicalId, cs.original_song_id) as original_song_id,
+                COALESCE(scs.canonicalId, cs.sampled_in_song_id) as sampled_in_song_id
+            FROM Sample cs
+            LEFT JOIN SongCanonical sco ON sco.songId = cs.original_song_id
+            LEFT JOIN SongCanonical scs ON scs.songId = cs.sampled_in_song_id
+            WHERE COALESCE(sco.canonicalId, cs.original_song_id) <> COALESCE(scs.canonicalId, cs.sampled_in_song_id)
+        )`
+}
+
+// sameSongSQL returns the query selecting the songs that stand for the song
+// with ID ?: its canonical song or, without the SongCanonical table, every
+// song with its title and release year, the way duplicates were merged before
+// the canonicalize job.
+func (db samplesDB) sameSongSQL() string {
+	if !db.canonical {
+		return `SELECT s1.id
+            FROM Song s1
+            WHERE EXISTS (
+                SELECT 1 FROM Song s2
+                WHERE s2.id = ?
+                AND s1.title = s2.title
+                AND ((s1.releaseYear IS NULL AND s2.releaseYear IS NULL) OR s1.releaseYear = s2.releaseYear)
+            )`
+	}
+	return `SELECT COALESCE(sc.canonicalId, s.id) as id
+            FROM Song s
+            LEFT JOIN SongCanonical sc ON sc.songId = s.id
+            WHERE s.id = ?`
+}
+
+// HasSongCanonicalTable reports whether the samples DB has the SongCanonical
+// table. The canonicalize job creates it; the server never does.
+func HasSongCanonicalTable(db *sql.DB) (bool, error) {
+	var tables int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = 'SongCanonical'
+	`).Scan(&tables)
+	if err != nil {
+		return false, fmt.Errorf("error checking song canonical table: %v", err)
+	}
+	return tables > 0, nil
+}
+
+// SongCanonicalizer clusters duplicate Song rows of the samples DB. Songs are
+// duplicates when they have the same title, ignoring case, and release year and
+// share at least one artist. The lowest song ID of a cluster is its canonical
+// song.
+type SongCanonicalizer struct {
+	db *sql.DB
+}
+
+func NewSongCanonicalizer(db *sql.DB) *SongCanonicalizer {
+	return &SongCanonicalizer{db: db}
+}
+
+// EnsureTable creates the SongCanonical table when it does not exist yet. It
+// needs DDL rights on the samples DB, so only the canonicalize job calls it.
+func (c *SongCanonicalizer) EnsureTable() error {
+	if _, err := c.db.Exec(songCanonicalTableSQL); err != nil {
+		return fmt.Errorf("error creating song canonical table: %v", err)
+	}
+	return nil
+}
+
+// canonicalCandidate is one (song, artist) credit read by the canonicalizer
+type canonicalCandidate struct {
+	songID   int
+	title    string
+	year     sql.NullInt64
+	artistID int
+}
+
+// Run rebuilds the SongCanonical mapping and returns how many duplicate songs
+// it mapped. The mapping is replaced in one transaction, so readers see either
+// the old or the new clusters.
+func (c *SongCanonicalizer) Run() (int, error) {
+	rows, err := c.db.Query(`
+		SELECT s.id, s.title, s.releaseYear, sa.artistId
+		FROM Song s
+		JOIN SongArtist sa ON sa.songId = s.id
+		ORDER BY s.id
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("error loading songs to canonicalize: %v", err)
+	}
+	defer rows.Close()
+
+	var candidates []canonicalCandidate
+	for rows.Next() {
+		var candidate canonicalCandidate
+		if err := rows.Scan(&candidate.songID, &candidate.title, &candidate.year, &candidate.artistID); err != nil {
+			return 0, fmt.Errorf("error scanning song to canonicalize: %v", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error loading songs to canonicalize: %v", err)
+	}
+
+	canonical := clusterDuplicateSongs(candidates)
+	if err := c.replaceMapping(canonical); err != nil {
+		return 0, err
+	}
+
+	zap.L().Info("Canonicalized duplicate songs",
+		zap.Int("songs", len(candidates)),
+		zap.Int("duplicates", len(canonical)))
+	return len(canonical), nil
+}
+
+func (c *SongCanonicalizer) replaceMapping(canonical map[int]int) error {
+	songIDs := make([]int, 0, len(canonical))
+	for songID := range canonical {
+		songIDs = append(songIDs, songID)
+	}
+	sort.Ints(songIDs)
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting song canonical update: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM SongCanonical`); err != nil {
+		return fmt.Errorf("error clearing song canonical table: %v", err)
+	}
+
+	for start := 0; start < len(songIDs); start += songDetailsBatchSize {
+		batch := songIDs[start:min(start+songDetailsBatchSize, len(songIDs))]
+		values := make([]string, len(batch))
+		args := make([]interface{}, 0, 2*len(batch))
+		for i, songID := range batch {
+			values[i] = "(?, ?)"
+			args = append(args, songID, canonical[songID])
+		}
+
+		query := `INSERT INTO SongCanonical (songId, canonicalId) VALUES ` + strings.Join(values, ", ")
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("error saving song canonical mapping: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing song canonical mapping: %v", err)
+	}
+	return nil
+}
+
+func titleYearKey(title string, releaseYear sql.NullInt64) string {
+	if !releaseYear.Valid {
+		return strings.ToLower(title) + "\x00"
+	}
+	return fmt.Sprintf("%s\x00%d", strings.ToLower(title), releaseYear.Int64)
+}
+
+// clusterDuplicateSongs groups songs by title and release year, then joins the
+// songs of a group that share an artist. It maps every song that is not the
+// lowest ID of its cluster to that ID.
+func clusterDuplicateSongs(candidates []canonicalCandidate) map[int]int {
+	parent := make(map[int]int)
+	var find func(id int) int
+	find = func(id int) int {
+		root, ok := parent[id]
+		if !ok || root == id {
+			return id
+		}
+		root = find(root)
+		parent[id] = root
+		return root
+	}
+	union := func(a, b int) {
+		rootA, rootB := find(a), find(b)
+		if rootA == rootB {
+			return
+		}
+		if rootA < rootB {
+			parent[rootB] = rootA
+		} else {
+			parent[rootA] = rootB
+		}
+	}
+
+	// firstByCredit remembers the first song seen for each title, year and artist
+	firstByCredit := make(map[string]int)
+	for _, candidate := range candidates {
+		key := fmt.Sprintf("%s\x00%d", titleYearKey(candidate.title, candidate.year), candidate.artistID)
+		if first, ok := firstByCredit[key]; ok {
+			union(first, candidate.songID)
+			continue
+		}
+		firstByCredit[key] = candidate.songID
+	}
+
+	canonical := make(map[int]int)
+	for songID := range parent {
+		if root := find(songID); root != songID {
+			canonical[songID] = root
+		}
+	}
+	return canonical
+}
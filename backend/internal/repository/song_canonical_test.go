@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterDuplicateSongs(t *testing.T) {
+	year := func(y int64) sql.NullInt64 { return sql.NullInt64{Int64: y, Valid: true} }
+	candidates := []canonicalCandidate{
+		{songID: 1, title: "Seed Song", year: year(2000), artistID: 101},
+		{songID: 2, title: "Seed Song", year: year(2001), artistID: 101},
+		{songID: 3, title: "SEED SONG", year: year(2000), artistID: 102},
+		{songID: 3, title: "SEED SONG", year: year(2000), artistID: 101},
+		{songID: 4, title: "Seed Song", year: year(2000), artistID: 102},
+		{songID: 5, title: "Seed Song", year: year(2000), artistID: 103},
+		{songID: 6, title: "Undated Song", artistID: 104},
+		{songID: 7, title: "Undated Song", artistID: 104},
+	}
+
+	// Act
+	canonical := clusterDuplicateSongs(candidates)
+
+	// Assert
+	assert.Equal(t, map[int]int{3: 1, 4: 1, 7: 6}, canonical,
+		"Should join songs through shared artists and leave other years and artists alone")
+}
+
+func TestSongCanonicalizer_Run(t *testing.T) {
+	t.Run("Replaces_Mapping", func(t *testing.T) {
+		// Arrange
+		db, mock := setupSongTestDB(t)
+		defer db.Close()
+		canonicalizer := NewSongCanonicalizer(db)
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, sa.artistId").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "artistId"}).
+				AddRow(1, "Seed Song", 2000, 101).
+				AddRow(2, "Other Song", 1990, 102).
+				AddRow(4, "seed song", 2000, 101))
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM SongCanonical").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`INSERT INTO SongCanonical \(songId, canonicalId\) VALUES \(\?, \?\)`).
+			WithArgs(4, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Act
+		duplicates, err := canonicalizer.Run()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, duplicates)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("Rolls_Back_On_Error", func(t *testing.T) {
+		// Arrange
+		db, mock := setupSongTestDB(t)
+		defer db.Close()
+		canonicalizer := NewSongCanonicalizer(db)
+
+		mock.ExpectQuery("SELECT s.id, s.title, s.releaseYear, sa.artistId").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "releaseYear", "artistId"}).
+				AddRow(1, "Seed Song", 2000, 101).
+				AddRow(4, "Seed Song", 2000, 101))
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM SongCanonical").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO SongCanonical").
+			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		// Act
+		duplicates, err := canonicalizer.Run()
+
+		// Assert
+		assert.Error(t, err, "Should return error when the mapping cannot be saved")
+		assert.Zero(t, duplicates)
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+}
+
+func TestHasSongCanonicalTable(t *testing.T) {
+	// Arrange
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// Act
+	exists, err := HasSongCanonicalTable(db)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestSongRepository_WithoutSongCanonical(t *testing.T) {
+	// Arrange
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, false)
+
+	mock.ExpectQuery(`SELECT DISTINCT s.id\s+FROM Song s\s+JOIN SongArtist sa ON s.id = sa.songId\s+JOIN Artist a ON sa.artistId = a.id\s+WHERE`).
+		WithArgs("Test Song", "Test Artist").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+	// Act
+	songIDs, err := repo.GetSongIDsByTitleAndArtist(context.Background(), "Test Song", "Test Artist")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{4}, songIDs, "Should leave songs unresolved without the SongCanonical table")
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestSongRepository_WithoutSongCanonical_Samples(t *testing.T) {
+	// Arrange
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, false)
+
+	// No canonical songs are looked up, and samples are read from Sample as is
+	mock.ExpectQuery(`WITH CanonicalSample AS \( SELECT DISTINCT cs.original_song_id, cs.sampled_in_song_id FROM Sample cs WHERE cs.original_song_id <> cs.sampled_in_song_id \)`).
+		WithArgs(1, 1).
+		WillReturnRows(sampleEdgeRows())
+
+	// Act
+	results, err := repo.FindSamplePaths(context.Background(), []int{1}, []int{3}, models.SearchOptions{MaxDepth: 4, Direction: models.DirectionBoth})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestSongRepository_WithoutSongCanonical_GetAllSampledSongs(t *testing.T) {
+	// Arrange
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	repo := NewSongRepository(db, false)
+
+	mock.ExpectQuery(`SameSong AS \( -- The requested song and its duplicates SELECT s1.id FROM Song s1 WHERE EXISTS \( SELECT 1 FROM Song s2 WHERE s2.id = \? AND s1.title = s2.title`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"sampled_song_id"}).AddRow(2).AddRow(5))
+
+	// Act
+	sampledSongs, err := repo.GetAllSampledSongs(context.Background(), 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 5}, sampledSongs, "Should merge songs with the same title and release year")
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
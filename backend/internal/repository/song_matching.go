@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// minMatchConfidence is the lowest score MatchSongs reports a candidate with
+const minMatchConfidence = 0.5
+
+// minAliasContainLength keeps very short names like "x" from partially
+// matching every artist that contains them
+const minAliasContainLength = 3
+
+// maxMatchCandidates bounds the songs a single MatchSongs lookup reads
+const maxMatchCandidates = 200
+
+// Streaming services decorate titles with version information the samples DB
+// does not carry, e.g. "Song - 2011 Remaster", "Song (feat. X)" or "Song - Live".
+var (
+	featuringPattern    = regexp.MustCompile(`(?i)\s*[\(\[](feat\.?|ft\.?|featuring|with)\s[^\)\]]*[\)\]]`)
+	trailingFeatPattern = regexp.MustCompile(`(?i)\s+(feat\.?|ft\.?|featuring)\s.*$`)
+	versionTagPattern   = regexp.MustCompile(`(?i)\s*[\(\[][^\)\]]*\b(remaster(ed)?|live|mono|stereo|version|edit|bonus track|deluxe)\b[^\)\]]*[\)\]]`)
+	versionDashPattern  = regexp.MustCompile(`(?i)\s+-\s+[^-]*\b(remaster(ed)?|live|mono|stereo|version|edit|bonus track|deluxe)\b.*$`)
+	artistSplitPattern  = regexp.MustCompile(`(?i)\s*(,|&|\bx\b|\band\b|\bfeat\.?|\bft\.?|\bfeaturing\b|\bwith\b)\s*`)
+)
+
+// stripTitleDecorations removes featuring credits and remaster/live/edit
+// suffixes but keeps the title's original spelling.
+func stripTitleDecorations(title string) string {
+	title = featuringPattern.ReplaceAllString(title, "")
+	title = versionTagPattern.ReplaceAllString(title, "")
+	title = versionDashPattern.ReplaceAllString(title, "")
+	title = trailingFeatPattern.ReplaceAllString(title, "")
+	return strings.TrimSpace(title)
+}
+
+// foldText lowercases s, strips accents and punctuation and collapses
+// whitespace, so "Beyoncé" and "BEYONCE" compare equal.
+func foldText(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		folded = s
+	}
+	folded = strings.ToLower(strings.ReplaceAll(folded, "&", " and "))
+
+	var b strings.Builder
+	for _, r := range folded {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '/':
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func normalizeTitle(title string) string {
+	return foldText(stripTitleDecorations(title))
+}
+
+// artistAliases returns the folded spellings an artist can appear under in the
+// samples DB: the full credit, the credit without a leading "the" and each
+// individual artist of a collaboration credit.
+func artistAliases(artist string) []string {
+	var aliases []string
+	seen := make(map[string]struct{})
+	add := func(alias string) {
+		alias = foldText(alias)
+		if alias == "" {
+			return
+		}
+		if _, exists := seen[alias]; exists {
+			return
+		}
+		seen[alias] = struct{}{}
+		aliases = append(aliases, alias)
+		if trimmed := strings.TrimPrefix(alias, "the "); trimmed != alias {
+			if _, exists := seen[trimmed]; !exists {
+				seen[trimmed] = struct{}{}
+				aliases = append(aliases, trimmed)
+			}
+		}
+	}
+
+	add(artist)
+	for _, part := range artistSplitPattern.Split(artist, -1) {
+		add(part)
+	}
+	return aliases
+}
+
+// artistSpellings returns the names to look an artist up by in the samples DB:
+// each alias as written in the query as well as folded, since stored names
+// keep their punctuation ("AC/DC", "Jay-Z").
+func artistSpellings(artist string) []string {
+	var spellings []string
+	seen := make(map[string]struct{})
+	add := func(spelling string) {
+		spelling = strings.TrimSpace(spelling)
+		if spelling == "" {
+			return
+		}
+		key := strings.ToLower(spelling)
+		if _, exists := seen[key]; exists {
+			return
+		}
+		seen[key] = struct{}{}
+		spellings = append(spellings, spelling)
+	}
+
+	for _, part := range append([]string{artist}, artistSplitPattern.Split(artist, -1)...) {
+		part = strings.TrimSpace(part)
+		add(part)
+		if len(part) > len("the ") && strings.EqualFold(part[:len("the ")], "the ") {
+			add(part[len("the "):])
+		}
+	}
+	for _, alias := range artistAliases(artist) {
+		add(alias)
+	}
+	return spellings
+}
+
+// artistMatchScore scores how well the queried artist matches any of a song's
+// artists: 1 for the same folded name or alias, 0.8 when one name contains the
+// other and 0 otherwise.
+func artistMatchScore(queryArtist string, songArtists []string) float64 {
+	aliases := artistAliases(queryArtist)
+	best := 0.0
+	for _, songArtist := range songArtists {
+		candidates := artistAliases(songArtist)
+		for _, alias := range aliases {
+			for _, candidate := range candidates {
+				switch {
+				case alias == candidate:
+					return 1
+				case len(alias) >= minAliasContainLength && len(candidate) >= minAliasContainLength &&
+					(strings.Contains(candidate, alias) || strings.Contains(alias, candidate)):
+					best = math.Max(best, 0.8)
+				}
+			}
+		}
+	}
+	return best
+}
+
+// scoreSongMatch rates a samples DB song as a match for a query. An exact
+// title and artist match scores 1; normalized matches score lower so callers
+// can prefer exact hits and pick their own cut-off.
+func scoreSongMatch(query models.SongQuery, title string, artists []string) float64 {
+	artistScore := artistMatchScore(query.Artist, artists)
+	if artistScore == 0 {
+		return 0
+	}
+
+	if strings.EqualFold(strings.TrimSpace(query.Title), strings.TrimSpace(title)) {
+		for _, artist := range artists {
+			if strings.EqualFold(strings.TrimSpace(query.Artist), strings.TrimSpace(artist)) {
+				return 1
+			}
+		}
+	}
+
+	queryTitle, songTitle := normalizeTitle(query.Title), normalizeTitle(title)
+	if queryTitle == "" || songTitle == "" {
+		return 0
+	}
+
+	var titleScore float64
+	switch {
+	case queryTitle == songTitle:
+		titleScore = 0.95
+	case strings.HasPrefix(songTitle, queryTitle+" ") || strings.HasPrefix(queryTitle, songTitle+" "):
+		titleScore = 0.7
+	default:
+		return 0
+	}
+
+	return math.Round(titleScore*artistScore*100) / 100
+}
+
+// matchCandidate is a samples DB song under consideration for a query
+type matchCandidate struct {
+	id      int
+	title   string
+	artists []string
+}
+
+// Both MatchSongs implementations read the same candidates: songs whose title
+// starts with the query title stripped of its decorations, and songs by an
+// artist spelled like the queried one. Past maxMatchCandidates songs, the most
+// relevant are kept, ties going to the lowest song ID. A song scores one point
+// each for the same title, a title starting with the query title and a
+// matching artist; the samples DB collation folds case and accents in SQL,
+// foldText does it in Go.
+
+// matchCandidatesSQL returns the query selecting the IDs of the candidate
+// songs for a stripped query title and the artist spellings, most relevant
+// first, along with its parameters
+func matchCandidatesSQL(title string, spellings []string) (string, []interface{}) {
+	titlePrefix := escapeLike(title) + "%"
+	params := []interface{}{title, titlePrefix}
+	for _, spelling := range spellings {
+		params = append(params, spelling)
+	}
+	params = append(params, titlePrefix)
+	for _, spelling := range spellings {
+		params = append(params, spelling)
+	}
+	params = append(params, maxMatchCandidates)
+
+	return `SELECT
+				s2.id,
+				MAX(s2.title = ?) + MAX(s2.title LIKE ?) + MAX(a2.name IN (` + inPlaceholders(len(spellings)) + `)) as relevance
+			FROM Song s2
+			JOIN SongArtist sa2 ON s2.id = sa2.songId
+			JOIN Artist a2 ON sa2.artistId = a2.id
+			WHERE s2.title LIKE ? OR a2.name IN (` + inPlaceholders(len(spellings)) + `)
+			GROUP BY s2.id
+			ORDER BY relevance DESC, s2.id
+			LIMIT ?`, params
+}
+
+// matchRelevance scores a candidate the way matchCandidatesSQL does. title is
+// the folded, stripped query title and aliases the queried artist's aliases.
+func matchRelevance(title string, aliases map[string]struct{}, candidate matchCandidate) int {
+	relevance := 0
+	candidateTitle := foldText(candidate.title)
+	if candidateTitle == title {
+		relevance++
+	}
+	if strings.HasPrefix(candidateTitle, title) {
+		relevance++
+	}
+	for _, artist := range candidate.artists {
+		if _, ok := aliases[foldText(artist)]; ok {
+			relevance++
+			break
+		}
+	}
+	return relevance
+}
+
+// topMatchCandidates keeps the maxMatchCandidates candidates most relevant to
+// the query, like the LIMIT of matchCandidatesSQL
+func topMatchCandidates(query models.SongQuery, candidates []matchCandidate) []matchCandidate {
+	title := foldText(stripTitleDecorations(query.Title))
+	aliases := make(map[string]struct{})
+	for _, alias := range artistAliases(query.Artist) {
+		aliases[alias] = struct{}{}
+	}
+
+	relevance := make(map[int]int, len(candidates))
+	for _, candidate := range candidates {
+		relevance[candidate.id] = matchRelevance(title, aliases, candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if relevance[candidates[i].id] != relevance[candidates[j].id] {
+			return relevance[candidates[i].id] > relevance[candidates[j].id]
+		}
+		return candidates[i].id < candidates[j].id
+	})
+	if len(candidates) > maxMatchCandidates {
+		candidates = candidates[:maxMatchCandidates]
+	}
+	return candidates
+}
+
+// rankSongMatches scores candidates against the query and returns the ones at
+// or above minMatchConfidence, best first.
+func rankSongMatches(query models.SongQuery, candidates []matchCandidate) []models.SongMatch {
+	var matches []models.SongMatch
+	for _, candidate := range candidates {
+		confidence := scoreSongMatch(query, candidate.title, candidate.artists)
+		if confidence < minMatchConfidence {
+			continue
+		}
+		matches = append(matches, models.SongMatch{
+			SongID:     candidate.id,
+			Title:      candidate.title,
+			Artist:     bestMatchingArtist(query.Artist, candidate.artists),
+			Confidence: confidence,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		return matches[i].SongID < matches[j].SongID
+	})
+	return matches
+}
+
+// bestMatchingArtist picks the song artist the query artist matched, falling
+// back to the first (main) artist.
+func bestMatchingArtist(queryArtist string, artists []string) string {
+	if len(artists) == 0 {
+		return ""
+	}
+	best, bestScore := artists[0], 0.0
+	for _, artist := range artists {
+		if score := artistMatchScore(queryArtist, []string{artist}); score > bestScore {
+			best, bestScore = artist, score
+		}
+	}
+	return best
+}
+
+// escapeLike escapes the LIKE wildcards in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"Song - 2011 Remaster":           "song",
+		"Song - Remastered 2009":         "song",
+		"Song (feat. Someone)":           "song",
+		"Song [ft. Someone & Other]":     "song",
+		"Song feat. Someone":             "song",
+		"Song - Live at Wembley":         "song",
+		"Song (Live)":                    "song",
+		"Song - Radio Edit":              "song",
+		"Café Del Mar":                   "cafe del mar",
+		"Don't Stop 'Til You Get Enough": "dont stop til you get enough",
+		"Rock & Roll":                    "rock and roll",
+		"Song - Part 2":                  "song part 2",
+	}
+
+	for title, expected := range tests {
+		assert.Equal(t, expected, normalizeTitle(title), "title %q", title)
+	}
+}
+
+func TestArtistAliases(t *testing.T) {
+	assert.Equal(t, []string{"the roots", "roots"}, artistAliases("The Roots"))
+	assert.Equal(t, []string{"beyonce and jay z", "beyonce", "jay z"}, artistAliases("Beyoncé & JAY-Z"))
+}
+
+func TestScoreSongMatch(t *testing.T) {
+	query := models.SongQuery{Title: "Song - 2011 Remaster", Artist: "Beyoncé"}
+
+	t.Run("Exact_Match", func(t *testing.T) {
+		assert.Equal(t, 1.0, scoreSongMatch(models.SongQuery{Title: "song", Artist: "ARTIST"}, "Song", []string{"Artist"}))
+	})
+
+	t.Run("Normalized_Match", func(t *testing.T) {
+		assert.Equal(t, 0.95, scoreSongMatch(query, "Song", []string{"Beyonce"}))
+	})
+
+	t.Run("Alias_Match", func(t *testing.T) {
+		assert.Equal(t, 0.95, scoreSongMatch(models.SongQuery{Title: "Song", Artist: "Beyoncé & JAY-Z"}, "Song", []string{"Jay Z"}))
+	})
+
+	t.Run("Partial_Artist_Match", func(t *testing.T) {
+		assert.Equal(t, 0.76, scoreSongMatch(query, "Song", []string{"Beyonce Knowles"}))
+	})
+
+	t.Run("Different_Artist", func(t *testing.T) {
+		assert.Zero(t, scoreSongMatch(query, "Song", []string{"Someone Else"}))
+	})
+
+	t.Run("Different_Title", func(t *testing.T) {
+		assert.Zero(t, scoreSongMatch(query, "Other Song", []string{"Beyonce"}))
+	})
+}
+
+func TestTopMatchCandidates(t *testing.T) {
+	// Arrange
+	query := models.SongQuery{Title: "Song (feat. Someone)", Artist: "Artist"}
+	candidates := []matchCandidate{
+		{id: 1, title: "Other", artists: []string{"Artist"}},
+		{id: 2, title: "Songbird", artists: []string{"Someone Else"}},
+		{id: 3, title: "Song", artists: []string{"ARTIST"}},
+		{id: 4, title: "Song", artists: []string{"Someone Else"}},
+	}
+
+	// Act
+	top := topMatchCandidates(query, candidates)
+
+	// Assert
+	ids := make([]int, len(top))
+	for i, candidate := range top {
+		ids[i] = candidate.id
+	}
+	assert.Equal(t, []int{3, 4, 1, 2}, ids, "Should rank by title and artist relevance, then by song ID")
+}
+
+// TestMatchSongs_SameCandidates runs both MatchSongs implementations over the
+// same catalog
+func TestMatchSongs_SameCandidates(t *testing.T) {
+	// Song 7 is a duplicate of song 1. Song 6 only shares a title prefix and
+	// part of the artist name with the query.
+	type fixtureSong struct {
+		id     int
+		title  string
+		artist string
+	}
+	catalog := []fixtureSong{
+		{1, "Song", "Beyoncé"},
+		{2, "Song (Live)", "Beyonce"},
+		{3, "Songbird", "Beyoncé"},
+		{4, "Song", "Someone Else"},
+		{5, "Other Tune", "Beyoncé"},
+		{6, "Song Part 2", "Beyoncé Knowles"},
+		{7, "Song", "Beyonce"},
+	}
+	query := models.SongQuery{Title: "Song - 2011 Remaster", Artist: "Beyoncé"}
+
+	// The SQL candidates: every song titled "Song%" or by an artist the
+	// collation reads as "Beyoncé", resolved to its canonical song
+	db, mock := setupSongTestDB(t)
+	defer db.Close()
+	candidateRows := sqlmock.NewRows([]string{"id", "title", "name"})
+	for _, id := range []int{1, 7, 2, 3, 4, 5, 6} {
+		song := catalog[id-1]
+		canonicalID := id
+		if id == 7 {
+			canonicalID, song.title = 1, catalog[0].title
+		}
+		candidateRows.AddRow(canonicalID, song.title, song.artist)
+	}
+	mock.ExpectQuery(`WHERE s2.title LIKE \? OR a2.name IN`).WillReturnRows(candidateRows)
+	sqlMatches, err := NewSongRepository(db, true).MatchSongs(context.Background(), query)
+	require.NoError(t, err)
+
+	indexDB, indexMock := setupSongTestDB(t)
+	defer indexDB.Close()
+	indexMock.ExpectQuery("SELECT songId, canonicalId FROM SongCanonical").
+		WillReturnRows(sqlmock.NewRows([]string{"songId", "canonicalId"}).AddRow(7, 1))
+	songRows := sqlmock.NewRows([]string{"id", "title", "releaseYear"})
+	artistRows := sqlmock.NewRows([]string{"songId", "id", "name", "isMainArtist"})
+	for _, song := range catalog {
+		songRows.AddRow(song.id, song.title, 2000)
+		artistRows.AddRow(song.id, 100+song.id, song.artist, true)
+	}
+	indexMock.ExpectQuery("SELECT s.id, s.title, s.releaseYear FROM Song s").WillReturnRows(songRows)
+	indexMock.ExpectQuery("SELECT sa.songId, a.id, a.name, sa.isMainArtist FROM SongArtist sa").WillReturnRows(artistRows)
+	indexMock.ExpectQuery("SELECT sg.B, g.name FROM _SongToGenre sg").WillReturnRows(sqlmock.NewRows([]string{"B", "name"}))
+	indexMock.ExpectQuery("SELECT original_song_id, sampled_in_song_id FROM Sample").WillReturnRows(sampleEdgeRows())
+	index := NewSongGraphIndex(indexDB, true)
+	require.NoError(t, index.Load())
+
+	// Act
+	indexMatches, err := index.MatchSongs(context.Background(), query)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, sqlMatches, indexMatches, "Both implementations should match the same songs")
+	require.Len(t, indexMatches, 3)
+	assert.Equal(t, []int{1, 2, 6}, []int{indexMatches[0].SongID, indexMatches[1].SongID, indexMatches[2].SongID})
+	assert.Equal(t, 0.56, indexMatches[2].Confidence, "Should find title prefix matches by a partly matching artist")
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	assert.NoError(t, indexMock.ExpectationsWereMet(), "All expectations should be met")
+}
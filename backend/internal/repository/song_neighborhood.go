@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+)
+
+// songNeighborhood is a neighborhood before its songs are hydrated
+type songNeighborhood struct {
+	ids       []int
+	edges     []models.SampleEdge
+	truncated bool
+}
+
+// buildSongNeighborhood expands opts.Radius sample hops out of songID, level by
+// level, keeping at most opts.MaxNodes songs and the Sample edges between them.
+// It stops with ctx's error once ctx is cancelled.
+func buildSongNeighborhood(ctx context.Context, load sampleHopLoader, songID int, opts models.NeighborhoodOptions) (*songNeighborhood, error) {
+	neighborhood := &songNeighborhood{ids: []int{songID}}
+	visited := map[int]struct{}{songID: {}}
+	seenEdges := make(map[models.SampleEdge]struct{})
+
+	frontier := []int{songID}
+	for depth := 0; depth < opts.Radius && len(frontier) > 0; depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hops, err := load(ctx, frontier, opts.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []int
+		for _, id := range frontier {
+			for _, hop := range hops[id] {
+				if _, known := visited[hop.to]; !known {
+					if opts.MaxNodes > 0 && len(neighborhood.ids) >= opts.MaxNodes {
+						neighborhood.truncated = true
+						continue
+					}
+					visited[hop.to] = struct{}{}
+					neighborhood.ids = append(neighborhood.ids, hop.to)
+					next = append(next, hop.to)
+				}
+
+				edge := models.SampleEdge{SampledInID: id, OriginalID: hop.to}
+				if hop.direction == models.DirectionDescendants {
+					edge = models.SampleEdge{SampledInID: hop.to, OriginalID: id}
+				}
+				if _, seen := seenEdges[edge]; !seen {
+					seenEdges[edge] = struct{}{}
+					neighborhood.edges = append(neighborhood.edges, edge)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return neighborhood, nil
+}
+
+// hydrate turns the neighborhood into a models.SongNeighborhood, dropping songs
+// lookup cannot find along with their edges
+func (n *songNeighborhood) hydrate(lookup func(id int) (models.SongNode, bool)) *models.SongNeighborhood {
+	result := &models.SongNeighborhood{
+		SongID:    n.ids[0],
+		Truncated: n.truncated,
+	}
+
+	found := make(map[int]struct{}, len(n.ids))
+	for _, id := range n.ids {
+		if song, ok := lookup(id); ok {
+			found[id] = struct{}{}
+			result.Songs = append(result.Songs, song)
+		}
+	}
+	for _, edge := range n.edges {
+		_, sampledIn := found[edge.SampledInID]
+		_, original := found[edge.OriginalID]
+		if sampledIn && original {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+	return result
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Emeruem-Kennedy1/ghopper/internal/auth"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/handlers"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/middleware"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
 	"github.com/Emeruem-Kennedy1/ghopper/internal/services"
 	"github.com/gin-gonic/gin"
@@ -18,20 +19,28 @@ type Server struct {
 	config             *config.Config
 	spotifyAuth        *auth.SpotifyAuth
 	userRepo           repository.UserRepositoryInterface
-	songRepo           *repository.SongRepository
+	songRepo           repository.SongRepositoryInterface
 	spotifySongRepo    repository.SpotifySongRepositoryInterface
 	nonSpotifyUserRepo *repository.NonSpotifyUserRepository
 	cleintManager      services.ClientManagerInterface
 	spotifyService     services.SpotifyServiceInterface
+	genreTaxonomy      services.GenreTaxonomyServiceInterface
+	exclusionRepo      repository.ExclusionRepositoryInterface
+	searchCache        repository.SearchCacheInterface
+	radioService       services.RadioServiceInterface
+	sampleDNAService   services.SampleDNAServiceInterface
 	logger             *zap.Logger
 }
 
 func NewServer(
 	cfg *config.Config,
 	userRepo *repository.UserRepository,
-	songRepo *repository.SongRepository,
+	songRepo repository.SongRepositoryInterface,
 	spotifySongRepo *repository.SpotifySongRepository,
 	nonSpotifyUserRepo *repository.NonSpotifyUserRepository,
+	genreTaxonomy services.GenreTaxonomyServiceInterface,
+	exclusionRepo repository.ExclusionRepositoryInterface,
+	searchCache repository.SearchCacheInterface,
 	logger *zap.Logger,
 ) (*Server, error) {
 	if cfg.Env == "production" {
@@ -58,8 +67,13 @@ func NewServer(
 		nonSpotifyUserRepo: nonSpotifyUserRepo,
 		cleintManager:      clientManager,
 		spotifyService:     spotifyService,
+		genreTaxonomy:      genreTaxonomy,
+		exclusionRepo:      exclusionRepo,
+		searchCache:        searchCache,
 		logger:             logger,
 	}
+	s.radioService = services.NewRadioService(songRepo, genreTaxonomy, s.radioOptions())
+	s.sampleDNAService = services.NewSampleDNAService(songRepo)
 	gin.Logger()
 
 	s.setupRoutes()
@@ -82,22 +96,79 @@ func (s *Server) setupRoutes() {
 		protected.GET("/user", handlers.GetUser(s.userRepo))
 		protected.GET("/user/top-artists", handlers.GetUserTopArtists(s.cleintManager))
 		protected.GET("/user/top-tracks", handlers.GetUserTopTracks(s.cleintManager, s.spotifyService))
-		protected.POST("/search", handlers.SearchSongByGenre(s.songRepo))
-		protected.POST("/toptracks-analysis", handlers.AnalyzeSongsGivenGenre(s.songRepo, s.cleintManager, s.spotifyService))
+		protected.GET("/user/sample-dna", handlers.GetUserSampleDNA(s.songRepo, s.cleintManager, s.sampleDNAService, s.searchBudget()))
+		protected.POST("/search", handlers.SearchSongByGenre(s.songRepo, s.searchBudget()))
+		protected.POST("/search/path", handlers.SearchSamplePath(s.songRepo, s.searchBudget()))
+		protected.POST("/search/profile", handlers.GetGenreProfile(s.songRepo, s.genreTaxonomy, s.searchBudget()))
+		protected.GET("/songs/search", handlers.SearchSongs(s.songRepo))
+		protected.GET("/songs/:id", handlers.GetSongDetails(s.songRepo))
+		protected.GET("/songs/:id/graph", handlers.GetSongGraph(s.songRepo, s.searchBudget()))
+		protected.GET("/radio", handlers.GetRadio(s.radioService, s.searchBudget()))
+		protected.GET("/artists/:id/network", handlers.GetArtistNetwork(s.songRepo, s.searchBudget()))
+		protected.GET("/genres", handlers.ListGenres(s.genreTaxonomy, s.songRepo))
+		protected.POST("/toptracks-analysis", handlers.AnalyzeSongsGivenGenre(s.songRepo, s.cleintManager, s.spotifyService, s.genreTaxonomy, s.exclusionRepo, s.playlistDiversity(), s.searchBudget()))
 		protected.GET("/user/playlists", handlers.GetUserPlaylists(s.spotifySongRepo, s.spotifyService))
 		protected.DELETE("/user/playlists/:playlistID", handlers.DeletePlaylist(s.spotifyService, s.spotifySongRepo))
 		protected.DELETE("/user/account", handlers.DeleteUserAccount(s.userRepo, s.spotifySongRepo, s.cleintManager))
+		protected.GET("/exclusions", handlers.ListExclusions(s.exclusionRepo))
+		protected.POST("/exclusions", handlers.AddExclusion(s.exclusionRepo))
+		protected.DELETE("/exclusions/:id", handlers.DeleteExclusion(s.exclusionRepo))
+	}
+
+	admin := protected.Group("/admin")
+	admin.Use(middleware.AdminMiddleware(s.config.AdminUserIDs))
+	{
+		admin.GET("/genres", handlers.ListGenreGroups(s.genreTaxonomy))
+		admin.PUT("/genres/:id", handlers.SaveGenreGroup(s.genreTaxonomy))
+		admin.DELETE("/genres/:id", handlers.DeleteGenreGroup(s.genreTaxonomy))
+		admin.GET("/search-cache", handlers.GetSearchCacheStats(s.searchCache))
+		admin.DELETE("/search-cache", handlers.ClearSearchCache(s.searchCache))
 	}
 
 	nonSpotifyProtected := s.router.Group("/api/non-spotify")
 	nonSpotifyProtected.Use(middleware.NonSpotifyAuthMiddleware(s.nonSpotifyUserRepo))
 	{
 		// Routes for non-Spotify users
-		nonSpotifyProtected.POST("/playlists", handlers.GenerateNonSpotifyPlaylist(s.nonSpotifyUserRepo, s.songRepo))
+		nonSpotifyProtected.POST("/playlists", handlers.GenerateNonSpotifyPlaylist(s.nonSpotifyUserRepo, s.songRepo, s.genreTaxonomy, s.exclusionRepo, s.playlistDiversity(), s.searchBudget()))
+		nonSpotifyProtected.GET("/genres", handlers.ListGenres(s.genreTaxonomy, s.songRepo))
+		nonSpotifyProtected.GET("/songs/search", handlers.SearchSongs(s.songRepo))
 		nonSpotifyProtected.GET("/playlists", handlers.GetNonSpotifyUserPlaylists(s.nonSpotifyUserRepo))
 		nonSpotifyProtected.GET("/playlists/:playlistID", handlers.GetNonSpotifyPlaylistDetails(s.nonSpotifyUserRepo))
 		nonSpotifyProtected.PATCH("/tracks/:trackID", handlers.UpdateNonSpotifyTrackStatus(s.nonSpotifyUserRepo))
 		nonSpotifyProtected.DELETE("/playlists/:playlistID", handlers.DeleteNonSpotifyPlaylist(s.nonSpotifyUserRepo))
+		nonSpotifyProtected.GET("/exclusions", handlers.ListExclusions(s.exclusionRepo))
+		nonSpotifyProtected.POST("/exclusions", handlers.AddExclusion(s.exclusionRepo))
+		nonSpotifyProtected.DELETE("/exclusions/:id", handlers.DeleteExclusion(s.exclusionRepo))
+	}
+}
+
+// playlistDiversity reads the playlist diversity stage settings from the config
+func (s *Server) playlistDiversity() models.DiversityOptions {
+	return models.DiversityOptions{
+		MaxPerArtist: s.config.PlaylistMaxPerArtist,
+		MaxPerSeed:   s.config.PlaylistMaxPerSeed,
+		Lambda:       s.config.PlaylistDiversityLambda,
+	}
+}
+
+// searchBudget reads the sample-graph search caps from the config
+func (s *Server) searchBudget() models.SearchBudget {
+	return models.SearchBudget{
+		MaxDepth: s.config.SearchMaxDepth,
+		MaxSeeds: s.config.SearchMaxSeeds,
+		MaxRows:  s.config.SearchMaxRows,
+		MaxCost:  s.config.SearchMaxCost,
+		Timeout:  s.config.SearchTimeout,
+	}
+}
+
+// radioOptions reads the sample radio random walk settings from the config
+func (s *Server) radioOptions() models.RadioOptions {
+	return models.RadioOptions{
+		RestartProbability: s.config.RadioRestartProbability,
+		GenreBias:          s.config.RadioGenreBias,
+		RecentWindow:       s.config.RadioRecentWindow,
+		SessionTTL:         s.config.RadioSessionTTL,
 	}
 }
 
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+)
+
+// ErrGenreTaken is returned when a saved group claims a genre name that
+// already belongs to another group
+var ErrGenreTaken = errors.New("genre belongs to another group")
+
+// GenreTaxonomyService keeps the genre taxonomy in memory and resolves the
+// genres clients send to genre groups. Edits go through the service so the
+// in-memory copy never drifts from the app DB.
+type GenreTaxonomyService struct {
+	repo repository.GenreTaxonomyRepositoryInterface
+
+	mu     sync.RWMutex
+	groups []models.GenreGroup
+	// byName maps every lowercased group ID, display name, genre and synonym
+	// to an index into groups
+	byName map[string]int
+}
+
+func NewGenreTaxonomyService(repo repository.GenreTaxonomyRepositoryInterface) *GenreTaxonomyService {
+	return &GenreTaxonomyService{repo: repo, byName: make(map[string]int)}
+}
+
+// Load reads the taxonomy from the app DB and replaces the in-memory copy
+func (s *GenreTaxonomyService) Load() error {
+	groups, err := s.repo.ListGroups()
+	if err != nil {
+		return fmt.Errorf("error loading genre taxonomy: %v", err)
+	}
+
+	byName := make(map[string]int)
+	for i, group := range groups {
+		for _, name := range group.Names() {
+			if _, exists := byName[name]; !exists {
+				byName[name] = i
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.groups = groups
+	s.byName = byName
+	s.mu.Unlock()
+	return nil
+}
+
+// Groups returns every genre group in display order
+func (s *GenreTaxonomyService) Groups() []models.GenreGroup {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.GenreGroup(nil), s.groups...)
+}
+
+// ResolveGenre returns the group a genre, synonym, group ID or group name
+// belongs to. A genre outside the taxonomy resolves to a group of its own.
+func (s *GenreTaxonomyService) ResolveGenre(genre string) models.GenreGroup {
+	name := strings.ToLower(strings.TrimSpace(genre))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i, exists := s.byName[name]; exists {
+		return s.groups[i]
+	}
+	return models.GenreGroup{
+		ID:     strings.ReplaceAll(name, " ", "-"),
+		Name:   name,
+		Genres: []models.GenreGroupGenre{{Name: name}},
+	}
+}
+
+// SaveGroup creates or replaces a genre group and reloads the taxonomy
+func (s *GenreTaxonomyService) SaveGroup(group *models.GenreGroup) error {
+	s.mu.RLock()
+	for _, genre := range group.Genres {
+		if i, exists := s.byName[strings.ToLower(genre.Name)]; exists && s.groups[i].ID != group.ID {
+			s.mu.RUnlock()
+			return fmt.Errorf("%w: %q is in %q", ErrGenreTaken, genre.Name, s.groups[i].ID)
+		}
+	}
+	s.mu.RUnlock()
+
+	if err := s.repo.SaveGroup(group); err != nil {
+		return err
+	}
+	return s.Load()
+}
+
+// DeleteGroup deletes a genre group and reloads the taxonomy. It reports
+// whether the group existed.
+func (s *GenreTaxonomyService) DeleteGroup(id string) (bool, error) {
+	group, err := s.repo.GetGroup(id)
+	if err != nil {
+		return false, err
+	}
+	if group == nil {
+		return false, nil
+	}
+
+	if err := s.repo.DeleteGroup(id); err != nil {
+		return false, err
+	}
+	return true, s.Load()
+}
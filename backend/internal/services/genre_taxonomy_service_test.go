@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testGenreGroups = []models.GenreGroup{
+	{
+		ID:   "hip-hop",
+		Name: "Hip-Hop / Rap / R&B",
+		Genres: []models.GenreGroupGenre{
+			{Name: "hip-hop"},
+			{Name: "rap"},
+			{Name: "hip hop", Synonym: true},
+		},
+	},
+	{
+		ID:     "jazz",
+		Name:   "Jazz / Blues",
+		Genres: []models.GenreGroupGenre{{Name: "jazz"}, {Name: "blues"}},
+	},
+}
+
+func setupGenreTaxonomyService(t *testing.T) (*GenreTaxonomyService, *MockGenreTaxonomyRepository) {
+	mockRepo := new(MockGenreTaxonomyRepository)
+	mockRepo.On("ListGroups").Return(testGenreGroups, nil).Once()
+
+	service := NewGenreTaxonomyService(mockRepo)
+	require.NoError(t, service.Load(), "Setup: Load should not fail")
+	return service, mockRepo
+}
+
+func TestGenreTaxonomyService_ResolveGenre(t *testing.T) {
+	service, _ := setupGenreTaxonomyService(t)
+
+	t.Run("Resolves_Group_Names", func(t *testing.T) {
+		for _, genre := range []string{"hip-hop", "Rap", " HIP HOP ", "Hip-Hop / Rap / R&B"} {
+			group := service.ResolveGenre(genre)
+			assert.Equal(t, "hip-hop", group.ID, "%q should resolve to the hip-hop group", genre)
+		}
+	})
+
+	t.Run("Search_Genres_Skip_Synonyms", func(t *testing.T) {
+		group := service.ResolveGenre("rap")
+		assert.Equal(t, []string{"hip-hop", "rap"}, group.SearchGenres())
+	})
+
+	t.Run("Unknown_Genre", func(t *testing.T) {
+		group := service.ResolveGenre("Smooth Jazz")
+		assert.Equal(t, "smooth-jazz", group.ID)
+		assert.Equal(t, "smooth jazz", group.Name)
+		assert.Equal(t, []string{"smooth jazz"}, group.SearchGenres(), "Unknown genres should be searched as they are")
+	})
+}
+
+func TestGenreTaxonomyService_SaveGroup(t *testing.T) {
+	t.Run("Saves_And_Reloads", func(t *testing.T) {
+		// Arrange
+		service, mockRepo := setupGenreTaxonomyService(t)
+		group := &models.GenreGroup{ID: "soul", Name: "Soul", Genres: []models.GenreGroupGenre{{Name: "soul"}}}
+		mockRepo.On("SaveGroup", group).Return(nil)
+		mockRepo.On("ListGroups").Return(append(testGenreGroups, *group), nil)
+
+		// Act
+		err := service.SaveGroup(group)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, service.Groups(), 3, "Should reload the taxonomy")
+		assert.Equal(t, "Soul", service.ResolveGenre("soul").Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Genre_Taken", func(t *testing.T) {
+		// Arrange
+		service, mockRepo := setupGenreTaxonomyService(t)
+		group := &models.GenreGroup{ID: "rap", Name: "Rap", Genres: []models.GenreGroupGenre{{Name: "Rap"}}}
+
+		// Act
+		err := service.SaveGroup(group)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrGenreTaken, "A genre can only belong to one group")
+		mockRepo.AssertNotCalled(t, "SaveGroup", mock.Anything)
+	})
+
+	t.Run("Repository_Error", func(t *testing.T) {
+		// Arrange
+		service, mockRepo := setupGenreTaxonomyService(t)
+		group := &models.GenreGroup{ID: "jazz", Name: "Jazz", Genres: []models.GenreGroupGenre{{Name: "jazz"}}}
+		mockRepo.On("SaveGroup", group).Return(errors.New("database error"))
+
+		// Act
+		err := service.SaveGroup(group)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, "Jazz / Blues", service.ResolveGenre("jazz").Name, "Should keep the loaded taxonomy")
+	})
+}
+
+func TestGenreTaxonomyService_DeleteGroup(t *testing.T) {
+	t.Run("Deletes_And_Reloads", func(t *testing.T) {
+		// Arrange
+		service, mockRepo := setupGenreTaxonomyService(t)
+		mockRepo.On("GetGroup", "jazz").Return(&testGenreGroups[1], nil)
+		mockRepo.On("DeleteGroup", "jazz").Return(nil)
+		mockRepo.On("ListGroups").Return(testGenreGroups[:1], nil)
+
+		// Act
+		deleted, err := service.DeleteGroup("jazz")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		assert.Equal(t, "blues", service.ResolveGenre("blues").ID, "Deleted group genres should no longer resolve to it")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Missing_Group", func(t *testing.T) {
+		// Arrange
+		service, mockRepo := setupGenreTaxonomyService(t)
+		mockRepo.On("GetGroup", "polka").Return(nil, nil)
+
+		// Act
+		deleted, err := service.DeleteGroup("polka")
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		mockRepo.AssertNotCalled(t, "DeleteGroup", mock.Anything)
+	})
+}
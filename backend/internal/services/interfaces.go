@@ -1,12 +1,36 @@
 package services
 
-import "github.com/zmb3/spotify"
+import (
+	"context"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/zmb3/spotify"
+)
 
 type SpotifyServiceInterface interface {
 	GetSongURL(userID, name, artist string) (string, error)
 	CreatePlaylistFromSongs(userID string, songSpotifyIDs []spotify.ID, playlistName string, playlistDescription string) (string, error)
 	DeletePlaylist(userID, playlistID string) error
 	GetPlaylistImageURL(userID, playlistID string) (string, error)
+	GetUserPlaylistSongs(userID string) ([]models.SongQuery, error)
+}
+
+type GenreTaxonomyServiceInterface interface {
+	Groups() []models.GenreGroup
+	ResolveGenre(genre string) models.GenreGroup
+	SaveGroup(group *models.GenreGroup) error
+	DeleteGroup(id string) (bool, error)
+}
+
+// RadioServiceInterface runs sample radio sessions
+type RadioServiceInterface interface {
+	Start(ctx context.Context, userID string, seedIDs []int, genre string) (string, error)
+	Next(ctx context.Context, userID, sessionID string, count int) ([]models.RadioTrack, error)
+}
+
+// SampleDNAServiceInterface builds sample DNA reports from a user's top tracks
+type SampleDNAServiceInterface interface {
+	Report(ctx context.Context, tracks []models.TopTrack, opts models.SampleDNAOptions) (*models.SampleDNA, error)
 }
 
 type SpotifyClientInterface interface {
@@ -14,6 +38,8 @@ type SpotifyClientInterface interface {
 	CurrentUser() (*spotify.PrivateUser, error)
 	CreatePlaylistForUser(userID, name, description string, public bool) (*spotify.FullPlaylist, error)
 	GetPlaylistTracks(playlistID spotify.ID) (*spotify.PlaylistTrackPage, error)
+	GetPlaylistTracksOpt(playlistID spotify.ID, opt *spotify.Options, fields string) (*spotify.PlaylistTrackPage, error)
+	CurrentUsersPlaylistsOpt(opt *spotify.Options) (*spotify.SimplePlaylistPage, error)
 	AddTracksToPlaylist(playlistID spotify.ID, trackIDs ...spotify.ID) (string, error)
 	GetPlaylist(playlistID spotify.ID) (*spotify.FullPlaylist, error)
 	UnfollowPlaylist(userID, playlistID spotify.ID) error
@@ -32,3 +58,6 @@ type ClientManagerInterface interface {
 var _ SpotifyServiceInterface = (*SpotifyService)(nil)
 var _ SpotifyClientInterface = (*spotify.Client)(nil)
 var _ ClientManagerInterface = (*ClientManager)(nil)
+var _ GenreTaxonomyServiceInterface = (*GenreTaxonomyService)(nil)
+var _ RadioServiceInterface = (*RadioService)(nil)
+var _ SampleDNAServiceInterface = (*SampleDNAService)(nil)
@@ -98,6 +98,16 @@ func (m *MockSpotifyClient) GetPlaylistTracks(playlistID spotify.ID) (*spotify.P
 	return args.Get(0).(*spotify.PlaylistTrackPage), args.Error(1)
 }
 
+func (m *MockSpotifyClient) GetPlaylistTracksOpt(playlistID spotify.ID, opt *spotify.Options, fields string) (*spotify.PlaylistTrackPage, error) {
+	args := m.Called(playlistID, opt, fields)
+	return args.Get(0).(*spotify.PlaylistTrackPage), args.Error(1)
+}
+
+func (m *MockSpotifyClient) CurrentUsersPlaylistsOpt(opt *spotify.Options) (*spotify.SimplePlaylistPage, error) {
+	args := m.Called(opt)
+	return args.Get(0).(*spotify.SimplePlaylistPage), args.Error(1)
+}
+
 func (m *MockSpotifyClient) AddTracksToPlaylist(playlistID spotify.ID, trackIDs ...spotify.ID) (string, error) {
 	args := m.Called(playlistID, trackIDs)
 	return args.String(0), args.Error(1)
@@ -122,3 +132,39 @@ func (m *MockSpotifyClient) CurrentUsersTopTracksOpt(opt *spotify.Options) (*spo
 	args := m.Called(opt)
 	return args.Get(0).(*spotify.FullTrackPage), args.Error(1)
 }
+
+// ! MockGenreTaxonomyRepository mocks the GenreTaxonomyRepository
+type MockGenreTaxonomyRepository struct {
+	mock.Mock
+}
+
+func (m *MockGenreTaxonomyRepository) ListGroups() ([]models.GenreGroup, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GenreGroup), args.Error(1)
+}
+
+func (m *MockGenreTaxonomyRepository) GetGroup(id string) (*models.GenreGroup, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GenreGroup), args.Error(1)
+}
+
+func (m *MockGenreTaxonomyRepository) SaveGroup(group *models.GenreGroup) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGenreTaxonomyRepository) DeleteGroup(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGenreTaxonomyRepository) SeedDefaults() error {
+	args := m.Called()
+	return args.Error(0)
+}
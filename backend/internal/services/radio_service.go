@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRadioSessionNotFound marks unknown, expired or other users' sessions
+	ErrRadioSessionNotFound = errors.New("radio session not found")
+	// ErrNoRadioSeeds marks sessions none of whose seed songs exist
+	ErrNoRadioSeeds = errors.New("no seed songs found")
+)
+
+const (
+	// maxRadioSessionsPerUser caps the sessions a user keeps; starting another
+	// one drops their least recently used
+	maxRadioSessionsPerUser = 5
+	// maxRadioStepsPerTrack bounds the walk when it keeps landing on seeds or
+	// recently played songs
+	maxRadioStepsPerTrack = 5
+	// maxRadioCachedSongs caps the songs whose steps a session keeps loaded
+	maxRadioCachedSongs = 500
+	// radioRecentPenalty multiplies the odds of stepping to a recently played song
+	radioRecentPenalty = 0.1
+)
+
+// RadioService runs "sample radio" sessions: random walks with restart over
+// the Sample graph from a set of seed songs, biased toward a target genre and
+// away from the songs a session played recently. Sessions live in memory.
+type RadioService struct {
+	songRepo      repository.SongRepositoryInterface
+	genreTaxonomy GenreTaxonomyServiceInterface
+	opts          models.RadioOptions
+	// newRand seeds each session's walk
+	newRand func() *rand.Rand
+
+	mu       sync.Mutex
+	sessions map[string]*radioSession
+}
+
+type radioSession struct {
+	id     string
+	userID string
+	seeds  []int
+	genres models.GenreFilter
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	current  int
+	recent   []int
+	lastUsed time.Time
+	// steps caches the steps out of the songs the walk has been on
+	steps map[int][]radioStep
+}
+
+// radioStep is a song one sample hop away from the walk's current song
+type radioStep struct {
+	song      models.SongNode
+	direction models.TraversalDirection
+}
+
+func NewRadioService(songRepo repository.SongRepositoryInterface, genreTaxonomy GenreTaxonomyServiceInterface, opts models.RadioOptions) *RadioService {
+	return &RadioService{
+		songRepo:      songRepo,
+		genreTaxonomy: genreTaxonomy,
+		opts:          opts,
+		newRand: func() *rand.Rand {
+			return rand.New(rand.NewSource(time.Now().UnixNano()))
+		},
+		sessions: make(map[string]*radioSession),
+	}
+}
+
+// Start opens a session for userID walking from seedIDs, biased toward the
+// songs of genre's group when genre is set. Seeds are resolved to their
+// canonical songs. It returns the session ID.
+func (s *RadioService) Start(ctx context.Context, userID string, seedIDs []int, genre string) (string, error) {
+	var seeds []int
+	seen := make(map[int]struct{}, len(seedIDs))
+	for _, id := range seedIDs {
+		song, err := s.songRepo.GetSongWithDetails(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, exists := seen[song.ID]; !exists {
+			seen[song.ID] = struct{}{}
+			seeds = append(seeds, song.ID)
+		}
+	}
+	if len(seeds) == 0 {
+		return "", ErrNoRadioSeeds
+	}
+
+	session := &radioSession{
+		id:       uuid.New().String(),
+		userID:   userID,
+		seeds:    seeds,
+		rng:      s.newRand(),
+		lastUsed: time.Now(),
+		steps:    make(map[int][]radioStep),
+	}
+	if genre != "" {
+		session.genres = models.NewGenreFilter(s.genreTaxonomy.ResolveGenre(genre).SearchGenres()...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneSessions(userID)
+	s.sessions[session.id] = session
+	return session.id, nil
+}
+
+// Next walks userID's session on until it played count more songs, or gives up
+// and returns fewer when the graph around the seeds runs dry.
+func (s *RadioService) Next(ctx context.Context, userID, sessionID string, count int) ([]models.RadioTrack, error) {
+	session, err := s.session(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	tracks := make([]models.RadioTrack, 0, count)
+	restarted := false
+	for step := 0; len(tracks) < count && step < count*maxRadioStepsPerTrack; step++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if session.current == 0 || session.rng.Float64() < s.opts.RestartProbability {
+			session.current = session.seeds[session.rng.Intn(len(session.seeds))]
+			restarted = true
+		}
+
+		steps, err := s.steps(ctx, session, session.current)
+		if err != nil {
+			return nil, err
+		}
+		if len(steps) == 0 {
+			// A dead end sends the walk back to a seed
+			session.current = 0
+			continue
+		}
+
+		next := s.pick(session, steps)
+		from := session.current
+		session.current = next.song.ID
+		if session.isSeed(next.song.ID) || session.playedRecently(next.song.ID) {
+			continue
+		}
+
+		tracks = append(tracks, models.RadioTrack{
+			Song:       next.song,
+			FromSongID: from,
+			Direction:  next.direction,
+			Restarted:  restarted,
+		})
+		session.remember(next.song.ID, s.opts.RecentWindow)
+		restarted = false
+	}
+
+	return tracks, nil
+}
+
+// session looks up a live session of userID and marks it used
+func (s *RadioService) session(userID, sessionID string) (*radioSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.userID != userID {
+		return nil, ErrRadioSessionNotFound
+	}
+	if s.opts.SessionTTL > 0 && time.Since(session.lastUsed) > s.opts.SessionTTL {
+		delete(s.sessions, sessionID)
+		return nil, ErrRadioSessionNotFound
+	}
+	session.lastUsed = time.Now()
+	return session, nil
+}
+
+// pruneSessions drops expired sessions and makes room for another session of
+// userID. The caller holds s.mu.
+func (s *RadioService) pruneSessions(userID string) {
+	var oldest *radioSession
+	userSessions := 0
+	for id, session := range s.sessions {
+		if s.opts.SessionTTL > 0 && time.Since(session.lastUsed) > s.opts.SessionTTL {
+			delete(s.sessions, id)
+			continue
+		}
+		if session.userID != userID {
+			continue
+		}
+		userSessions++
+		if oldest == nil || session.lastUsed.Before(oldest.lastUsed) {
+			oldest = session
+		}
+	}
+	if userSessions >= maxRadioSessionsPerUser {
+		delete(s.sessions, oldest.id)
+	}
+}
+
+// steps returns the hydrated songs one sample hop away from songID, loading
+// them once per session
+func (s *RadioService) steps(ctx context.Context, session *radioSession, songID int) ([]radioStep, error) {
+	if steps, ok := session.steps[songID]; ok {
+		return steps, nil
+	}
+
+	lineage, err := s.songRepo.GetSampleLineage(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []radioStep
+	ids := append(append([]int(nil), lineage.SamplesUsed...), lineage.SampledIn...)
+	if len(ids) > 0 {
+		songs, err := s.songRepo.GetSongsWithDetails(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range lineage.SamplesUsed {
+			if song, ok := songs[id]; ok {
+				steps = append(steps, radioStep{song: *song, direction: models.DirectionAncestors})
+			}
+		}
+		for _, id := range lineage.SampledIn {
+			if song, ok := songs[id]; ok {
+				steps = append(steps, radioStep{song: *song, direction: models.DirectionDescendants})
+			}
+		}
+	}
+
+	if len(session.steps) >= maxRadioCachedSongs {
+		session.steps = make(map[int][]radioStep)
+	}
+	session.steps[songID] = steps
+	return steps, nil
+}
+
+// pick draws the next step, weighting songs of the session's genre up by
+// GenreBias and recently played songs down
+func (s *RadioService) pick(session *radioSession, steps []radioStep) radioStep {
+	weights := make([]float64, len(steps))
+	total := 0.0
+	for i, step := range steps {
+		weight := 1.0
+		if s.opts.GenreBias > 0 && session.genres.Matches(step.song.Genres) {
+			weight *= s.opts.GenreBias
+		}
+		if session.playedRecently(step.song.ID) {
+			weight *= radioRecentPenalty
+		}
+		weights[i] = weight
+		total += weight
+	}
+
+	draw := session.rng.Float64() * total
+	for i, weight := range weights {
+		if draw < weight {
+			return steps[i]
+		}
+		draw -= weight
+	}
+	return steps[len(steps)-1]
+}
+
+func (r *radioSession) isSeed(songID int) bool {
+	for _, id := range r.seeds {
+		if id == songID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *radioSession) playedRecently(songID int) bool {
+	for _, id := range r.recent {
+		if id == songID {
+			return true
+		}
+	}
+	return false
+}
+
+// remember records a played song, forgetting the oldest beyond window
+func (r *radioSession) remember(songID, window int) {
+	r.recent = append(r.recent, songID)
+	if window > 0 && len(r.recent) > window {
+		r.recent = r.recent[len(r.recent)-window:]
+	}
+}
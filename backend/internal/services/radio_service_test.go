@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// radioTestGraph is a small sample graph: the seed (1) samples a soul song (2)
+// and a rock song (3), the soul song samples another soul song (4) and a later
+// song (5) samples the rock song. Song 6 samples nothing and is sampled by
+// nothing. Song 7 is a duplicate of the seed.
+type radioTestGraph struct {
+	repository.SongRepositoryInterface
+	// lineageLoads counts the GetSampleLineage calls per song
+	lineageLoads map[int]int
+}
+
+var radioTestSongs = map[int]models.SongNode{
+	1: {ID: 1, Title: "Seed Song", Genres: []string{"hip-hop"}},
+	2: {ID: 2, Title: "Soul Song", Genres: []string{"soul"}},
+	3: {ID: 3, Title: "Rock Song", Genres: []string{"rock"}},
+	4: {ID: 4, Title: "Older Soul Song", Genres: []string{"soul"}},
+	5: {ID: 5, Title: "Later Song", Genres: []string{"hip-hop"}},
+	6: {ID: 6, Title: "Lonely Song", Genres: []string{"jazz"}},
+}
+
+var radioTestLineage = map[int]models.SampleLineage{
+	1: {SamplesUsed: []int{2, 3}},
+	2: {SamplesUsed: []int{4}, SampledIn: []int{1}},
+	3: {SampledIn: []int{1, 5}},
+	4: {SampledIn: []int{2}},
+	5: {SamplesUsed: []int{3}},
+}
+
+func (g *radioTestGraph) GetSongWithDetails(_ context.Context, songID int) (*models.SongNode, error) {
+	if songID == 7 {
+		songID = 1
+	}
+	song, ok := radioTestSongs[songID]
+	if !ok {
+		return nil, fmt.Errorf("error getting song: %w", sql.ErrNoRows)
+	}
+	return &song, nil
+}
+
+func (g *radioTestGraph) GetSampleLineage(_ context.Context, songID int) (*models.SampleLineage, error) {
+	g.lineageLoads[songID]++
+	lineage := radioTestLineage[songID]
+	return &lineage, nil
+}
+
+func (g *radioTestGraph) GetSongsWithDetails(_ context.Context, ids []int) (map[int]*models.SongNode, error) {
+	songs := make(map[int]*models.SongNode)
+	for _, id := range ids {
+		if song, ok := radioTestSongs[id]; ok {
+			songs[id] = &song
+		}
+	}
+	return songs, nil
+}
+
+func setupRadioService(opts models.RadioOptions) *RadioService {
+	service := NewRadioService(&radioTestGraph{lineageLoads: make(map[int]int)}, NewGenreTaxonomyService(nil), opts)
+	service.newRand = func() *rand.Rand { return rand.New(rand.NewSource(1)) }
+	return service
+}
+
+// isSampleHop reports whether track's hop is a Sample edge of radioTestGraph
+func isSampleHop(track models.RadioTrack) bool {
+	lineage := radioTestLineage[track.FromSongID]
+	hops := lineage.SamplesUsed
+	if track.Direction == models.DirectionDescendants {
+		hops = lineage.SampledIn
+	}
+	for _, id := range hops {
+		if id == track.Song.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRadioService_Next(t *testing.T) {
+	t.Run("Walks_Sample_Edges", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{RestartProbability: 0.2, RecentWindow: 10})
+		sessionID, err := service.Start(context.Background(), "user-1", []int{1}, "")
+		require.NoError(t, err)
+
+		// Act
+		tracks, err := service.Next(context.Background(), "user-1", sessionID, 4)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, tracks, 4, "Every song but the seed and the unreachable one should play")
+		played := make(map[int]struct{})
+		for _, track := range tracks {
+			assert.True(t, isSampleHop(track), "Track %d should follow a Sample edge from %d", track.Song.ID, track.FromSongID)
+			assert.NotEqual(t, 1, track.Song.ID, "The seed should not be played")
+			played[track.Song.ID] = struct{}{}
+		}
+		assert.Len(t, played, 4, "Recently played songs should not repeat")
+		assert.True(t, tracks[0].Restarted, "The first hop starts from a seed")
+		for songID, loads := range service.songRepo.(*radioTestGraph).lineageLoads {
+			assert.Equal(t, 1, loads, "Song %d's steps should be loaded once per session", songID)
+		}
+	})
+
+	t.Run("Continues_Session", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{RestartProbability: 0.2, RecentWindow: 10})
+		sessionID, err := service.Start(context.Background(), "user-1", []int{1}, "")
+		require.NoError(t, err)
+		first, err := service.Next(context.Background(), "user-1", sessionID, 2)
+		require.NoError(t, err)
+
+		// Act
+		second, err := service.Next(context.Background(), "user-1", sessionID, 2)
+
+		// Assert
+		require.NoError(t, err)
+		for _, track := range second {
+			for _, earlier := range first {
+				assert.NotEqual(t, earlier.Song.ID, track.Song.ID, "The next batch should avoid the songs already played")
+			}
+		}
+	})
+
+	t.Run("Genre_Bias", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{RestartProbability: 0, GenreBias: 1000, RecentWindow: 10})
+		sessionID, err := service.Start(context.Background(), "user-1", []int{1}, "soul")
+		require.NoError(t, err)
+
+		// Act
+		tracks, err := service.Next(context.Background(), "user-1", sessionID, 2)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, tracks, 2)
+		assert.Equal(t, 2, tracks[0].Song.ID, "Should step to the soul song")
+		assert.Equal(t, 4, tracks[1].Song.ID, "Should keep following soul songs")
+		assert.Equal(t, models.DirectionAncestors, tracks[1].Direction)
+	})
+
+	t.Run("Dead_End", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{RestartProbability: 0.15})
+		sessionID, err := service.Start(context.Background(), "user-1", []int{6}, "")
+		require.NoError(t, err)
+
+		// Act
+		tracks, err := service.Next(context.Background(), "user-1", sessionID, 5)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, tracks, "A seed without samples has nothing to play")
+	})
+
+	t.Run("Other_Users_Session", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{})
+		sessionID, err := service.Start(context.Background(), "user-1", []int{1}, "")
+		require.NoError(t, err)
+
+		// Act
+		_, err = service.Next(context.Background(), "user-2", sessionID, 1)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRadioSessionNotFound)
+	})
+
+	t.Run("Expired_Session", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{SessionTTL: time.Millisecond})
+		sessionID, err := service.Start(context.Background(), "user-1", []int{1}, "")
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		// Act
+		_, err = service.Next(context.Background(), "user-1", sessionID, 1)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRadioSessionNotFound)
+	})
+}
+
+func TestRadioService_Start(t *testing.T) {
+	t.Run("Unknown_Seeds", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{})
+
+		// Act
+		_, err := service.Start(context.Background(), "user-1", []int{998, 999}, "")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNoRadioSeeds)
+	})
+
+	t.Run("Resolves_Duplicate_Seeds", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{RestartProbability: 0.5, RecentWindow: 10})
+
+		// Act
+		sessionID, err := service.Start(context.Background(), "user-1", []int{7, 1, 999}, "")
+		require.NoError(t, err)
+		tracks, err := service.Next(context.Background(), "user-1", sessionID, 4)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, service.sessions[sessionID].seeds, "Duplicates should resolve to one canonical seed")
+		for _, track := range tracks {
+			assert.NotEqual(t, 1, track.Song.ID, "The canonical seed should not be played")
+		}
+	})
+
+	t.Run("Caps_Sessions_Per_User", func(t *testing.T) {
+		// Arrange
+		service := setupRadioService(models.RadioOptions{})
+		first, err := service.Start(context.Background(), "user-1", []int{1}, "")
+		require.NoError(t, err)
+
+		// Act
+		for i := 0; i < maxRadioSessionsPerUser; i++ {
+			_, err := service.Start(context.Background(), "user-1", []int{1}, "")
+			require.NoError(t, err)
+		}
+
+		// Assert
+		_, err = service.Next(context.Background(), "user-1", first, 1)
+		assert.ErrorIs(t, err, ErrRadioSessionNotFound, "The least recently used session should be dropped")
+		assert.Len(t, service.sessions, maxRadioSessionsPerUser)
+	})
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+)
+
+// SampleDNAService builds "sample DNA" reports: the songs, artists, genres and
+// eras a user's top tracks sample, directly or through other samples.
+type SampleDNAService struct {
+	songRepo repository.SongRepositoryInterface
+}
+
+func NewSampleDNAService(songRepo repository.SongRepositoryInterface) *SampleDNAService {
+	return &SampleDNAService{songRepo: songRepo}
+}
+
+// dnaExample is an example path along with what ranks it among the others
+type dnaExample struct {
+	path  models.SampleDNAPath
+	track int
+}
+
+// dnaTally counts how many top tracks reach each entry of a report section
+// and keeps each track's shortest path to it
+type dnaTally struct {
+	tracks   map[string]int
+	examples map[string][]dnaExample
+}
+
+func newDNATally() *dnaTally {
+	return &dnaTally{tracks: make(map[string]int), examples: make(map[string][]dnaExample)}
+}
+
+// add counts track toward key. Callers add a track at most once per key,
+// through its shortest path.
+func (t *dnaTally) add(key string, track int, path models.SampleDNAPath) {
+	t.tracks[key]++
+	t.examples[key] = append(t.examples[key], dnaExample{path: path, track: track})
+}
+
+// top returns the keys reached by the most top tracks, at most limit of them,
+// along with their entries
+func (t *dnaTally) top(limit, examples int) ([]string, []models.SampleDNAEntry) {
+	keys := make([]string, 0, len(t.tracks))
+	for key := range t.tracks {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if t.tracks[keys[i]] != t.tracks[keys[j]] {
+			return t.tracks[keys[i]] > t.tracks[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	entries := make([]models.SampleDNAEntry, len(keys))
+	for i, key := range keys {
+		found := t.examples[key]
+		sort.SliceStable(found, func(a, b int) bool {
+			if len(found[a].path.Songs) != len(found[b].path.Songs) {
+				return len(found[a].path.Songs) < len(found[b].path.Songs)
+			}
+			return found[a].track < found[b].track
+		})
+		if examples > 0 && len(found) > examples {
+			found = found[:examples]
+		}
+
+		entries[i] = models.SampleDNAEntry{Tracks: t.tracks[key]}
+		for _, example := range found {
+			entries[i].Examples = append(entries[i].Examples, example.path)
+		}
+	}
+	return keys, entries
+}
+
+// Report walks up to opts.Depth sample hops back from every matched top track
+// and reports the ancestors, artists, genres and eras reached by the most of
+// them. Unmatched tracks only count toward SampleDNA.Tracks.
+func (s *SampleDNAService) Report(ctx context.Context, tracks []models.TopTrack, opts models.SampleDNAOptions) (*models.SampleDNA, error) {
+	dna := &models.SampleDNA{Tracks: len(tracks)}
+	ancestors := make(map[int]models.SongNode)
+	artists := make(map[int]models.Artist)
+	songTally, artistTally, genreTally, eraTally := newDNATally(), newDNATally(), newDNATally(), newDNATally()
+
+	for i, track := range tracks {
+		if len(track.SongIDs) == 0 {
+			continue
+		}
+		dna.Matched++
+
+		paths, err := s.ancestorPaths(ctx, track.SongIDs, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]struct{})
+		addOnce := func(tally *dnaTally, key string, path models.SampleDNAPath) {
+			if _, exists := seen[key]; exists {
+				return
+			}
+			seen[key] = struct{}{}
+			tally.add(key, i, path)
+		}
+		for _, chain := range paths {
+			path := models.SampleDNAPath{Songs: chain, TimeRanges: track.TimeRanges}
+			ancestor := chain[len(chain)-1]
+			ancestors[ancestor.ID] = ancestor
+
+			addOnce(songTally, "song:"+strconv.Itoa(ancestor.ID), path)
+			for _, artist := range mainArtists(ancestor.Artists) {
+				artists[artist.ID] = artist
+				addOnce(artistTally, "artist:"+strconv.Itoa(artist.ID), path)
+			}
+			for _, genre := range ancestor.Genres {
+				if genre = strings.ToLower(strings.TrimSpace(genre)); genre != "" {
+					addOnce(genreTally, "genre:"+genre, path)
+				}
+			}
+			if ancestor.ReleaseYear > 0 {
+				addOnce(eraTally, "era:"+strconv.Itoa(ancestor.ReleaseYear/10*10), path)
+			}
+		}
+	}
+
+	keys, entries := songTally.top(opts.Entries, opts.Examples)
+	for i, key := range keys {
+		id, _ := strconv.Atoi(strings.TrimPrefix(key, "song:"))
+		dna.Ancestors = append(dna.Ancestors, models.SampleDNASong{Song: ancestors[id], SampleDNAEntry: entries[i]})
+	}
+	keys, entries = artistTally.top(opts.Entries, opts.Examples)
+	for i, key := range keys {
+		id, _ := strconv.Atoi(strings.TrimPrefix(key, "artist:"))
+		dna.Artists = append(dna.Artists, models.SampleDNAArtist{Artist: artists[id], SampleDNAEntry: entries[i]})
+	}
+	keys, entries = genreTally.top(opts.Entries, opts.Examples)
+	for i, key := range keys {
+		dna.Genres = append(dna.Genres, models.SampleDNAGenre{Genre: strings.TrimPrefix(key, "genre:"), SampleDNAEntry: entries[i]})
+	}
+	keys, entries = eraTally.top(opts.Entries, opts.Examples)
+	for i, key := range keys {
+		decade, _ := strconv.Atoi(strings.TrimPrefix(key, "era:"))
+		dna.Eras = append(dna.Eras, models.SampleDNAEra{Decade: decade, SampleDNAEntry: entries[i]})
+	}
+	return dna, nil
+}
+
+// ancestorPaths returns the shortest sample chain from any of songIDs to each
+// of their ancestors, shortest first. Every chain starts with the song that
+// samples the rest.
+func (s *SampleDNAService) ancestorPaths(ctx context.Context, songIDs []int, opts models.SampleDNAOptions) ([][]models.SongNode, error) {
+	var paths [][]models.SongNode
+	reached := make(map[int]struct{})
+	for _, songID := range songIDs {
+		neighborhood, err := s.songRepo.GetSongNeighborhood(ctx, songID, models.NeighborhoodOptions{
+			Radius:    opts.Depth,
+			Direction: models.DirectionAncestors,
+			MaxNodes:  opts.MaxNodes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if neighborhood == nil {
+			continue
+		}
+		reached[neighborhood.SongID] = struct{}{}
+
+		nodes := make(map[int]models.SongNode, len(neighborhood.Songs))
+		for _, song := range neighborhood.Songs {
+			nodes[song.ID] = song
+		}
+		samples := make(map[int][]int)
+		for _, edge := range neighborhood.Edges {
+			samples[edge.SampledInID] = append(samples[edge.SampledInID], edge.OriginalID)
+		}
+
+		// BFS over the neighborhood's edges keeps the shortest chain to each
+		// ancestor
+		start, ok := nodes[neighborhood.SongID]
+		if !ok {
+			continue
+		}
+		frontier := [][]models.SongNode{{start}}
+		for len(frontier) > 0 {
+			var next [][]models.SongNode
+			for _, path := range frontier {
+				for _, id := range samples[path[len(path)-1].ID] {
+					if _, exists := reached[id]; exists {
+						continue
+					}
+					reached[id] = struct{}{}
+					song, ok := nodes[id]
+					if !ok {
+						continue
+					}
+					extended := append(append([]models.SongNode(nil), path...), song)
+					paths = append(paths, extended)
+					next = append(next, extended)
+				}
+			}
+			frontier = next
+		}
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) < len(paths[j])
+	})
+	return paths, nil
+}
+
+// mainArtists returns the main artists of a song, or every artist when none is
+// marked main
+func mainArtists(artists []models.Artist) []models.Artist {
+	var main []models.Artist
+	for _, artist := range artists {
+		if artist.IsMain {
+			main = append(main, artist)
+		}
+	}
+	if len(main) == 0 {
+		return artists
+	}
+	return main
+}
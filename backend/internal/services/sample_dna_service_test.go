@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Emeruem-Kennedy1/ghopper/internal/models"
+	"github.com/Emeruem-Kennedy1/ghopper/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dnaTestGraph is a small sample graph: the first top track (1) samples a
+// soul song (2) and a funk song (3), the soul song samples an older soul song
+// (4), and the second top track (5) samples the funk and older soul songs.
+type dnaTestGraph struct {
+	repository.SongRepositoryInterface
+	err error
+}
+
+var (
+	soulArtist = models.Artist{ID: 201, Name: "Soul Artist", IsMain: true}
+	funkArtist = models.Artist{ID: 202, Name: "Funk Artist", IsMain: true}
+)
+
+var dnaTestSongs = map[int]models.SongNode{
+	1: {ID: 1, Title: "Top Track", Genres: []string{"hip-hop"}, ReleaseYear: 1994},
+	2: {ID: 2, Title: "Soul Song", Artists: []models.Artist{soulArtist}, Genres: []string{"soul"}, ReleaseYear: 1975},
+	3: {ID: 3, Title: "Funk Song", Artists: []models.Artist{funkArtist, {ID: 203, Name: "Featured Artist"}}, Genres: []string{"Funk"}, ReleaseYear: 1972},
+	4: {ID: 4, Title: "Older Soul Song", Artists: []models.Artist{soulArtist}, Genres: []string{"soul"}, ReleaseYear: 1968},
+	5: {ID: 5, Title: "Other Top Track", Genres: []string{"hip-hop"}, ReleaseYear: 1998},
+}
+
+var dnaTestSamples = map[int][]int{
+	1: {2, 3},
+	2: {4},
+	5: {4, 3},
+}
+
+func (g *dnaTestGraph) GetSongNeighborhood(_ context.Context, songID int, opts models.NeighborhoodOptions) (*models.SongNeighborhood, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if _, ok := dnaTestSongs[songID]; !ok {
+		return nil, nil
+	}
+
+	neighborhood := &models.SongNeighborhood{SongID: songID, Songs: []models.SongNode{dnaTestSongs[songID]}}
+	visited := map[int]bool{songID: true}
+	frontier := []int{songID}
+	for depth := 0; depth < opts.Radius; depth++ {
+		var next []int
+		for _, id := range frontier {
+			for _, original := range dnaTestSamples[id] {
+				neighborhood.Edges = append(neighborhood.Edges, models.SampleEdge{SampledInID: id, OriginalID: original})
+				if !visited[original] {
+					visited[original] = true
+					neighborhood.Songs = append(neighborhood.Songs, dnaTestSongs[original])
+					next = append(next, original)
+				}
+			}
+		}
+		frontier = next
+	}
+	return neighborhood, nil
+}
+
+func TestSampleDNAService_Report(t *testing.T) {
+	tracks := []models.TopTrack{
+		{Title: "Top Track", Artist: "Rapper", TimeRanges: []string{"short", "long"}, SongIDs: []int{1}},
+		{Title: "Unknown Track", Artist: "Nobody", TimeRanges: []string{"short"}},
+		{Title: "Other Top Track", Artist: "Other Rapper", TimeRanges: []string{"medium"}, SongIDs: []int{5}},
+	}
+	opts := models.SampleDNAOptions{Depth: 2, Entries: 10, Examples: 3}
+
+	t.Run("Counts_Top_Tracks_Per_Entry", func(t *testing.T) {
+		// Arrange
+		service := NewSampleDNAService(&dnaTestGraph{})
+
+		// Act
+		dna, err := service.Report(context.Background(), tracks, opts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, dna.Tracks)
+		assert.Equal(t, 2, dna.Matched)
+
+		require.Len(t, dna.Ancestors, 3)
+		assert.Equal(t, 3, dna.Ancestors[0].Song.ID, "Both top tracks sample the funk song")
+		assert.Equal(t, 2, dna.Ancestors[0].Tracks)
+		assert.Equal(t, 4, dna.Ancestors[1].Song.ID, "Both top tracks reach the older soul song")
+		assert.Equal(t, 2, dna.Ancestors[1].Tracks)
+		assert.Equal(t, 2, dna.Ancestors[2].Song.ID)
+		assert.Equal(t, 1, dna.Ancestors[2].Tracks)
+
+		examples := dna.Ancestors[1].Examples
+		require.Len(t, examples, 2)
+		assert.Len(t, examples[0].Songs, 2, "The direct sample should be the first example")
+		assert.Equal(t, []string{"medium"}, examples[0].TimeRanges)
+		assert.Equal(t, []int{1, 2, 4}, []int{examples[1].Songs[0].ID, examples[1].Songs[1].ID, examples[1].Songs[2].ID})
+
+		require.Len(t, dna.Artists, 2, "Featured artists should not count")
+		assert.Equal(t, soulArtist, dna.Artists[0].Artist)
+		assert.Equal(t, 2, dna.Artists[0].Tracks, "A track reaching two soul songs counts once")
+		assert.Equal(t, funkArtist, dna.Artists[1].Artist)
+		assert.Equal(t, 2, dna.Artists[1].Tracks)
+
+		require.Len(t, dna.Genres, 2)
+		assert.Equal(t, "funk", dna.Genres[0].Genre)
+		assert.Equal(t, "soul", dna.Genres[1].Genre)
+
+		require.Len(t, dna.Eras, 2)
+		assert.Equal(t, 1960, dna.Eras[0].Decade, "Ties should come in chronological order")
+		assert.Equal(t, 1970, dna.Eras[1].Decade)
+		assert.Equal(t, 2, dna.Eras[1].Tracks)
+	})
+
+	t.Run("Caps_Entries_And_Examples", func(t *testing.T) {
+		// Arrange
+		service := NewSampleDNAService(&dnaTestGraph{})
+
+		// Act
+		dna, err := service.Report(context.Background(), tracks, models.SampleDNAOptions{Depth: 1, Entries: 1, Examples: 1})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, dna.Ancestors, 1)
+		assert.Equal(t, 3, dna.Ancestors[0].Song.ID)
+		assert.Len(t, dna.Ancestors[0].Examples, 1)
+		assert.Len(t, dna.Eras, 1)
+	})
+
+	t.Run("Repository_Error", func(t *testing.T) {
+		// Arrange
+		service := NewSampleDNAService(&dnaTestGraph{err: assert.AnError})
+
+		// Act
+		dna, err := service.Report(context.Background(), tracks, opts)
+
+		// Assert
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, dna)
+	})
+}
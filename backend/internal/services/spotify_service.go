@@ -8,6 +8,15 @@ import (
 	"github.com/zmb3/spotify"
 )
 
+const (
+	// playlistsPageSize and playlistTracksPageSize are the largest pages
+	// Spotify serves of a user's playlists and of a playlist's tracks
+	playlistsPageSize      = 50
+	playlistTracksPageSize = 100
+	// maxKnownPlaylists caps the saved playlists GetUserPlaylistSongs reads
+	maxKnownPlaylists = 50
+)
+
 type SpotifyService struct {
 	clientManager   *ClientManager
 	spotifySongRepo repository.SpotifySongRepositoryInterface
@@ -204,3 +213,58 @@ func (s *SpotifyService) GetPlaylistImageURL(userID, playlistID string) (string,
 
 	return playlist.Images[0].URL, nil
 }
+
+// GetUserPlaylistSongs returns the songs in the user's saved Spotify
+// playlists, the ones the app created included. It reads every track of up to
+// maxKnownPlaylists playlists.
+func (s *SpotifyService) GetUserPlaylistSongs(userID string) ([]models.SongQuery, error) {
+	client, exists := s.clientManager.GetClient(userID)
+	if !exists {
+		return nil, fmt.Errorf("no spotify client found for user %s", userID)
+	}
+
+	var playlistIDs []spotify.ID
+	for offset := 0; len(playlistIDs) < maxKnownPlaylists; {
+		limit := playlistsPageSize
+		page, err := client.CurrentUsersPlaylistsOpt(&spotify.Options{Limit: &limit, Offset: &offset})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user playlists: %v", err)
+		}
+		for _, playlist := range page.Playlists {
+			if len(playlistIDs) < maxKnownPlaylists {
+				playlistIDs = append(playlistIDs, playlist.ID)
+			}
+		}
+		offset += len(page.Playlists)
+		if page.Next == "" || len(page.Playlists) == 0 {
+			break
+		}
+	}
+
+	var songs []models.SongQuery
+	for _, playlistID := range playlistIDs {
+		for offset := 0; ; {
+			limit := playlistTracksPageSize
+			page, err := client.GetPlaylistTracksOpt(playlistID, &spotify.Options{Limit: &limit, Offset: &offset}, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get playlist tracks: %v", err)
+			}
+
+			for _, track := range page.Tracks {
+				if len(track.Track.Artists) == 0 {
+					continue
+				}
+				songs = append(songs, models.SongQuery{
+					Title:  track.Track.Name,
+					Artist: track.Track.Artists[0].Name,
+				})
+			}
+			offset += len(page.Tracks)
+			if page.Next == "" || len(page.Tracks) == 0 {
+				break
+			}
+		}
+	}
+
+	return songs, nil
+}
@@ -425,3 +425,76 @@ func TestRemoveDuplicates(t *testing.T) {
 		assert.Empty(t, result, "Should return empty slice for empty input")
 	})
 }
+
+func TestGetUserPlaylistSongs(t *testing.T) {
+	t.Run("Collects_Playlist_Songs", func(t *testing.T) {
+		// Arrange
+		mockClient := new(MockSpotifyClient)
+		clientManager := NewClientManager()
+		service := NewSpotifyService(clientManager, new(MockSpotifySongRepository))
+
+		userID := "test-user"
+		clientManager.StoreClient(userID, mockClient)
+
+		playlistPage := func(offset int) interface{} {
+			return mock.MatchedBy(func(opts *spotify.Options) bool {
+				return *opts.Limit == playlistsPageSize && *opts.Offset == offset
+			})
+		}
+		trackPage := func(offset int) interface{} {
+			return mock.MatchedBy(func(opts *spotify.Options) bool {
+				return *opts.Limit == playlistTracksPageSize && *opts.Offset == offset
+			})
+		}
+		track := func(name, artist string) spotify.PlaylistTrack {
+			var artists []spotify.SimpleArtist
+			if artist != "" {
+				artists = []spotify.SimpleArtist{{Name: artist}}
+			}
+			return spotify.PlaylistTrack{Track: spotify.FullTrack{SimpleTrack: spotify.SimpleTrack{Name: name, Artists: artists}}}
+		}
+
+		// Both the saved playlists and the first playlist's tracks span two pages
+		firstPlaylists := &spotify.SimplePlaylistPage{Playlists: []spotify.SimplePlaylist{{ID: "playlist1"}}}
+		firstPlaylists.Next = "next"
+		mockClient.On("CurrentUsersPlaylistsOpt", playlistPage(0)).Return(firstPlaylists, nil)
+		mockClient.On("CurrentUsersPlaylistsOpt", playlistPage(1)).Return(&spotify.SimplePlaylistPage{
+			Playlists: []spotify.SimplePlaylist{{ID: "playlist2"}},
+		}, nil)
+
+		firstTracks := &spotify.PlaylistTrackPage{Tracks: []spotify.PlaylistTrack{track("Song 1", "Artist 1"), track("No Artist", "")}}
+		firstTracks.Next = "next"
+		mockClient.On("GetPlaylistTracksOpt", spotify.ID("playlist1"), trackPage(0), "").Return(firstTracks, nil)
+		mockClient.On("GetPlaylistTracksOpt", spotify.ID("playlist1"), trackPage(2), "").Return(&spotify.PlaylistTrackPage{
+			Tracks: []spotify.PlaylistTrack{track("Song 3", "Artist 3")},
+		}, nil)
+		mockClient.On("GetPlaylistTracksOpt", spotify.ID("playlist2"), trackPage(0), "").Return(&spotify.PlaylistTrackPage{
+			Tracks: []spotify.PlaylistTrack{track("Song 2", "Artist 2")},
+		}, nil)
+
+		// Act
+		songs, err := service.GetUserPlaylistSongs(userID)
+
+		// Assert
+		require.NoError(t, err, "GetUserPlaylistSongs should not return error")
+		assert.Equal(t, []models.SongQuery{
+			{Title: "Song 1", Artist: "Artist 1"},
+			{Title: "Song 3", Artist: "Artist 3"},
+			{Title: "Song 2", Artist: "Artist 2"},
+		}, songs, "Should read every page of every saved playlist")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("No_Client", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockSpotifySongRepository)
+		service := NewSpotifyService(NewClientManager(), mockRepo)
+
+		// Act
+		songs, err := service.GetUserPlaylistSongs("test-user")
+
+		// Assert
+		assert.Error(t, err, "Should return error when the user has no client")
+		assert.Nil(t, songs)
+	})
+}